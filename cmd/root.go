@@ -2,12 +2,21 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/ersanisk/sieve/internal/app"
+	"github.com/ersanisk/sieve/internal/bookmark"
+	"github.com/ersanisk/sieve/internal/columns"
 	"github.com/ersanisk/sieve/internal/config"
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/history"
+	"github.com/ersanisk/sieve/internal/tail"
 	"github.com/ersanisk/sieve/internal/theme"
+	"github.com/ersanisk/sieve/pkg/logentry"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +24,16 @@ var (
 	cfgFile   string
 	themeName string
 	follow    bool
+	preset    string
+	excludes  []string
+	since     string
+	until     string
+	level     string
+	filterArg string
+	watchGlob string
+	depth     int
+	columnArg string
+	contextN  int
 )
 
 // NewRootCmd creates the root cobra command.
@@ -30,7 +49,7 @@ func NewRootCmd(version, buildTime string) *cobra.Command {
 				filePath = args[0]
 			}
 
-			appCfg, err := config.Load()
+			appCfg, err := config.LoadFrom(cfgFile)
 			if err != nil {
 				return fmt.Errorf("loading config: %w", err)
 			}
@@ -41,13 +60,63 @@ func NewRootCmd(version, buildTime string) *cobra.Command {
 			if cmd.Flags().Changed("follow") {
 				appCfg.Follow = follow
 			}
+			if cmd.Flags().Changed("level") {
+				appCfg.LevelFilter = level
+			}
+			if cmd.Flags().Changed("filter") {
+				appCfg.FilterExpr = filterArg
+			}
+			if cmd.Flags().Changed("glob") {
+				appCfg.Watch.Glob = watchGlob
+			}
+			if cmd.Flags().Changed("depth") {
+				appCfg.Watch.Depth = depth
+			}
 
 			if theme.Get(appCfg.Theme) == nil {
 				appCfg.Theme = "default"
 			}
 
 			model := app.NewModel(filePath, appCfg.Theme, appCfg.Follow)
-			program := tea.NewProgram(model)
+			model.SetPresets(loadPresets(appCfg))
+			model.SetConfigPath(appCfg.Path)
+			model.SetWatchOptions(appCfg.Watch.Glob, appCfg.Watch.Depth)
+			model.SetLayout(appCfg.Layout.PaneRatio, appCfg.Layout.StackWidth)
+			model.SetWrapLines(appCfg.WrapLines)
+			if cmd.Flags().Changed("context") {
+				model.SetContext(contextN, true)
+			} else {
+				model.SetContext(appCfg.ContextLines, false)
+			}
+			model.SetCorrelationKeys(appCfg.CorrelationKeys)
+			model.SetTimeMarkers(appCfg.Markers.Gap, appCfg.Markers.Burst)
+			model.SetTailOptions(tail.ManagerOptions{MaxBatchRate: appCfg.Tail.MaxBatchRate, MaxPending: appCfg.Tail.MaxPending})
+			model.SetHistory(openHistory("search_history"), openHistory("filter_history"))
+			model.SetBookmarks(openBookmarks())
+			model.SetColumns(appCfg.Columns)
+			model.SetLayouts(openLayouts())
+			if columnArg != "" {
+				model.SetFileColumns(columns.Parse(columnArg))
+			}
+			if err := model.SetFilter(appCfg.FilterExpr, logentry.ParseLevel(appCfg.LevelFilter)); err != nil {
+				return err
+			}
+			if err := model.SetExcludes(append(appCfg.Filters.Exclude, excludes...)); err != nil {
+				return err
+			}
+			if err := setTimeRange(&model); err != nil {
+				return err
+			}
+			if preset != "" {
+				if err := model.SetPreset(preset); err != nil {
+					return err
+				}
+			}
+			var opts []tea.ProgramOption
+			if appCfg.Mouse {
+				opts = append(opts, tea.WithMouseCellMotion())
+			}
+			program := tea.NewProgram(model, opts...)
 
 			if _, err := program.Run(); err != nil {
 				return err
@@ -60,8 +129,76 @@ func NewRootCmd(version, buildTime string) *cobra.Command {
 	rootCmd.Flags().StringVarP(&cfgFile, "config", "c", "", "config file path")
 	rootCmd.Flags().StringVarP(&themeName, "theme", "t", config.DefaultTheme, "color theme (monokai, dracula, gruvbox, nord)")
 	rootCmd.Flags().BoolVarP(&follow, "follow", "f", false, "follow file for new lines (like tail -f)")
+	rootCmd.Flags().StringVar(&preset, "preset", "", "apply a named filter preset on startup")
+	rootCmd.Flags().StringVarP(&level, "level", "l", "", "minimum level to show (debug, info, warn, error, fatal)")
+	rootCmd.Flags().StringVar(&filterArg, "filter", "", `filter expression (e.g. '.status >= 500')`)
+	rootCmd.Flags().StringVar(&since, "since", "", `show entries from this time on (e.g. "1h ago", "10:30", "2024-01-15T10:42")`)
+	rootCmd.Flags().StringVar(&until, "until", "", "show entries up to this time")
+	rootCmd.Flags().StringVar(&watchGlob, "glob", config.DefaultWatchGlob, "file names to follow when following a directory")
+	rootCmd.Flags().IntVar(&depth, "depth", config.DefaultWatchDepth, "how many subdirectories deep to follow files in a directory")
+	rootCmd.Flags().StringVar(&columnArg, "columns", "", `fields to show as columns (e.g. "ts,level,service,status,path")`)
+	rootCmd.Flags().IntVarP(&contextN, "context", "C", config.DefaultContextLines, "show this many entries around each filter match")
+	rootCmd.Flags().StringArrayVar(&excludes, "exclude", nil, "hide entries matching a filter expression or /regex/ (repeatable)")
 
 	rootCmd.Version = fmt.Sprintf("%s (built %s)", version, buildTime)
 
 	return rootCmd
 }
+
+// loadPresets returns the built-in presets plus those from the config file.
+func loadPresets(cfg *config.Config) *filter.PresetRegistry {
+	presets := filter.NewPresetRegistry()
+	names := make([]string, 0, len(cfg.Filters.Presets))
+	for name := range cfg.Filters.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := cfg.Filters.Presets[name]
+		presets.Register(filter.Preset{
+			Name:        name,
+			Description: p.Description,
+			Expression:  p.Filter,
+			Level:       logentry.ParseLevel(p.Level),
+		})
+	}
+	return presets
+}
+
+// setTimeRange applies the --since and --until flags.
+func setTimeRange(model *app.Model) error {
+	now := time.Now()
+	sinceTime, err := filter.ParseTime(since, now)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	untilTime, err := filter.ParseTime(until, now)
+	if err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+	model.SetTimeRange(sinceTime, untilTime)
+	return nil
+}
+
+// openHistory loads a history file from the state directory. An unreadable
+// file yields an empty history, which doesn't save over it, rather than an
+// error.
+func openHistory(name string) *history.History {
+	h, _ := history.Open(filepath.Join(config.StateDir(), name), history.DefaultLimit)
+	return h
+}
+
+// openLayouts loads the saved column layouts from the state directory. A
+// corrupt or unreadable file yields no layouts.
+func openLayouts() *columns.Store {
+	store, _ := columns.OpenStore(filepath.Join(config.StateDir(), "columns.json"))
+	return store
+}
+
+// openBookmarks loads the saved bookmarks from the state directory. A
+// corrupt or unreadable file yields no bookmarks.
+func openBookmarks() *bookmark.Store {
+	store, _ := bookmark.Open(filepath.Join(config.StateDir(), "bookmarks.json"))
+	return store
+}
@@ -0,0 +1,548 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/internal/theme"
+	"github.com/ersanisk/sieve/internal/ui"
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// Help sections actions are listed under, in order.
+const (
+	sectionNavigation = "Navigation"
+	sectionView       = "View & Actions"
+	sectionSearch     = "Search & Filter"
+	sectionLevel      = "Level Filter"
+	sectionFile       = "File & Program"
+)
+
+var actionSections = []string{sectionNavigation, sectionView, sectionSearch, sectionLevel, sectionFile}
+
+// paletteRecentFilters is how many recent filters the command palette lists.
+const paletteRecentFilters = 10
+
+// action is something sieve can do: run by its key, or picked by name from
+// the command palette. The key dispatch, the help overlay and the palette
+// are all built from the list of actions.
+type action struct {
+	// id names the action in palette item IDs, e.g. "toggle-wrap".
+	id      string
+	title   string
+	section string
+	// binding returns the key in the key map that runs the action, or nil
+	// for actions only run from the palette.
+	binding func(k *KeyMap) *keyBinding
+	// keys are further keys that run the action.
+	keys []string
+	// label replaces the keys in help, for keys that take an argument.
+	label string
+	run   func(m Model) (Model, tea.Cmd)
+}
+
+// actions returns every action, in the order they are listed.
+func actions() []action {
+	return []action{
+		{id: "scroll-down", title: "Scroll down", section: sectionNavigation, keys: []string{"down"},
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollDown },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollDownOne()
+				m.updateSelectedEntry()
+				return m, tickCmd()
+			}},
+		{id: "scroll-up", title: "Scroll up", section: sectionNavigation, keys: []string{"up"},
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollUp },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollUpOne()
+				m.updateSelectedEntry()
+				return m, tickCmd()
+			}},
+		{id: "scroll-left", title: "Scroll long lines left", section: sectionNavigation, keys: []string{"left"},
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollLeft },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollLeft(horizontalScrollStep)
+				return m, tickCmd()
+			}},
+		{id: "scroll-right", title: "Scroll long lines right", section: sectionNavigation, keys: []string{"right"},
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollRight },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollRight(horizontalScrollStep)
+				return m, tickCmd()
+			}},
+		{id: "page-down", title: "Page down", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollPageDown },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollPageDown()
+				m.updateSelectedEntry()
+				return m, tickCmd()
+			}},
+		{id: "page-up", title: "Page up", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollPageUp },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollPageUp()
+				m.updateSelectedEntry()
+				return m, tickCmd()
+			}},
+		{id: "top", title: "Go to top", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollToTop },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollToTop()
+				m.updateSelectedEntry()
+				return m, tickCmd()
+			}},
+		{id: "bottom", title: "Go to bottom, resume auto-scroll", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.ScrollToBottom },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.ScrollToBottom()
+				m.updateSelectedEntry()
+				return m, tickCmd()
+			}},
+		{id: "goto", title: "Go to line, time or percent", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.Goto },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.gotoPrompt.Show()
+				m.mode = "goto"
+				return m, tickCmd()
+			}},
+		{id: "bookmark", title: "Toggle bookmark", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.Bookmark },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.setBookmark(""))
+			}},
+		{id: "set-mark", title: "Set mark", section: sectionNavigation, label: "m{a-z}",
+			binding: func(k *KeyMap) *keyBinding { return &k.SetMark },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.pendingKey = m.keyMap.SetMark.key.String()
+				return m, tickCmd()
+			}},
+		{id: "jump-mark", title: "Jump to mark, '' next bookmark", section: sectionNavigation, label: "'{a-z}",
+			binding: func(k *KeyMap) *keyBinding { return &k.JumpMark },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.pendingKey = m.keyMap.JumpMark.key.String()
+				return m, tickCmd()
+			}},
+		{id: "bookmarks", title: "Bookmarks panel", section: sectionNavigation,
+			binding: func(k *KeyMap) *keyBinding { return &k.Bookmarks },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.syncBookmarks()
+				m.bookmarksPanel.Show()
+				return m, tickCmd()
+			}},
+
+		{id: "palette", title: "Command palette", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.Palette },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.showPalette()
+				return m, tickCmd()
+			}},
+		{id: "details", title: "View log details", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.Expand },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logDetail.Show(m.selectedEntry)
+				return m, tickCmd()
+			}},
+		{id: "toggle-wrap", title: "Toggle line wrap", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleWrap },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.logView.SetWrap(!m.logView.IsWrapped())
+				if m.logView.IsWrapped() {
+					m.statusBar.SetInfo("Line wrap on")
+				} else {
+					m.statusBar.SetInfo("Line wrap off")
+				}
+				return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+			}},
+		{id: "toggle-columns", title: "Toggle columns view", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleColumns },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.toggleColumns())
+			}},
+		{id: "toggle-markers", title: "Toggle gap and burst markers", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleMarkers },
+			run:     Model.toggleTimeMarkers},
+		{id: "relative-time", title: "Relative time: off, since previous, since pinned", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.RelativeTime },
+			run:     Model.cycleRelativeTime},
+		{id: "pin-reference", title: "Pin entry as relative time reference", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.PinReference },
+			run:     Model.pinReference},
+		{id: "sort-column", title: "Sort by next column", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.SortColumn },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.cycleSortColumn())
+			}},
+		{id: "toggle-inspector", title: "Toggle field inspector", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleSidebar },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.toggleSidebar()
+				return m, tickCmd()
+			}},
+		{id: "switch-pane", title: "Switch between log and inspector", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.SwitchPane },
+			run: func(m Model) (Model, tea.Cmd) {
+				if !m.sidebar.IsVisible() {
+					m.toggleSidebar()
+				}
+				m.sidebar.Focus()
+				return m, tickCmd()
+			}},
+		{id: "shrink-inspector", title: "Narrow inspector", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ShrinkPane },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.resizeSidebar(-1))
+			}},
+		{id: "grow-inspector", title: "Widen inspector", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.GrowPane },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.resizeSidebar(1))
+			}},
+		{id: "copy", title: "Copy entry", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.Copy },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.copyEntries(false))
+			}},
+		{id: "copy-json", title: "Copy entry as JSON", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.CopyJSON },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.copyEntries(true))
+			}},
+		{id: "visual", title: "Select range to copy", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.Visual },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.toggleVisual()
+				return m, tickCmd()
+			}},
+		{id: "dashboard", title: "Toggle dashboard", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleDashboard },
+			run: func(m Model) (Model, tea.Cmd) {
+				if m.dashboard.IsVisible() {
+					m.dashboard.Hide()
+				} else {
+					m.dashboard.Show()
+				}
+				return m, tickCmd()
+			}},
+		{id: "sources", title: "Followed sources", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleSources },
+			run: func(m Model) (Model, tea.Cmd) {
+				if m.follower != nil {
+					m.sourcesPanel.SetSources(sourceItems(m.follower.manager.Statuses()), m.follower.manager.Dropped())
+				}
+				m.sourcesPanel.Show()
+				return m, tickCmd()
+			}},
+		{id: "help", title: "Toggle help", section: sectionView,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleHelp },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.help.Show()
+				m.mode = "help"
+				return m, tickCmd()
+			}},
+
+		{id: "search", title: "Search", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.Search },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.searchBar.Show()
+				m.mode = "search"
+				return m, tickCmd()
+			}},
+		{id: "search-next", title: "Next search result", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.SearchNext },
+			run:     Model.searchNext},
+		{id: "search-prev", title: "Previous search result", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.SearchPrev },
+			run:     Model.searchPrev},
+		{id: "clear-search", title: "Clear search", section: sectionSearch,
+			run: func(m Model) (Model, tea.Cmd) {
+				m.clearSearch()
+				return m, tickCmd()
+			}},
+		{id: "filter", title: "Filter expression", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.Filter },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.filterBar.Show()
+				m.mode = "filter"
+				return m, tickCmd()
+			}},
+		{id: "clear-filter", title: "Clear filter", section: sectionSearch,
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.clearFilter())
+			}},
+		{id: "presets", title: "Filter presets", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.Presets },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.showPresetPicker()
+				return m, tickCmd()
+			}},
+		{id: "save-preset", title: "Save filter as preset", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.SavePreset },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.presetPicker.ShowSave()
+				m.mode = "presets"
+				return m, tickCmd()
+			}},
+		{id: "toggle-context", title: "Toggle context around matches", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleContext },
+			run:     Model.toggleContext},
+		{id: "more-context", title: "More context around matches", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.MoreContext },
+			run:     func(m Model) (Model, tea.Cmd) { return m.adjustContext(1) }},
+		{id: "less-context", title: "Less context around matches", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.LessContext },
+			run:     func(m Model) (Model, tea.Cmd) { return m.adjustContext(-1) }},
+		{id: "suspend-filter", title: "Suspend/resume filter, keeping the selection", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.SuspendFilter },
+			run:     Model.toggleSuspended},
+		{id: "correlate", title: "Entries sharing this trace/request ID", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.Correlate },
+			run:     Model.correlate},
+		{id: "call-tree", title: "Span call tree of this trace", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.CallTree },
+			run:     Model.showCallTree},
+		{id: "exclude-like", title: "Exclude entries like this", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.ExcludeLike },
+			run:     Model.excludeLikeSelected},
+		{id: "toggle-excluded", title: "Show/hide excluded entries", section: sectionSearch,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleExcluded },
+			run:     Model.toggleExcluded},
+
+		{id: "level-debug", title: "Debug and above", section: sectionLevel,
+			binding: func(k *KeyMap) *keyBinding { return &k.LevelDebug },
+			run:     func(m Model) (Model, tea.Cmd) { return m.toggleLevel(logentry.Debug) }},
+		{id: "level-info", title: "Info and above", section: sectionLevel,
+			binding: func(k *KeyMap) *keyBinding { return &k.LevelInfo },
+			run:     func(m Model) (Model, tea.Cmd) { return m.toggleLevel(logentry.Info) }},
+		{id: "level-warn", title: "Warn and above", section: sectionLevel,
+			binding: func(k *KeyMap) *keyBinding { return &k.LevelWarn },
+			run:     func(m Model) (Model, tea.Cmd) { return m.toggleLevel(logentry.Warn) }},
+		{id: "level-error", title: "Error and above", section: sectionLevel,
+			binding: func(k *KeyMap) *keyBinding { return &k.LevelError },
+			run:     func(m Model) (Model, tea.Cmd) { return m.toggleLevel(logentry.Error) }},
+		{id: "level-fatal", title: "Fatal", section: sectionLevel,
+			binding: func(k *KeyMap) *keyBinding { return &k.LevelFatal },
+			run:     func(m Model) (Model, tea.Cmd) { return m.toggleLevel(logentry.Fatal) }},
+		{id: "level-none", title: "No level filter", section: sectionLevel,
+			binding: func(k *KeyMap) *keyBinding { return &k.LevelNone },
+			run:     func(m Model) (Model, tea.Cmd) { return m.applyLevel(logentry.Unknown) }},
+
+		{id: "toggle-sort", title: "Toggle sort order", section: sectionFile,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleSort },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.toggleSort()
+				if m.sortColumn != "" {
+					return m, tea.Batch(tickCmd(), m.saveColumnsCmd(), clearInfoCmd(2*time.Second))
+				}
+				return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+			}},
+		{id: "refresh", title: "Refresh file", section: sectionFile,
+			binding: func(k *KeyMap) *keyBinding { return &k.RefreshFile },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), loadFileCmd(m.filePath, m.followMode), tickCmd())
+			}},
+		{id: "follow", title: "Toggle follow mode", section: sectionFile,
+			binding: func(k *KeyMap) *keyBinding { return &k.ToggleFollow },
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, tea.Batch(tickCmd(), m.toggleFollow())
+			}},
+		{id: "open-file", title: "Open file", section: sectionFile,
+			run: func(m Model) (Model, tea.Cmd) {
+				dir := filepath.Dir(m.filePath)
+				if m.filePath == "" {
+					dir, _ = os.Getwd()
+				}
+				return m, func() tea.Msg { return ui.ShowFilePickerMsg{Directory: dir} }
+			}},
+		{id: "force-quit", title: "Force quit", section: sectionFile,
+			binding: func(k *KeyMap) *keyBinding { return &k.ForceQuit },
+			run:     func(m Model) (Model, tea.Cmd) { return m, tea.Quit }},
+		{id: "quit", title: "Quit", section: sectionFile,
+			binding: func(k *KeyMap) *keyBinding { return &k.Quit },
+			run:     func(m Model) (Model, tea.Cmd) { return m, tea.Quit }},
+	}
+}
+
+// actionKeys returns the keys that run a, as compared with
+// tea.KeyMsg.String().
+func (a action) actionKeys(k *KeyMap) []string {
+	var keys []string
+	if a.binding != nil {
+		keys = append(keys, a.binding(k).key.String())
+	}
+	return append(keys, a.keys...)
+}
+
+// keyLabel returns how a, run with the keys in k, is shown in help and the
+// palette.
+func (a action) keyLabel(k *KeyMap) string {
+	if a.label != "" {
+		return a.label
+	}
+	keys := a.actionKeys(k)
+	for i, key := range keys {
+		keys[i] = keyName(key)
+	}
+	return strings.Join(keys, " / ")
+}
+
+// keyNames are the names keys are shown with in help, where they differ
+// from tea.KeyMsg.String().
+var keyNames = map[string]string{
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"pgup":   "PgUp",
+	"pgdown": "PgDn",
+	"tab":    "Tab",
+	"enter":  "Enter",
+	"esc":    "Esc",
+	" ":      "Space",
+}
+
+// keyName returns how key is shown in help.
+func keyName(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	return key
+}
+
+// actionFor returns the action run by key.
+func (m Model) actionFor(key string) (action, bool) {
+	for _, a := range actions() {
+		if slices.Contains(a.actionKeys(&m.keyMap), key) {
+			return a, true
+		}
+	}
+	return action{}, false
+}
+
+// helpSections lists the actions with keys by section, for the help
+// overlay.
+func helpSections(k KeyMap) []ui.HelpSection {
+	all := actions()
+	sections := make([]ui.HelpSection, 0, len(actionSections))
+	for _, title := range actionSections {
+		section := ui.HelpSection{Title: title}
+		for _, a := range all {
+			if a.section == title && (a.binding != nil || len(a.keys) > 0) {
+				section.Bindings = append(section.Bindings, ui.HelpBinding{Key: a.keyLabel(&k), Description: a.title})
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// showPalette opens the command palette listing the actions, filter
+// presets, themes, files and recent filters.
+func (m *Model) showPalette() {
+	m.palette.Show(m.paletteItems())
+	m.mode = "palette"
+}
+
+// paletteItems returns what the command palette lists.
+func (m Model) paletteItems() []ui.PaletteItem {
+	var items []ui.PaletteItem
+	for _, a := range actions() {
+		if a.id == "palette" {
+			continue
+		}
+		items = append(items, ui.PaletteItem{ID: "action:" + a.id, Title: a.title, Key: a.keyLabel(&m.keyMap)})
+	}
+	for _, p := range m.presets.All() {
+		items = append(items, ui.PaletteItem{ID: "preset:" + p.Name, Title: "Apply preset: " + p.Name})
+	}
+	themes := theme.Names()
+	sort.Strings(themes)
+	for _, name := range themes {
+		title := "Switch theme: " + name
+		if name == m.theme.Name() {
+			title += " (current)"
+		}
+		items = append(items, ui.PaletteItem{ID: "theme:" + name, Title: title})
+	}
+	for _, path := range m.paletteFiles() {
+		items = append(items, ui.PaletteItem{ID: "file:" + path, Title: "Open file: " + path})
+	}
+	if m.filterHistory != nil {
+		for _, expr := range m.filterHistory.Recent(paletteRecentFilters) {
+			items = append(items, ui.PaletteItem{ID: "filter:" + expr, Title: "Apply filter: " + expr})
+		}
+	}
+	return items
+}
+
+// paletteFiles returns the files the command palette offers to open: the
+// loaded ones and those found for the file picker.
+func (m Model) paletteFiles() []string {
+	var files []string
+	for path := range m.loadedFiles {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	for _, path := range m.filePicker.Files() {
+		if !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// runPaletteItem runs the command palette item with id.
+func (m Model) runPaletteItem(id string) (Model, tea.Cmd) {
+	kind, arg, _ := strings.Cut(id, ":")
+	switch kind {
+	case "action":
+		for _, a := range actions() {
+			if a.id == arg {
+				return a.run(m)
+			}
+		}
+	case "preset":
+		return m.applyPreset(arg)
+	case "theme":
+		m.setTheme(arg)
+		return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+	case "file":
+		return m, func() tea.Msg { return ui.FileSelectedMsg{Path: arg} }
+	case "filter":
+		m.filterBar.SetValue(arg)
+		return m.applyFilter(arg)
+	}
+	m.statusBar.SetError(fmt.Sprintf("Unknown command: %s", id))
+	return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+}
+
+// setTheme switches every component to the theme called name.
+func (m *Model) setTheme(name string) {
+	t := theme.Get(name)
+	m.theme = t
+	m.logView.SetTheme(t)
+	m.statusBar.SetTheme(t)
+	m.searchBar.SetTheme(t)
+	m.filterBar.SetTheme(t)
+	m.sidebar.SetTheme(t)
+	m.help.SetTheme(t)
+	m.dashboard.SetTheme(t)
+	m.logDetail.SetTheme(t)
+	m.filePicker.SetTheme(t)
+	m.presetPicker.SetTheme(t)
+	m.sourcesPanel.SetTheme(t)
+	m.bookmarksPanel.SetTheme(t)
+	m.tracePanel.SetTheme(t)
+	m.gotoPrompt.SetTheme(t)
+	m.palette.SetTheme(t)
+	m.statusBar.SetInfo(fmt.Sprintf("Theme: %s", t.Name()))
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/internal/bookmark"
+	"github.com/ersanisk/sieve/internal/ui"
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// isMarkName reports whether key names a mark: a letter from a to z.
+func isMarkName(key string) bool {
+	return len(key) == 1 && key[0] >= 'a' && key[0] <= 'z'
+}
+
+// fileKey returns the key the bookmarks and column layout of the open
+// file or directory are stored under: its absolute path.
+func (m *Model) fileKey() string {
+	if m.filePath == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(m.filePath)
+	if err != nil {
+		return m.filePath
+	}
+	return abs
+}
+
+// bookmarkSource returns the source of an entry as a bookmark stores it:
+// empty for the open file, or relative to the open directory.
+func (m *Model) bookmarkSource(source string) string {
+	if source == m.filePath {
+		return ""
+	}
+	rel, err := filepath.Rel(filepath.Clean(m.filePath), source)
+	if err != nil {
+		return source
+	}
+	return rel
+}
+
+// bookmarkID returns the identity of the entry a bookmark marks.
+func (m *Model) bookmarkID(b bookmark.Bookmark) logentry.ID {
+	if b.Source == "" {
+		return logentry.ID{Source: m.filePath, Offset: b.Offset}
+	}
+	return logentry.ID{Source: filepath.Join(filepath.Clean(m.filePath), b.Source), Offset: b.Offset}
+}
+
+// handleMarkKey finishes a two-key mark command: m{a-z} sets a mark, '{a-z}
+// jumps to one and pressing ' twice jumps to the next bookmark.
+func (m Model) handleMarkKey(prefix, key string) (Model, tea.Cmd) {
+	switch {
+	case prefix == m.keyMap.SetMark.key.String() && isMarkName(key):
+		return m, tea.Batch(tickCmd(), m.setBookmark(key))
+	case prefix == m.keyMap.JumpMark.key.String() && isMarkName(key):
+		b, ok := m.bookmarks.Named(m.fileKey(), key)
+		if !ok {
+			m.statusBar.SetError(fmt.Sprintf("Mark '%s' is not set", key))
+			return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+		}
+		return m, tea.Batch(tickCmd(), m.jumpToBookmark(b))
+	case prefix == m.keyMap.JumpMark.key.String() && key == prefix:
+		return m, tea.Batch(tickCmd(), m.nextBookmark())
+	}
+	return m, tickCmd()
+}
+
+// setBookmark bookmarks the selected entry under a mark name. An empty
+// name toggles an anonymous bookmark instead.
+func (m *Model) setBookmark(name string) tea.Cmd {
+	entry, index := m.logView.GetSelected()
+	if index < 0 {
+		return nil
+	}
+
+	b := bookmark.Bookmark{
+		Name:    name,
+		Source:  m.bookmarkSource(entry.Source),
+		Offset:  entry.Offset,
+		Line:    entry.Line,
+		Message: entry.Message,
+	}
+	switch {
+	case name != "":
+		m.bookmarks.Set(m.fileKey(), b)
+		m.statusBar.SetInfo(fmt.Sprintf("Set mark '%s' at line %d", name, entry.Line))
+	case m.bookmarks.Toggle(m.fileKey(), b):
+		m.statusBar.SetInfo(fmt.Sprintf("Bookmarked line %d", entry.Line))
+	default:
+		m.statusBar.SetInfo(fmt.Sprintf("Removed bookmark at line %d", entry.Line))
+	}
+	m.syncBookmarks()
+	return tea.Batch(m.saveBookmarksCmd(), clearInfoCmd(3*time.Second))
+}
+
+// deleteBookmark removes the bookmark at index in the bookmarks panel.
+func (m *Model) deleteBookmark(index int) tea.Cmd {
+	bookmarks := m.bookmarks.List(m.fileKey())
+	if index < 0 || index >= len(bookmarks) {
+		return nil
+	}
+	b := bookmarks[index]
+	m.bookmarks.Remove(m.fileKey(), b.Source, b.Offset)
+	m.syncBookmarks()
+	return m.saveBookmarksCmd()
+}
+
+// jumpToBookmark selects the entry a bookmark marks.
+func (m *Model) jumpToBookmark(b bookmark.Bookmark) tea.Cmd {
+	pos, ok := m.positionOf(m.bookmarkID(b))
+	if !ok {
+		m.statusBar.SetError(fmt.Sprintf("Bookmarked line %d is not shown; clear the filter to see it", b.Line))
+		return clearInfoCmd(3 * time.Second)
+	}
+	m.logView.SetSelected(pos)
+	m.updateSelectedEntry()
+	return nil
+}
+
+// nextBookmark selects the next shown bookmarked entry after the selected
+// one, wrapping around to the first.
+func (m *Model) nextBookmark() tea.Cmd {
+	_, selected := m.logView.GetSelected()
+	first, next := -1, -1
+	for _, b := range m.bookmarks.List(m.fileKey()) {
+		pos, ok := m.positionOf(m.bookmarkID(b))
+		if !ok {
+			continue
+		}
+		if first < 0 || pos < first {
+			first = pos
+		}
+		if pos > selected && (next < 0 || pos < next) {
+			next = pos
+		}
+	}
+	if next < 0 {
+		next = first
+	}
+	if next < 0 {
+		m.statusBar.SetInfo("No bookmarks shown")
+		return clearInfoCmd(3 * time.Second)
+	}
+	m.logView.SetSelected(next)
+	m.updateSelectedEntry()
+	return nil
+}
+
+// syncBookmarks shows the bookmarks of the open file in the gutter and the
+// bookmarks panel.
+func (m *Model) syncBookmarks() {
+	bookmarks := m.bookmarks.List(m.fileKey())
+	marks := make(map[logentry.ID]string, len(bookmarks))
+	items := make([]ui.BookmarkItem, len(bookmarks))
+	for i, b := range bookmarks {
+		marks[m.bookmarkID(b)] = b.Name
+		items[i] = ui.BookmarkItem{Name: b.Name, Source: b.Source, Line: b.Line, Message: b.Message}
+	}
+	m.logView.SetBookmarks(marks)
+	m.bookmarksPanel.SetBookmarks(items)
+}
+
+// saveBookmarksCmd persists the bookmarks, unless the entries come from
+// standard input.
+func (m *Model) saveBookmarksCmd() tea.Cmd {
+	if m.filePath == "" {
+		return nil
+	}
+	return saveBestEffortCmd(m.bookmarks.Save)
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/internal/columns"
+)
+
+// defaultPinColumns are the columns a columns view starts with when a
+// field is pinned without any columns set.
+var defaultPinColumns = []string{columns.Time, columns.Level, columns.Message}
+
+// restoreColumns shows the column layout saved for the open file, or the
+// configured columns when it has none.
+func (m *Model) restoreColumns() {
+	layout, ok := m.layouts.Get(m.fileKey())
+	if !ok {
+		layout = columns.Layout{Columns: m.defaultColumns}
+	}
+	m.columns = layout.Columns
+	m.showColumns = len(m.columns) > 0
+	m.sortColumn = ""
+	if slices.Contains(m.columns, layout.SortBy) {
+		m.sortColumn = layout.SortBy
+		m.sortOrder = SortAsc
+		if layout.Desc {
+			m.sortOrder = SortDesc
+		}
+	}
+	m.syncColumns()
+}
+
+// syncColumns shows the columns in the log view while the columns view is
+// on.
+func (m *Model) syncColumns() {
+	if m.showColumns {
+		m.logView.SetColumns(m.columns)
+	} else {
+		m.logView.SetColumns(nil)
+	}
+	m.logView.SetSortColumn(m.sortColumn, m.sortOrder == SortDesc)
+}
+
+// toggleColumns turns the columns view on or off.
+func (m *Model) toggleColumns() tea.Cmd {
+	if len(m.columns) == 0 {
+		m.statusBar.SetInfo("No columns; pin fields from the inspector with c or set columns in the config")
+		return clearInfoCmd(3 * time.Second)
+	}
+	m.showColumns = !m.showColumns
+	m.syncColumns()
+	return nil
+}
+
+// pinColumn adds the field selected in the inspector to the columns, or
+// removes it when it is one already.
+func (m *Model) pinColumn() tea.Cmd {
+	field := m.sidebar.SelectedField()
+	if field == nil {
+		return nil
+	}
+	name := strings.TrimPrefix(field.Path, ".")
+
+	if i := slices.Index(m.columns, name); i >= 0 {
+		m.columns = slices.Delete(slices.Clone(m.columns), i, i+1)
+		if m.sortColumn == name {
+			m.sortColumn = ""
+			m.applySort()
+		}
+		m.statusBar.SetInfo(fmt.Sprintf("Unpinned column %s", name))
+	} else {
+		if len(m.columns) == 0 {
+			m.columns = defaultPinColumns
+		}
+		// Pinned fields go before a trailing message column, which takes
+		// what is left of the screen.
+		at := len(m.columns)
+		if last, _ := columns.Builtin(m.columns[at-1]); last == columns.Message {
+			at--
+		}
+		m.columns = slices.Insert(slices.Clone(m.columns), at, name)
+		m.statusBar.SetInfo(fmt.Sprintf("Pinned column %s", name))
+	}
+	m.showColumns = len(m.columns) > 0
+	m.syncColumns()
+	return tea.Batch(m.saveColumnsCmd(), clearInfoCmd(3*time.Second))
+}
+
+// cycleSortColumn sorts the entries by the next column, or back in file
+// order after the last one.
+func (m *Model) cycleSortColumn() tea.Cmd {
+	if !m.showColumns {
+		return nil
+	}
+	next := 0
+	if i := slices.Index(m.columns, m.sortColumn); i >= 0 {
+		next = i + 1
+	}
+	m.sortColumn = ""
+	if next < len(m.columns) {
+		m.sortColumn = m.columns[next]
+	}
+	m.sortOrder = SortAsc
+	m.applySort()
+	return tea.Batch(m.saveColumnsCmd(), clearInfoCmd(3*time.Second))
+}
+
+// saveColumnsCmd remembers the column layout of the open file, unless the
+// entries come from standard input.
+func (m *Model) saveColumnsCmd() tea.Cmd {
+	if m.filePath == "" {
+		return nil
+	}
+	m.layouts.Set(m.fileKey(), columns.Layout{
+		Columns: m.columns,
+		SortBy:  m.sortColumn,
+		Desc:    m.sortColumn != "" && m.sortOrder == SortDesc,
+	})
+	return saveBestEffortCmd(m.layouts.Save)
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -11,34 +13,90 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/ersanisk/sieve/internal/clipboard"
+	"github.com/ersanisk/sieve/internal/config"
+	"github.com/ersanisk/sieve/internal/filter"
 	"github.com/ersanisk/sieve/internal/parser"
+	"github.com/ersanisk/sieve/internal/search"
+	"github.com/ersanisk/sieve/internal/tail"
 	"github.com/ersanisk/sieve/internal/ui"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
 
-// loadFileCmd loads a file and parses its contents.
-func loadFileCmd(path string) tea.Cmd {
+// loadFileCmd loads a file and parses its contents. With follow set, a final
+// line without a newline is left for following to read once it is finished.
+func loadFileCmd(path string, follow bool) tea.Cmd {
 	return func() tea.Msg {
-		parser := parser.NewParser()
+		entries, size, err := readLogFile(path, parser.NewParser(), follow)
+		if err != nil {
+			return ui.ErrorMsg{Error: err}
+		}
 
-		file, err := os.Open(path)
+		return ui.FileLoadedMsg{Path: path, Entries: entries, Size: size}
+	}
+}
+
+// loadDirCmd loads the files under dir matching glob, at most depth
+// directories down, and merges their entries by time.
+func loadDirCmd(dir, glob string, depth int) tea.Cmd {
+	return func() tea.Msg {
+		paths, err := tail.MatchFiles(dir, glob, depth)
 		if err != nil {
-			return ui.ErrorMsg{Error: fmt.Errorf("failed to open file: %w", err)}
+			return ui.ErrorMsg{Error: fmt.Errorf("failed to list files: %w", err)}
 		}
-		defer func() {
-			if closeErr := file.Close(); closeErr != nil {
-				// Log error but don't override the main error
-				fmt.Fprintf(os.Stderr, "Warning: failed to close file: %v\n", closeErr)
+
+		p := parser.NewParser()
+		var entries []logentry.Entry
+		sizes := make(map[string]int64, len(paths))
+		for _, path := range paths {
+			fileEntries, size, err := readLogFile(path, p, true)
+			if err != nil {
+				continue // Unreadable files are skipped
 			}
-		}()
+			entries = append(entries, fileEntries...)
+			sizes[path] = size
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		})
 
-		entries, err := parser.ParseLines(file)
-		if err != nil {
-			return ui.ErrorMsg{Error: fmt.Errorf("failed to parse file: %w", err)}
+		return ui.FileLoadedMsg{Path: dir, Entries: entries, Files: sizes}
+	}
+}
+
+// readLogFile parses a file, returning its entries and the number of bytes
+// read. With follow set, parsing stops after the last newline: a writer may
+// be in the middle of the line after it, which following reads once finished.
+func readLogFile(path string, p *parser.Parser, follow bool) ([]logentry.Entry, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			// Log error but don't override the main error
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file: %v\n", closeErr)
 		}
+	}()
+
+	// Following continues from what was read, not from a later size.
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	if follow {
+		data = data[:bytes.LastIndexByte(data, '\n')+1]
+	}
 
-		return ui.FileLoadedMsg{Path: path, Entries: entries}
+	entries, err := p.ParseLines(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse file: %w", err)
 	}
+	for i := range entries {
+		entries[i].Source = path
+	}
+
+	return entries, int64(len(data)), nil
 }
 
 // tickCmd returns a tick command for animations.
@@ -57,41 +115,104 @@ func clearInfoCmd(delay time.Duration) tea.Cmd {
 
 // NewLinesMsg is sent when new lines are appended to the followed file.
 type NewLinesMsg struct {
+	// Source is the file the lines were read from.
+	Source  string
 	Entries []logentry.Entry
+	// Rotated and Truncated report that the file was replaced or cut short,
+	// and entries are now read from its beginning. Their offsets continue
+	// from Base, so entry IDs stay unique.
+	Rotated   bool
+	Truncated bool
+	Base      int64
 }
 
-// followCmd reads new lines appended to the file since lastSize.
-func followCmd(path string, lastSize int64, p *parser.Parser) tea.Cmd {
+// indexEntriesCmd adds entries to the search index in the background. base is
+// the position of the first entry in the model's entry list.
+func indexEntriesCmd(ix *search.Index, base int, entries []logentry.Entry) tea.Cmd {
 	return func() tea.Msg {
-		info, err := os.Stat(path)
-		if err != nil {
-			return nil
-		}
-		if info.Size() <= lastSize {
-			return nil
-		}
+		ix.Add(base, entries)
+		return nil
+	}
+}
 
-		f, err := os.Open(path)
-		if err != nil {
-			return nil
-		}
-		defer func() {
-			_ = f.Close()
-		}()
+// searchChunk is how many candidate entries a search job scans per message.
+const searchChunk = 20000
+
+// filterChunk is how many entries a filter job scans per message. Lists no
+// longer than this are filtered synchronously.
+const filterChunk = 50000
 
-		if _, err := f.Seek(lastSize, io.SeekStart); err != nil {
+// SearchResultsMsg carries the matches found in one chunk of a search job.
+type SearchResultsMsg struct {
+	JobID   int
+	Results []search.SearchResult
+	Done    bool
+}
+
+// searchChunkCmd scans the next chunk of a search job. Nothing is sent once
+// ctx is cancelled.
+func searchChunkCmd(ctx context.Context, job *search.Job) tea.Cmd {
+	return func() tea.Msg {
+		results := job.Next(ctx, searchChunk)
+		if ctx.Err() != nil {
 			return nil
 		}
+		return SearchResultsMsg{JobID: job.ID, Results: results, Done: job.Done()}
+	}
+}
+
+// FilterResultsMsg carries the entries that passed one chunk of a filter job.
+type FilterResultsMsg struct {
+	JobID   int
+	Entries []logentry.Entry
+	// First is set on the job's first chunk, which replaces the shown entries.
+	First bool
+	Done  bool
+}
 
-		entries, err := p.ParseLines(f)
-		if err != nil || len(entries) == 0 {
+// filterChunkCmd filters the next chunk of a filter job. Nothing is sent once
+// ctx is cancelled.
+func filterChunkCmd(ctx context.Context, job *filter.Job) tea.Cmd {
+	return func() tea.Msg {
+		first := job.Scanned() == 0
+		entries := job.Next(ctx, filterChunk)
+		if ctx.Err() != nil {
 			return nil
 		}
+		return FilterResultsMsg{JobID: job.ID, Entries: entries, First: first, Done: job.Done()}
+	}
+}
 
-		return NewLinesMsg{Entries: entries}
+// savePresetCmd writes a preset to the config file.
+func savePresetCmd(path, name string, preset config.PresetConfig) tea.Cmd {
+	return func() tea.Msg {
+		return ui.PresetSavedMsg{Name: name, Err: config.SavePreset(path, name, preset)}
+	}
+}
+
+// saveBestEffortCmd runs save in the background. History, bookmarks and
+// layouts are best-effort, so write errors are ignored.
+func saveBestEffortCmd(save func() error) tea.Cmd {
+	return func() tea.Msg {
+		_ = save()
+		return nil
 	}
 }
 
+// copyCmd copies text in the background, describing it as what in the
+// result.
+func copyCmd(cb *clipboard.Clipboard, text, what string) tea.Cmd {
+	return func() tea.Msg {
+		method, err := cb.Copy(text)
+		return ui.CopiedMsg{What: what, Method: string(method), Err: err}
+	}
+}
+
+// saveLayoutCmd remembers the share of the screen the field inspector gets.
+func saveLayoutCmd(path string, ratio float64) tea.Cmd {
+	return saveBestEffortCmd(func() error { return config.SavePaneRatio(path, ratio) })
+}
+
 // findLogFilesCmd searches for .log files in the given directory.
 func findLogFilesCmd(dir string) tea.Cmd {
 	return func() tea.Msg {
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// maxContextLines is the most entries the context view shows around each
+// match.
+const maxContextLines = 100
+
+// withContext returns the entries of all that are in matches, each with up
+// to n entries before and after it, in the order of all. It also returns
+// the entries added as context, and the first entry of each group after the
+// first with how many entries were skipped before it.
+func withContext(all, matches []logentry.Entry, n int) ([]logentry.Entry, map[logentry.ID]bool, map[logentry.ID]int) {
+	matched := make(map[logentry.ID]bool, len(matches))
+	for _, entry := range matches {
+		matched[entry.ID()] = true
+	}
+
+	shown := make([]logentry.Entry, 0, len(matches))
+	context := make(map[logentry.ID]bool)
+	breaks := make(map[logentry.ID]int)
+	// next is the first entry not shown yet.
+	next := 0
+	for i, entry := range all {
+		if !matched[entry.ID()] {
+			continue
+		}
+		from := max(i-n, next)
+		if from > next && len(shown) > 0 {
+			breaks[all[from].ID()] = from - next
+		}
+		to := min(i+n, len(all)-1)
+		for j := from; j <= to; j++ {
+			if !matched[all[j].ID()] {
+				context[all[j].ID()] = true
+			}
+			shown = append(shown, all[j])
+		}
+		next = max(next, to+1)
+	}
+	return shown, context, breaks
+}
+
+// contextActive reports whether the context view adds entries around the
+// filter matches: it is on and a filter is in effect.
+func (m Model) contextActive() bool {
+	return m.showContext && m.contextLines > 0 && m.pipeline.IsFiltering() && !m.pipeline.Suspended()
+}
+
+// addContext adds the entries around the matches in filtered while the
+// context view is active, replacing those added before. It waits for a
+// running filter job, which is still adding matches.
+func (m *Model) addContext() {
+	if m.contextIDs != nil {
+		matches := make([]logentry.Entry, 0, len(m.filtered))
+		for _, entry := range m.filtered {
+			if !m.contextIDs[entry.ID()] {
+				matches = append(matches, entry)
+			}
+		}
+		m.filtered = matches
+	}
+	m.contextIDs, m.contextBreaks = nil, nil
+	if !m.contextActive() || m.filterJob != nil {
+		return
+	}
+	m.filtered, m.contextIDs, m.contextBreaks = withContext(m.entries, m.filtered, m.contextLines)
+}
+
+// showContextRows shows which entries are context in the log view. Groups
+// are only told apart while entries are in file order, which a pivot's
+// entries are not.
+func (m *Model) showContextRows() {
+	breaks := m.contextBreaks
+	if m.keepsSorted() {
+		breaks = nil
+	}
+	m.logView.SetContext(m.contextIDs, breaks)
+}
+
+// keepSelection selects the selected entry again once the next refilter is
+// done.
+func (m *Model) keepSelection() {
+	if entry, index := m.logView.GetSelected(); index >= 0 {
+		id := entry.ID()
+		m.keepSelected = &id
+	}
+}
+
+// reselect selects the entry kept by keepSelection, if it is shown.
+func (m *Model) reselect() {
+	if m.keepSelected == nil || m.filterJob != nil {
+		return
+	}
+	if i, ok := m.positionOf(*m.keepSelected); ok {
+		m.logView.SetSelected(i)
+	}
+	m.keepSelected = nil
+}
+
+// toggleContext turns the context view on or off.
+func (m Model) toggleContext() (Model, tea.Cmd) {
+	m.showContext = !m.showContext
+	if !m.showContext {
+		return m.refreshContext("Context view off")
+	}
+	return m.refreshContext(fmt.Sprintf("Context view: %d entries around each match", m.contextLines))
+}
+
+// adjustContext shows delta more entries around each match, turning the
+// context view on.
+func (m Model) adjustContext(delta int) (Model, tea.Cmd) {
+	m.showContext = true
+	m.contextLines = max(0, min(m.contextLines+delta, maxContextLines))
+	return m.refreshContext(fmt.Sprintf("Context: %d entries around each match", m.contextLines))
+}
+
+// refreshContext shows the filtered entries with or without context,
+// keeping the selected entry.
+func (m Model) refreshContext(info string) (Model, tea.Cmd) {
+	if !m.pipeline.IsFiltering() {
+		info += " (applies while filtering)"
+	}
+	m.keepSelection()
+	if m.filterJob == nil {
+		m.showFiltered()
+		m.sortSearchResults()
+		m.updateSelectedEntry()
+	}
+	m.statusBar.SetInfo(info)
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
+
+// toggleSuspended drops the filter for a moment, or brings it back, keeping
+// the selected entry.
+func (m Model) toggleSuspended() (Model, tea.Cmd) {
+	if !m.pipeline.IsFiltering() {
+		m.statusBar.SetInfo("No filter to suspend")
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+	m.pipeline.SetSuspended(!m.pipeline.Suspended())
+	m.keepSelection()
+
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+	if m.pipeline.Suspended() {
+		m.statusBar.SetInfo(fmt.Sprintf("Filter suspended — %d entries", len(m.filtered)))
+	} else {
+		m.statusBar.SetInfo(fmt.Sprintf("Filter resumed — %d/%d entries", len(m.filtered), len(m.entries)))
+	}
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
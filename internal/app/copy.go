@@ -0,0 +1,135 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// entryText returns an entry as it appeared in the file.
+func entryText(entry logentry.Entry) string {
+	if entry.Raw != "" {
+		return entry.Raw
+	}
+	return entry.Message
+}
+
+// entryJSON returns an entry as a JSON object: the line itself when it is
+// one, or its parsed parts otherwise.
+func entryJSON(entry logentry.Entry) json.RawMessage {
+	raw := strings.TrimSpace(entry.Raw)
+	if strings.HasPrefix(raw, "{") && json.Valid([]byte(raw)) {
+		return json.RawMessage(raw)
+	}
+
+	obj := make(map[string]any, len(entry.Fields)+3)
+	for k, v := range entry.Fields {
+		obj[k] = v
+	}
+	if !entry.Timestamp.IsZero() {
+		obj["timestamp"] = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	obj["level"] = entry.Level.String()
+	obj["message"] = entry.Message
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// prettyJSON indents v for copying.
+func prettyJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// fieldText returns a field value for copying: strings as they are, other
+// values as JSON.
+func fieldText(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return prettyJSON(value)
+}
+
+// copyEntries copies the selected range of entries, or the selected entry
+// when there is no range: as they appeared in the file, or as JSON when
+// asJSON is set. Copying a range ends it.
+func (m *Model) copyEntries(asJSON bool) tea.Cmd {
+	entries := m.logView.GetEntries()
+	from, to, ok := m.logView.VisualRange()
+	if !ok {
+		_, from = m.logView.GetSelected()
+		to = from
+	}
+	if from < 0 || to >= len(entries) {
+		return nil
+	}
+	m.stopVisual()
+
+	selected := entries[from : to+1]
+	what := "entry"
+	if len(selected) > 1 {
+		what = fmt.Sprintf("%d entries", len(selected))
+	}
+
+	if !asJSON {
+		lines := make([]string, len(selected))
+		for i, entry := range selected {
+			lines[i] = entryText(entry)
+		}
+		return copyCmd(m.clipboard, strings.Join(lines, "\n"), what)
+	}
+	if len(selected) == 1 {
+		return copyCmd(m.clipboard, prettyJSON(entryJSON(selected[0])), what+" as JSON")
+	}
+	objs := make([]json.RawMessage, len(selected))
+	for i, entry := range selected {
+		objs[i] = entryJSON(entry)
+	}
+	return copyCmd(m.clipboard, prettyJSON(objs), what+" as JSON")
+}
+
+// copyField copies the value of the field selected in the inspector, or
+// its path when path is set.
+func (m *Model) copyField(path bool) tea.Cmd {
+	field := m.sidebar.SelectedField()
+	if field == nil {
+		return nil
+	}
+	if path {
+		return copyCmd(m.clipboard, field.Path, "path "+field.Path)
+	}
+	return copyCmd(m.clipboard, fieldText(field.Value), strings.TrimPrefix(field.Path, "."))
+}
+
+// toggleVisual starts or ends selecting a range of entries.
+func (m *Model) toggleVisual() {
+	if _, _, ok := m.logView.VisualRange(); ok {
+		m.stopVisual()
+		return
+	}
+	m.logView.StartVisual()
+	if _, _, ok := m.logView.VisualRange(); ok {
+		m.mode = "visual"
+		m.statusBar.SetMode(m.mode)
+	}
+}
+
+// stopVisual ends selecting a range of entries.
+func (m *Model) stopVisual() {
+	m.logView.StopVisual()
+	if m.mode == "visual" {
+		m.mode = "view"
+		m.statusBar.SetMode(m.mode)
+	}
+}
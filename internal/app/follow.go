@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/internal/parser"
+	"github.com/ersanisk/sieve/internal/tail"
+)
+
+// follower follows a file or a directory through a tail.Manager and turns
+// the batches it sends into messages. Only one waitCmd runs at a time and
+// only it touches the read state, so the state needs no lock.
+type follower struct {
+	path    string
+	parser  *parser.Parser
+	manager *tail.Manager
+	ctx     context.Context
+	cancel  context.CancelFunc
+	// waiting is set while a waitCmd runs; it is only used by the model.
+	waiting bool
+
+	// files is how far each followed file has been read.
+	files map[string]*followedFile
+}
+
+// followedFile is how far a followed file has been read.
+type followedFile struct {
+	// base is the offset entries of the current file start at. When the file
+	// is rotated or truncated it grows by what was read of the old one, so
+	// entry IDs stay unique.
+	base int64
+	// end is where the last line read ends in the current file.
+	end int64
+	// lines is the number of lines read, across files.
+	lines int
+}
+
+// SourceChangedMsg is sent when a followed file is deleted, or a new file
+// in a followed directory is attached.
+type SourceChangedMsg struct {
+	Path    string
+	Deleted bool
+}
+
+// FollowBatchMsg carries what the follower read since the last batch: the
+// NewLinesMsg and SourceChangedMsg values in the order they happened, the
+// number of lines dropped so far and the state of every followed file.
+type FollowBatchMsg struct {
+	Msgs    []tea.Msg
+	Dropped int
+	Sources []tail.SourceStatus
+
+	// from is the follower that read the batch; batches of a follower that
+	// was replaced are dropped.
+	from *follower
+}
+
+// newFollower starts following path from offset size, where lines lines
+// were read before.
+func newFollower(path string, size int64, lines int, opts tail.ManagerOptions, p *parser.Parser) (*follower, error) {
+	manager := tail.NewManager(opts)
+	if err := manager.AddFile(path, size); err != nil {
+		_ = manager.Stop()
+		return nil, err
+	}
+
+	f := startFollower(path, manager, p)
+	f.files[path] = &followedFile{end: size, lines: lines}
+	return f, nil
+}
+
+// newDirFollower starts following the files under dir matching glob, at
+// most depth directories down. Files in sizes continue from that offset,
+// after the number of lines in lines.
+func newDirFollower(dir, glob string, depth int, sizes map[string]int64, lines map[string]int, opts tail.ManagerOptions, p *parser.Parser) (*follower, error) {
+	manager := tail.NewManager(opts)
+	if err := manager.AddDir(dir, glob, depth, sizes); err != nil {
+		_ = manager.Stop()
+		return nil, err
+	}
+
+	f := startFollower(dir, manager, p)
+	for path, size := range sizes {
+		f.files[path] = &followedFile{end: size, lines: lines[path]}
+	}
+	return f, nil
+}
+
+func startFollower(path string, manager *tail.Manager, p *parser.Parser) *follower {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &follower{
+		path:    path,
+		parser:  p,
+		manager: manager,
+		ctx:     ctx,
+		cancel:  cancel,
+		files:   make(map[string]*followedFile),
+	}
+}
+
+// stop stops the watchers. A waitCmd still running returns nil.
+func (f *follower) stop() {
+	f.cancel()
+	_ = f.manager.Stop()
+}
+
+// pause stops reading the followed files until resume; what is appended
+// meanwhile is read then.
+func (f *follower) pause() {
+	f.manager.Pause()
+}
+
+// resume continues reading the followed files after pause.
+func (f *follower) resume() {
+	f.manager.Resume()
+}
+
+// waitCmd waits for the next batch the manager sends and returns it as a
+// FollowBatchMsg.
+func (f *follower) waitCmd() tea.Cmd {
+	f.waiting = true
+	return func() tea.Msg {
+		for {
+			select {
+			case <-f.ctx.Done():
+				return nil
+			case batch := <-f.manager.Batches():
+				msg := FollowBatchMsg{Dropped: batch.Dropped, from: f}
+				for _, event := range batch.Events {
+					if converted := f.convert(event); converted != nil {
+						msg.Msgs = append(msg.Msgs, converted)
+					}
+				}
+				msg.Sources = f.manager.Statuses()
+				return msg
+			}
+		}
+	}
+}
+
+// file returns the read state of a followed file, adding it if it is new.
+func (f *follower) file(path string) *followedFile {
+	file, ok := f.files[path]
+	if !ok {
+		file = &followedFile{}
+		f.files[path] = file
+	}
+	return file
+}
+
+// restart moves the read state of path to the start of a new file and
+// returns the message saying so.
+func (f *follower) restart(path string, truncated bool) NewLinesMsg {
+	file := f.file(path)
+	file.base += file.end
+	file.end = 0
+	return NewLinesMsg{Source: path, Rotated: !truncated, Truncated: truncated, Base: file.base}
+}
+
+// convert turns a manager event into a message: new lines into parsed
+// entries, rotation and truncation into a NewLinesMsg saying so, and files
+// coming and going into a SourceChangedMsg.
+func (f *follower) convert(event any) tea.Msg {
+	switch event := event.(type) {
+	case tail.NewLinesMsg:
+		file := f.file(event.Path)
+		msg := NewLinesMsg{Source: event.Path}
+		// Dropped lines still count, so line numbers match the file.
+		file.lines += event.Skipped
+		offset := event.Offset
+		for i, line := range event.Lines {
+			file.lines++
+			entry := f.parser.ParseLine(line, file.lines)
+			entry.Source = event.Path
+			entry.Offset = file.base + offset
+			msg.Entries = append(msg.Entries, entry)
+			offset += event.LineSize(i)
+		}
+		file.end = offset
+		return msg
+	case tail.FileRotatedMsg:
+		return f.restart(event.NewPath, false)
+	case tail.FileTruncatedMsg:
+		return f.restart(event.Path, true)
+	case tail.FileCreatedMsg:
+		// A file that comes back after being deleted was replaced.
+		if _, ok := f.files[event.Path]; ok {
+			return f.restart(event.Path, false)
+		}
+		f.file(event.Path)
+		return SourceChangedMsg{Path: event.Path}
+	case tail.FileDeletedMsg:
+		return SourceChangedMsg{Path: event.Path, Deleted: true}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/ui"
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// resolveGoto returns the row of the visible entries a ":" prompt input
+// jumps to. The input is a line number ("1234"), a percentage ("50%"), or a
+// time ("10:42:05" or "@2024-01-15T10:42"). Clock times are taken on the day
+// of the selected entry. Lines and times jump to the nearest visible entry.
+func (m Model) resolveGoto(input string) (int, error) {
+	input = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), ":"))
+	if input == "" {
+		return -1, fmt.Errorf("nothing to go to")
+	}
+	if len(m.filtered) == 0 {
+		return -1, fmt.Errorf("no entries")
+	}
+
+	if pct, ok := strings.CutSuffix(input, "%"); ok {
+		p, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || p < 0 || p > 100 {
+			return -1, fmt.Errorf("invalid percentage %q", input)
+		}
+		return int(p / 100 * float64(len(m.filtered)-1)), nil
+	}
+
+	if line, err := strconv.Atoi(input); err == nil {
+		return nearestLine(m.filtered, line), nil
+	}
+
+	ref := m.selectedEntry.Timestamp
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+	t, err := filter.ParseTime(strings.TrimPrefix(input, "@"), ref)
+	if err != nil {
+		return -1, err
+	}
+	row := nearestTime(m.filtered, t, m.timeSorted, m.sortOrder)
+	if row < 0 {
+		return -1, fmt.Errorf("no entries with timestamps")
+	}
+	return row, nil
+}
+
+// gotoPreview describes the target of a ":" prompt input for the prompt's
+// preview.
+func (m Model) gotoPreview(input string) ui.GotoPreview {
+	if strings.TrimSpace(input) == "" {
+		return ui.GotoPreview{Row: -1}
+	}
+	row, err := m.resolveGoto(input)
+	if err != nil {
+		return ui.GotoPreview{Row: -1, Err: err.Error()}
+	}
+	return ui.GotoPreview{Row: row, Total: len(m.filtered), Entry: m.filtered[row]}
+}
+
+// nearestLine returns the row of the entry whose line number is closest to line.
+func nearestLine(entries []logentry.Entry, line int) int {
+	best, bestDist := 0, -1
+	for i, entry := range entries {
+		dist := entry.Line - line
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+			if dist == 0 {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// nearestTime returns the row of the entry whose timestamp is closest to t,
+// or -1 if no entry has one. Entries sorted by time in order are binary
+// searched; otherwise every entry is compared.
+func nearestTime(entries []logentry.Entry, t time.Time, sorted bool, order SortOrder) int {
+	if !sorted {
+		best := -1
+		var bestDist time.Duration
+		for i, entry := range entries {
+			if entry.Timestamp.IsZero() {
+				continue
+			}
+			if dist := absDuration(entry.Timestamp.Sub(t)); best < 0 || dist < bestDist {
+				best, bestDist = i, dist
+			}
+		}
+		return best
+	}
+
+	i := sort.Search(len(entries), func(i int) bool {
+		if order == SortDesc {
+			return !entries[i].Timestamp.After(t)
+		}
+		return !entries[i].Timestamp.Before(t)
+	})
+
+	// The nearest entry is on either side of where t would go. Entries
+	// without a timestamp sort first when ascending and last when descending.
+	best := -1
+	var bestDist time.Duration
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(entries) || entries[j].Timestamp.IsZero() {
+			continue
+		}
+		if dist := absDuration(entries[j].Timestamp.Sub(t)); best < 0 || dist < bestDist {
+			best, bestDist = j, dist
+		}
+	}
+	return best
+}
+
+// inTimeOrder reports whether entries are sorted by timestamp in order, as
+// sortFiltered would leave them.
+func inTimeOrder(entries []logentry.Entry, order SortOrder) bool {
+	for i := 1; i < len(entries); i++ {
+		prev, cur := entries[i-1].Timestamp, entries[i].Timestamp
+		if order == SortAsc && cur.Before(prev) || order == SortDesc && cur.After(prev) {
+			return false
+		}
+	}
+	return true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
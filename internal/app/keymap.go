@@ -1,6 +1,9 @@
 package app
 
 import (
+	"fmt"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -13,6 +16,10 @@ type KeyMap struct {
 	ScrollDown      keyBinding
 	ScrollLeft      keyBinding
 	ScrollRight     keyBinding
+	ToggleWrap      keyBinding
+	ToggleColumns   keyBinding
+	SortColumn      keyBinding
+	PinColumn       keyBinding
 	ScrollPageUp    keyBinding
 	ScrollPageDown  keyBinding
 	ScrollToTop     keyBinding
@@ -24,8 +31,12 @@ type KeyMap struct {
 	ClearFilter     keyBinding
 	ToggleHelp      keyBinding
 	ToggleSidebar   keyBinding
+	SwitchPane      keyBinding
+	GrowPane        keyBinding
+	ShrinkPane      keyBinding
 	ToggleDashboard keyBinding
 	ToggleFollow    keyBinding
+	ToggleSources   keyBinding
 	LevelDebug      keyBinding
 	LevelInfo       keyBinding
 	LevelWarn       keyBinding
@@ -35,8 +46,29 @@ type KeyMap struct {
 	Expand          keyBinding
 	Collapse        keyBinding
 	Copy            keyBinding
+	CopyJSON        keyBinding
+	Visual          keyBinding
 	RefreshFile     keyBinding
 	ToggleSort      keyBinding
+	Presets         keyBinding
+	SavePreset      keyBinding
+	ExcludeLike     keyBinding
+	ToggleExcluded  keyBinding
+	Goto            keyBinding
+	Bookmark        keyBinding
+	SetMark         keyBinding
+	JumpMark        keyBinding
+	Bookmarks       keyBinding
+	Palette         keyBinding
+	ToggleContext   keyBinding
+	MoreContext     keyBinding
+	LessContext     keyBinding
+	SuspendFilter   keyBinding
+	Correlate       keyBinding
+	CallTree        keyBinding
+	ToggleMarkers   keyBinding
+	RelativeTime    keyBinding
+	PinReference    keyBinding
 }
 
 // keyBinding represents a single keyboard binding.
@@ -57,6 +89,10 @@ func DefaultKeyMap() KeyMap {
 		ScrollDown:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'j'}}, help: "Scroll Down", style: keyStyle},
 		ScrollLeft:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'h'}}, help: "Scroll Left", style: keyStyle},
 		ScrollRight:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'l'}}, help: "Scroll Right", style: keyStyle},
+		ToggleWrap:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'w'}}, help: "Toggle Line Wrap", style: keyStyle},
+		ToggleColumns:   keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'C'}}, help: "Toggle Columns View", style: keyStyle},
+		SortColumn:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'s'}}, help: "Sort by Next Column", style: keyStyle},
+		PinColumn:       keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'c'}}, help: "Pin Field as Column", style: keyStyle},
 		ScrollPageUp:    keyBinding{key: tea.Key{Type: tea.KeyPgUp}, help: "Page Up (PgUp)", style: keyStyle},
 		ScrollPageDown:  keyBinding{key: tea.Key{Type: tea.KeyPgDown}, help: "Page Down (PgDn)", style: keyStyle},
 		ScrollToTop:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'g'}}, help: "Go to Top", style: keyStyle},
@@ -67,9 +103,13 @@ func DefaultKeyMap() KeyMap {
 		Filter:          keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'f'}}, help: "Filter", style: keyStyle},
 		ClearFilter:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'F'}}, help: "Clear Filter", style: keyStyle},
 		ToggleHelp:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'?'}}, help: "Toggle Help", style: keyStyle},
-		ToggleSidebar:   keyBinding{key: tea.Key{Type: tea.KeyTab}, help: "Toggle Sidebar (Tab)", style: keyStyle},
+		ToggleSidebar:   keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'i'}}, help: "Toggle Field Inspector", style: keyStyle},
+		SwitchPane:      keyBinding{key: tea.Key{Type: tea.KeyTab}, help: "Switch Pane (Tab)", style: keyStyle},
+		GrowPane:        keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'>'}}, help: "Widen Inspector", style: keyStyle},
+		ShrinkPane:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'<'}}, help: "Narrow Inspector", style: keyStyle},
 		ToggleDashboard: keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'d'}}, help: "Toggle Dashboard", style: keyStyle},
 		ToggleFollow:    keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'F'}}, help: "Toggle Follow Mode", style: keyStyle},
+		ToggleSources:   keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'S'}}, help: "Followed Sources", style: keyStyle},
 		LevelDebug:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'1'}}, help: "Filter Debug", style: keyStyle},
 		LevelInfo:       keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'2'}}, help: "Filter Info", style: keyStyle},
 		LevelWarn:       keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'3'}}, help: "Filter Warn", style: keyStyle},
@@ -78,46 +118,50 @@ func DefaultKeyMap() KeyMap {
 		LevelNone:       keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'0'}}, help: "Clear Level Filter", style: keyStyle},
 		Expand:          keyBinding{key: tea.Key{Type: tea.KeyEnter}, help: "Expand Entry (Enter)", style: keyStyle},
 		Collapse:        keyBinding{key: tea.Key{Type: tea.KeyEsc}, help: "Collapse/Close (Esc)", style: keyStyle},
-		Copy:            keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'c'}}, help: "Copy Entry", style: keyStyle},
+		Copy:            keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'y'}}, help: "Copy Entry", style: keyStyle},
+		CopyJSON:        keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'Y'}}, help: "Copy Entry as JSON", style: keyStyle},
+		Visual:          keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'V'}}, help: "Select Range", style: keyStyle},
 		RefreshFile:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'R'}}, help: "Refresh File (Shift+R)", style: keyStyle},
 		ToggleSort:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'r'}}, help: "Toggle Sort", style: keyStyle},
+		Presets:         keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'p'}}, help: "Filter Presets", style: keyStyle},
+		SavePreset:      keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'P'}}, help: "Save Filter as Preset", style: keyStyle},
+		ExcludeLike:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'x'}}, help: "Exclude Entries Like This", style: keyStyle},
+		ToggleExcluded:  keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'X'}}, help: "Show/Hide Excluded Entries", style: keyStyle},
+		Goto:            keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{':'}}, help: "Go to Line/Time/Percent", style: keyStyle},
+		Bookmark:        keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'b'}}, help: "Toggle Bookmark", style: keyStyle},
+		SetMark:         keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'m'}}, help: "Set Mark (m{a-z})", style: keyStyle},
+		JumpMark:        keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'\''}}, help: "Jump to Mark ('{a-z}, '' next)", style: keyStyle},
+		Bookmarks:       keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'B'}}, help: "Bookmarks", style: keyStyle},
+		Palette:         keyBinding{key: tea.Key{Type: tea.KeyCtrlP}, help: "Command Palette (Ctrl+P)", style: keyStyle},
+		ToggleContext:   keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'a'}}, help: "Toggle Context Around Matches", style: keyStyle},
+		MoreContext:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'+'}}, help: "More Context", style: keyStyle},
+		LessContext:     keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'-'}}, help: "Less Context", style: keyStyle},
+		SuspendFilter:   keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'u'}}, help: "Suspend/Resume Filter", style: keyStyle},
+		Correlate:       keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'T'}}, help: "Entries Sharing Trace/Request ID", style: keyStyle},
+		CallTree:        keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'W'}}, help: "Span Call Tree", style: keyStyle},
+		ToggleMarkers:   keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'z'}}, help: "Toggle Gap/Burst Markers", style: keyStyle},
+		RelativeTime:    keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'e'}}, help: "Relative Time: Off/Previous/Pinned", style: keyStyle},
+		PinReference:    keyBinding{key: tea.Key{Type: tea.KeyRunes, Runes: []rune{'E'}}, help: "Pin Entry as Time Reference", style: keyStyle},
 	}
 }
 
 // ShortHelp returns a short help string for keybindings.
 func (k KeyMap) ShortHelp() string {
-	return "q: quit | ?: help | /: search | f: filter | d: dashboard | r: sort | R: refresh"
+	return "q: quit | ?: help | /: search | f: filter | p: presets | d: dashboard | r: sort | R: refresh"
 }
 
-// FullHelp returns a full help string for keybindings.
+// FullHelp returns a full help string for keybindings, listing the actions
+// that have keys.
 func (k KeyMap) FullHelp() string {
-	return `Navigation:
-  j/↓         Scroll down
-  k/↑         Scroll up
-  g            Go to top
-  G            Go to bottom
-  PgDn/Space  Page down
-  PgUp         Page up
-
-Search & Filter:
-  /            Search
-  n/N          Next/Prev search result
-  f            Filter
-  F            Clear filter
-
-Level Filter:
-  1-5          Filter by level (debug/error/etc)
-  0            Clear level filter
-
-View & Actions:
-  Enter        Expand/Collapse entry
-  Tab          Toggle sidebar
-  d            Toggle dashboard
-  Esc          Close overlay / exit mode
-
-File & Program:
-  r            Toggle Sort (asc/desc)
-  R            Refresh file
-  q            Quit
-  ?            Toggle this help`
+	var b strings.Builder
+	for i, section := range helpSections(k) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(section.Title + ":\n")
+		for _, binding := range section.Bindings {
+			fmt.Fprintf(&b, "  %-12s %s\n", binding.Key, binding.Description)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
 }
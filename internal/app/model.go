@@ -1,22 +1,39 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/ersanisk/sieve/internal/bookmark"
+	"github.com/ersanisk/sieve/internal/clipboard"
+	"github.com/ersanisk/sieve/internal/columns"
+	"github.com/ersanisk/sieve/internal/config"
 	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/history"
 	"github.com/ersanisk/sieve/internal/parser"
 	"github.com/ersanisk/sieve/internal/search"
+	"github.com/ersanisk/sieve/internal/tail"
 	"github.com/ersanisk/sieve/internal/theme"
+	"github.com/ersanisk/sieve/internal/trace"
 	"github.com/ersanisk/sieve/internal/ui"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
 
+// horizontalScrollStep is how many cells h and l scroll long lines by.
+const horizontalScrollStep = 10
+
+// statusBarHeight is how many rows the status bar takes below the panes.
+const statusBarHeight = 2
+
 type SortOrder int
 
 const (
@@ -31,10 +48,12 @@ type Model struct {
 	filterBar     ui.FilterBar
 	sidebar       ui.Sidebar
 	help          ui.Help
-	treeView      ui.TreeView
 	dashboard     ui.Dashboard
 	logDetail     *ui.LogDetail
 	filePicker    ui.FilePicker
+	presetPicker  ui.PresetPicker
+	sourcesPanel  ui.SourcesPanel
+	gotoPrompt    ui.GotoPrompt
 	keyMap        KeyMap
 	theme         theme.Theme
 	entries       []logentry.Entry
@@ -45,18 +64,104 @@ type Model struct {
 	loadingMsg    string
 	filePath      string
 	followMode    bool
-	levelFilter   logentry.Level
-	filter        *filter.CompiledFilter
-	filterExpr    string
+	// layout splits the screen between the log view and the sidebar
+	layout ui.Layout
+	// pipeline decides which entries end up in filtered
+	pipeline *filter.Pipeline
+	presets  *filter.PresetRegistry
 	// search state
 	searchQuery   string
 	searchResults []search.SearchResult
 	searchIndex   int
-	// follow state
-	followSize   int64
+	// searchEnd is how many entries the running search job covers
+	searchEnd int
+	// timeSorted is set while filtered is sorted by timestamp in sortOrder
+	timeSorted bool
+	// filteredPos maps entry identities to their index in filtered; it is
+	// built on demand and dropped whenever filtered is rebuilt
+	filteredPos map[logentry.ID]int
+	// background search and filter jobs still streaming results, if any
+	searchJob    *search.Job
+	searchCtx    context.Context
+	searchCancel context.CancelFunc
+	filterJob    *filter.Job
+	filterCtx    context.Context
+	filterCancel context.CancelFunc
+	// filterStale is set when a filter job was cancelled half way
+	filterStale bool
+	jobs        int
+	// index prefilters searches; it is filled in the background
+	index *search.Index
+	// follow state; follower is started the first time follow mode is on
+	follower     *follower
+	loadedSize   int64
 	followParser *parser.Parser
+	tailOptions  tail.ManagerOptions
+	// loadedFiles is set when a directory is followed, with the bytes read
+	// of each file; watchGlob and watchDepth select its files
+	loadedFiles map[string]int64
+	watchGlob   string
+	watchDepth  int
+	// newEntries counts entries that arrived while scrolled up
+	newEntries int
+	// markers are shown in the log view where the followed file was
+	// rotated or truncated
+	markers []ui.Marker
+	// bookmarks of every file; pendingKey is the first key of a two-key
+	// mark command while waiting for the second
+	bookmarks      *bookmark.Store
+	bookmarksPanel ui.BookmarksPanel
+	pendingKey     string
+	clipboard      *clipboard.Clipboard
+	// columns view: the columns shown for the open file, the configured
+	// ones used when it has no saved layout, and the column entries are
+	// sorted by
+	columns        []string
+	defaultColumns []string
+	showColumns    bool
+	sortColumn     string
+	layouts        *columns.Store
+	// mouse state: the last click, to tell double clicks, and whether a
+	// pane border is being dragged
+	lastClick      time.Time
+	lastClickIndex int
+	dragging       bool
+	// palette runs actions, presets, themes, files and recent filters by
+	// name
+	palette ui.Palette
+	// context view: how many entries are shown around each filter match
+	// and whether they are; contextIDs are the entries in filtered added
+	// as context and contextBreaks where their groups start
+	contextLines  int
+	showContext   bool
+	contextIDs    map[logentry.ID]bool
+	contextBreaks map[logentry.ID]int
+	// keepSelected is the entry to select again once a refilter is done
+	keepSelected *logentry.ID
+	// correlation: the fields entries are pivoted on, the pivot shown and
+	// the spans of its call tree
+	correlationKeys []string
+	pivot           *pivot
+	traceSpans      []trace.Span
+	tracePanel      ui.TracePanel
+	// time markers: how long a gap and how many entries a second are
+	// marked, and whether they are; relativeTime is what the relative time
+	// column measures from and pinned the entry it measures from when one
+	// is pinned
+	gapThreshold   time.Duration
+	burstThreshold int
+	showMarkers    bool
+	relativeTime   relativeMode
+	pinned         *logentry.Entry
 	// sort state
 	sortOrder SortOrder
+	// configPath is where saved presets are written.
+	configPath string
+	// submitted search and filter expressions, persisted across sessions
+	searchHistory *history.History
+	filterHistory *history.History
+	// fields and values seen in entries, for filter completion
+	fieldStats *filter.FieldStats
 }
 
 func NewModel(filePath string, themeName string, followMode bool) Model {
@@ -69,11 +174,14 @@ func NewModel(filePath string, themeName string, followMode bool) Model {
 		filterBar:    ui.NewFilterBar(theme),
 		sidebar:      ui.NewSidebar(theme),
 		help:         ui.NewHelp(theme),
-		treeView:     ui.NewTreeView(theme),
 		dashboard:    ui.NewDashboard(theme),
 		logDetail:    ui.NewLogDetail(theme),
 		filePicker:   ui.NewFilePicker(theme),
+		presetPicker: ui.NewPresetPicker(theme),
+		sourcesPanel: ui.NewSourcesPanel(theme),
+		gotoPrompt:   ui.NewGotoPrompt(theme),
 		keyMap:       DefaultKeyMap(),
+		layout:       ui.NewLayout(0, 0),
 		theme:        theme,
 		entries:      []logentry.Entry{},
 		filtered:     []logentry.Entry{},
@@ -81,14 +189,139 @@ func NewModel(filePath string, themeName string, followMode bool) Model {
 		loading:      false,
 		filePath:     filePath,
 		followMode:   followMode,
-		levelFilter:  logentry.Unknown,
+		pipeline:     filter.NewPipeline(),
+		presets:      filter.NewPresetRegistry(),
+		index:        search.NewIndex(),
 		followParser: parser.NewParser(),
 		sortOrder:    SortAsc,
+		bookmarks:    bookmark.New(""),
+		layouts:      columns.NewStore(""),
 	}
+	m.bookmarksPanel = ui.NewBookmarksPanel(theme)
+	m.palette = ui.NewPalette(theme)
+	m.contextLines = config.DefaultContextLines
+	m.correlationKeys = trace.DefaultKeys
+	m.tracePanel = ui.NewTracePanel(theme)
+	m.gapThreshold = config.DefaultMarkerGap
+	m.burstThreshold = config.DefaultMarkerBurst
+	m.showMarkers = true
+	m.syncTimeMarkers()
+	m.help.SetSections(helpSections(m.keyMap))
+	m.clipboard = clipboard.Default()
 	m.statusBar.SetFollowing(followMode)
 	return m
 }
 
+// SetConfigPath sets the config file that saved presets are written to.
+func (m *Model) SetConfigPath(path string) {
+	m.configPath = path
+}
+
+// SetWatchOptions sets which files are followed when the path is a
+// directory: those matching glob, at most depth directories down.
+func (m *Model) SetWatchOptions(glob string, depth int) {
+	m.watchGlob = glob
+	m.watchDepth = depth
+}
+
+// SetTailOptions sets how followed files are batched: how often new lines
+// are shown and how many may wait before the oldest are dropped.
+func (m *Model) SetTailOptions(opts tail.ManagerOptions) {
+	m.tailOptions = opts
+}
+
+// SetLayout sets the share of the screen the field inspector gets and the
+// terminal width below which it is shown below the log view.
+func (m *Model) SetLayout(ratio float64, stackWidth int) {
+	m.layout = ui.NewLayout(ratio, stackWidth)
+}
+
+// SetWrapLines sets whether long messages wrap instead of being cut at the
+// edge of the screen.
+func (m *Model) SetWrapLines(wrap bool) {
+	m.logView.SetWrap(wrap)
+}
+
+// SetContext sets how many entries the context view shows around each
+// filter match, and whether it is on.
+func (m *Model) SetContext(lines int, show bool) {
+	m.contextLines = max(0, min(lines, maxContextLines))
+	m.showContext = show
+}
+
+// SetCorrelationKeys sets the fields entries are pivoted on, in order of
+// preference. No keys leaves the defaults.
+func (m *Model) SetCorrelationKeys(keys []string) {
+	if len(keys) > 0 {
+		m.correlationKeys = keys
+	}
+}
+
+// SetTimeMarkers sets how long the log must go quiet for a gap to be marked
+// and how many entries logged within one second are marked as a burst. Zero
+// turns either off.
+func (m *Model) SetTimeMarkers(gap time.Duration, burst int) {
+	m.gapThreshold = max(gap, 0)
+	m.burstThreshold = max(burst, 0)
+	m.syncTimeMarkers()
+}
+
+// SetColumns sets the columns shown for files without a saved layout.
+func (m *Model) SetColumns(columns []string) {
+	m.defaultColumns = columns
+	m.restoreColumns()
+}
+
+// SetLayouts sets the store column layouts are saved in.
+func (m *Model) SetLayouts(store *columns.Store) {
+	m.layouts = store
+	m.restoreColumns()
+}
+
+// SetFileColumns sets the columns of the open file, replacing its saved
+// layout.
+func (m *Model) SetFileColumns(cols []string) {
+	m.layouts.Set(m.fileKey(), columns.Layout{Columns: cols})
+	m.restoreColumns()
+}
+
+// SetBookmarks sets the store bookmarks are kept in.
+func (m *Model) SetBookmarks(store *bookmark.Store) {
+	m.bookmarks = store
+}
+
+// SetClipboard sets the clipboard entries are copied to.
+func (m *Model) SetClipboard(cb *clipboard.Clipboard) {
+	m.clipboard = cb
+}
+
+// SetHistory sets the persistent search and filter histories.
+func (m *Model) SetHistory(searchHistory, filterHistory *history.History) {
+	m.searchHistory = searchHistory
+	m.filterHistory = filterHistory
+	if searchHistory != nil {
+		m.searchBar.SetHistory(searchHistory.Entries())
+	}
+	if filterHistory != nil {
+		m.filterBar.SetHistory(filterHistory.Entries())
+	}
+}
+
+// SetPresets sets the filter presets that can be applied.
+func (m *Model) SetPresets(presets *filter.PresetRegistry) {
+	m.presets = presets
+}
+
+// SetPreset activates a filter preset before any file is loaded.
+// The preset is applied once entries arrive.
+func (m *Model) SetPreset(name string) error {
+	preset, ok := m.presets.Get(name)
+	if !ok {
+		return fmt.Errorf("preset not found: %s", name)
+	}
+	return m.setPresetState(preset)
+}
+
 func getTheme(name string) theme.Theme {
 	if t := theme.Get(name); t != nil {
 		return t
@@ -113,6 +346,10 @@ func (m Model) Init() tea.Cmd {
 
 	// Check if the provided path is a directory
 	info, err := os.Stat(m.filePath)
+	if err == nil && info.IsDir() && m.followMode {
+		// Following a directory follows every log file in it
+		return tea.Batch(tickCmd(), loadDirCmd(m.filePath, m.watchGlob, m.watchDepth))
+	}
 	if err == nil && info.IsDir() {
 		// It's a directory - show file picker
 		return tea.Batch(
@@ -124,10 +361,19 @@ func (m Model) Init() tea.Cmd {
 	}
 
 	// It's a file - load it directly
-	return tea.Batch(tickCmd(), loadFileCmd(m.filePath))
+	return tea.Batch(tickCmd(), loadFileCmd(m.filePath, m.followMode))
 }
 
+// Update handles msg, then fits the panes to the rows the search and filter
+// bars leave them, which change as the bars open and close and show
+// completions or errors.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	m.resizePanes()
+	return m, cmd
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
@@ -139,6 +385,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.filePicker = *updatedPicker
 			return m, cmd
 		}
+		if m.presetPicker.IsVisible() {
+			m.presetPicker, cmd = m.presetPicker.Update(msg)
+			if !m.presetPicker.IsVisible() {
+				m.mode = "view"
+			}
+			return m, tea.Batch(cmd, tickCmd())
+		}
+		if m.sourcesPanel.IsVisible() {
+			m.sourcesPanel, cmd = m.sourcesPanel.Update(msg)
+			return m, tea.Batch(cmd, tickCmd())
+		}
+		if m.bookmarksPanel.IsVisible() {
+			m.bookmarksPanel, cmd = m.bookmarksPanel.Update(msg)
+			return m, tea.Batch(cmd, tickCmd())
+		}
+		if m.tracePanel.IsVisible() {
+			m.tracePanel, cmd = m.tracePanel.Update(msg)
+			return m, tea.Batch(cmd, tickCmd())
+		}
+		if m.gotoPrompt.IsVisible() {
+			m.gotoPrompt, cmd = m.gotoPrompt.Update(msg)
+			value := m.gotoPrompt.Value()
+			if value != "" && unicode.IsLetter([]rune(value)[0]) {
+				// No goto target starts with a letter: ":wrap" is a command.
+				m.gotoPrompt.Hide()
+				m.showPalette()
+				m.palette.SetValue(value)
+				return m, tea.Batch(cmd, tickCmd())
+			}
+			m.gotoPrompt.SetPreview(m.gotoPreview(value))
+			if !m.gotoPrompt.IsVisible() {
+				m.mode = "view"
+			}
+			return m, tea.Batch(cmd, tickCmd())
+		}
+		if m.palette.IsVisible() {
+			m.palette, cmd = m.palette.Update(msg)
+			if target, ok := strings.CutPrefix(m.palette.Value(), ":"); ok {
+				m.palette.Hide()
+				m.gotoPrompt.Show()
+				m.gotoPrompt.SetValue(target)
+				m.gotoPrompt.SetPreview(m.gotoPreview(target))
+				m.mode = "goto"
+				return m, tea.Batch(cmd, tickCmd())
+			}
+			if !m.palette.IsVisible() {
+				m.mode = "view"
+			}
+			return m, tea.Batch(cmd, tickCmd())
+		}
 		if m.filterBar.IsFocused() && msg.Type == tea.KeyEnter {
 			m.filterBar.Hide()
 			m.mode = "view"
@@ -149,6 +445,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if (m.filterBar.IsFocused() || m.filterBar.IsVisible()) && msg.Type == tea.KeyEsc {
 			m.filterBar.Hide()
 			m.mode = "view"
+			if m.filterStale {
+				// A keystroke cancelled the filter half way; finish it.
+				return m, tea.Batch(tickCmd(), m.refilter())
+			}
 			return m, tickCmd()
 		}
 		if m.searchBar.IsFocused() && msg.Type == tea.KeyEnter {
@@ -164,6 +464,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tickCmd()
 		}
 		if m.searchBar.IsFocused() {
+			// A running search goes on while typing; submitting the new
+			// query replaces it.
 			var cmd tea.Cmd
 			m.searchBar, cmd = m.searchBar.Update(msg)
 			return m, tea.Batch(cmd, tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
@@ -171,6 +473,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}))
 		}
 		if m.filterBar.IsFocused() {
+			m.cancelFilter()
 			var cmd tea.Cmd
 			m.filterBar, cmd = m.filterBar.Update(msg)
 			return m, tea.Batch(cmd, tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
@@ -179,7 +482,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m.handleKey(msg)
 	case tea.MouseMsg:
-		return m, nil
+		return m.handleMouse(msg)
 	case tea.WindowSizeMsg:
 		m.handleResize(msg)
 		return m, tickCmd()
@@ -201,21 +504,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case ui.FileLoadedMsg:
 		m.entries = msg.Entries
-		m.filtered = msg.Entries
-		m.logView.SetEntries(msg.Entries)
+		m.unpin()
+		m.restoreColumns()
+		// The index is replaced first: refiltering restarts an active
+		// search, which must not use the previous file's index.
+		m.index = search.NewIndex()
+		cmd = m.refilter()
+		m.fieldStats = filter.NewFieldStats(msg.Entries)
+		m.filterBar.SetFieldStats(m.fieldStats)
+		m.searchBar.SetFieldStats(m.fieldStats)
 		m.statusBar.SetFilePath(msg.Path)
-		m.statusBar.SetTotalLines(len(msg.Entries))
 		m.loading = false
-		// follow için mevcut dosya boyutunu kaydet
-		if info, err := os.Stat(msg.Path); err == nil {
-			m.followSize = info.Size()
+		// follow, yüklenen kısmın sonundan devam eder
+		if m.follower != nil {
+			m.follower.stop()
+			m.follower = nil
 		}
-		return m, tickCmd()
+		m.loadedSize = msg.Size
+		m.loadedFiles = msg.Files
+		m.logView.SetSourceLabels(m.followedSources())
+		m.newEntries = 0
+		m.statusBar.SetNewEntries(0)
+		m.markers = nil
+		m.logView.SetMarkers(nil)
+		m.syncBookmarks()
+		return m, tea.Batch(tickCmd(), cmd, indexEntriesCmd(m.index, 0, msg.Entries), m.followCmd())
 	case ui.SearchInputMsg:
 		m.searchBar.SetValue(msg.Query)
 		return m, tickCmd()
 	case ui.SearchSubmitMsg:
-		return m.applySearch(m.searchBar.GetValue())
+		query := m.searchBar.GetValue()
+		m, cmd = m.applySearch(query)
+		if query != "" && m.searchHistory != nil {
+			m.searchHistory.Add(query)
+			m.searchBar.SetHistory(m.searchHistory.Entries())
+			cmd = tea.Batch(cmd, saveBestEffortCmd(m.searchHistory.Save))
+		}
+		return m, cmd
+	case SearchResultsMsg:
+		return m.addSearchResults(msg)
+	case FilterResultsMsg:
+		return m.addFilterResults(msg)
 	case ui.SearchNextMsg:
 		return m.searchNext()
 	case ui.SearchPrevMsg:
@@ -224,20 +553,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.filterBar.SetValue(msg.Expression)
 		return m, tickCmd()
 	case ui.FilterSubmitMsg:
-		return m.applyFilter(m.filterBar.GetValue())
+		expr := m.filterBar.GetValue()
+		m, cmd = m.applyFilter(expr)
+		if expr != "" && m.pipeline.Expression() == expr && m.filterHistory != nil {
+			m.filterHistory.Add(expr)
+			m.filterBar.SetHistory(m.filterHistory.Entries())
+			cmd = tea.Batch(cmd, saveBestEffortCmd(m.filterHistory.Save))
+		}
+		return m, cmd
+	case ui.PaletteRunMsg:
+		return m.runPaletteItem(msg.ID)
+	case ui.ShowPresetPickerMsg:
+		m.showPresetPicker()
+		return m, tickCmd()
+	case ui.FilterSetPresetMsg:
+		return m.applyPreset(msg.Preset)
+	case ui.PresetSaveMsg:
+		return m.saveCurrentAsPreset(msg.Name)
+	case ui.PresetSavedMsg:
+		if msg.Err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Saving preset failed: %v", msg.Err))
+		} else {
+			m.statusBar.SetInfo(fmt.Sprintf("Saved preset %q", msg.Name))
+		}
+		return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
 	case ui.SetLevelFilterMsg:
-		m.levelFilter = msg.Level
-		return m.applyLevelFilter()
+		return m.applyLevel(msg.Level)
 	case ui.ToggleHelpMsg:
 		m.help.Show()
 		m.mode = "help"
 		return m, tickCmd()
 	case ui.ToggleSidebarMsg:
-		if m.sidebar.IsVisible() {
-			m.sidebar.Hide()
-		} else {
-			m.sidebar.Show()
-		}
+		m.toggleSidebar()
 		return m, tickCmd()
 	case ui.ToggleDashboardMsg:
 		if m.dashboard.IsVisible() {
@@ -247,9 +594,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tickCmd()
 	case ui.ToggleFollowMsg:
-		m.followMode = !m.followMode
-		m.statusBar.SetFollowing(m.followMode)
-		return m, tickCmd()
+		return m, tea.Batch(tickCmd(), m.toggleFollow())
 	case ui.ScrollUpMsg:
 		if m.help.IsVisible() {
 			m.help, _ = m.help.Update(msg)
@@ -266,6 +611,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateSelectedEntry()
 		}
 		return m, tickCmd()
+	case ui.GotoSubmitMsg:
+		row, err := m.resolveGoto(msg.Input)
+		if err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Go to %s: %v", msg.Input, err))
+			return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+		}
+		return m, func() tea.Msg { return ui.ScrollToLineMsg{Line: row} }
+	case ui.ScrollToLineMsg:
+		m.logView.ScrollToLine(msg.Line)
+		m.updateSelectedEntry()
+		return m, tickCmd()
 	case ui.ScrollToTopMsg:
 		m.logView.ScrollToTop()
 		m.updateSelectedEntry()
@@ -275,25 +631,46 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateSelectedEntry()
 		return m, tickCmd()
 	case ui.TickMsg:
-		if m.followMode && m.filePath != "" {
-			return m, tea.Batch(tickCmd(), followCmd(m.filePath, m.followSize, m.followParser))
-		}
 		return m, tickCmd()
+	case FollowBatchMsg:
+		if msg.from != m.follower {
+			return m, nil
+		}
+		m.follower.waiting = false
+		return m, tea.Batch(m.addFollowBatch(msg), m.followCmd())
+	case SourceChangedMsg:
+		return m, m.sourceChanged(msg)
 	case NewLinesMsg:
-		if len(msg.Entries) > 0 {
-			// followSize'ı güncelle
-			if info, err := os.Stat(m.filePath); err == nil {
-				m.followSize = info.Size()
-			}
-			m.entries = append(m.entries, msg.Entries...)
-			m.filtered = append(m.filtered, msg.Entries...)
-			m.logView.SetEntries(m.filtered)
-			m.statusBar.SetTotalLines(len(m.filtered))
-			// otomatik en alta kaydır
-			m.logView.ScrollToBottom()
-			m.updateSelectedEntry()
+		if msg.Rotated || msg.Truncated {
+			cmd = m.addFollowMarker(msg)
 		}
-		return m, tickCmd()
+		return m, tea.Batch(cmd, m.appendEntries(msg.Entries))
+	case ui.CopyEntryMsg:
+		return m, tea.Batch(tickCmd(), copyCmd(m.clipboard, entryText(msg.Entry), "entry"))
+	case ui.CopiedMsg:
+		switch {
+		case msg.Err != nil:
+			m.statusBar.SetError(fmt.Sprintf("Copy failed: %v", msg.Err))
+		case msg.Method == string(clipboard.OSC52):
+			m.statusBar.SetInfo(fmt.Sprintf("Copied %s via OSC52", msg.What))
+		default:
+			m.statusBar.SetInfo(fmt.Sprintf("Copied %s to clipboard", msg.What))
+		}
+		return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+	case ui.BookmarkMsg:
+		m.logView.SetSelected(msg.Index)
+		m.updateSelectedEntry()
+		return m, tea.Batch(tickCmd(), m.setBookmark(""))
+	case ui.GoToBookmarkMsg:
+		bookmarks := m.bookmarks.List(m.fileKey())
+		if msg.Index < 0 || msg.Index >= len(bookmarks) {
+			return m, tickCmd()
+		}
+		return m, tea.Batch(tickCmd(), m.jumpToBookmark(bookmarks[msg.Index]))
+	case ui.GoToSpanMsg:
+		return m, tea.Batch(tickCmd(), m.jumpToSpan(msg.Index))
+	case ui.DeleteBookmarkMsg:
+		return m, tea.Batch(tickCmd(), m.deleteBookmark(msg.Index))
 	case ui.ClearInfoMsg:
 		m.statusBar.SetInfo("")
 		return m, tickCmd()
@@ -309,7 +686,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = "view"
 		m.filePath = msg.Path
 		m.loading = true
-		return m, tea.Batch(tickCmd(), loadFileCmd(msg.Path))
+		return m, tea.Batch(tickCmd(), loadFileCmd(msg.Path, m.followMode))
 	}
 
 	return m, cmd
@@ -320,6 +697,30 @@ func (m Model) View() string {
 		return (&m.filePicker).View()
 	}
 
+	if m.presetPicker.IsVisible() {
+		return (&m.presetPicker).View()
+	}
+
+	if m.sourcesPanel.IsVisible() {
+		return (&m.sourcesPanel).View()
+	}
+
+	if m.bookmarksPanel.IsVisible() {
+		return (&m.bookmarksPanel).View()
+	}
+
+	if m.tracePanel.IsVisible() {
+		return (&m.tracePanel).View()
+	}
+
+	if m.gotoPrompt.IsVisible() {
+		return (&m.gotoPrompt).View()
+	}
+
+	if m.palette.IsVisible() {
+		return (&m.palette).View()
+	}
+
 	if m.loading {
 		return m.renderLoading()
 	}
@@ -397,174 +798,90 @@ func (m Model) handleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, tickCmd()
 	}
 
-	// Esc: search modundan çık
-	if msg.Type == tea.KeyEsc && m.searchQuery != "" {
-		m.searchQuery = ""
-		m.searchResults = nil
-		m.searchIndex = 0
-		m.logView.SetSearchQuery("")
-		m.statusBar.SetInfo("")
-		return m, tickCmd()
+	// The focused inspector takes the navigation keys; Esc and Tab give
+	// focus back to the log view.
+	if m.sidebar.IsFocused() {
+		switch msg.String() {
+		case "esc", m.keyMap.SwitchPane.key.String():
+			m.sidebar.Blur()
+			return m, tickCmd()
+		case "up", "down", "k", "j", "g", "G", "home", "end", "enter", " ":
+			m.sidebar.Update(msg)
+			return m, tickCmd()
+		case m.keyMap.Copy.key.String():
+			return m, tea.Batch(tickCmd(), m.copyField(false))
+		case m.keyMap.CopyJSON.key.String():
+			return m, tea.Batch(tickCmd(), m.copyField(true))
+		case m.keyMap.PinColumn.key.String():
+			return m, tea.Batch(tickCmd(), m.pinColumn())
+		}
 	}
 
-	// Esc: filter modundan çık ve filtreyi temizle
-	if msg.Type == tea.KeyEsc && (m.filter != nil || m.levelFilter != logentry.Unknown) {
-		m.filter = nil
-		m.filterExpr = ""
-		m.levelFilter = logentry.Unknown
-		m.filtered = m.entries
-		m.logView.SetEntries(m.filtered)
-		m.statusBar.SetTotalLines(len(m.filtered))
-		m.statusBar.SetInfo("Filter cleared")
-		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	if m.pendingKey != "" {
+		prefix := m.pendingKey
+		m.pendingKey = ""
+		return m.handleMarkKey(prefix, msg.String())
 	}
 
-	switch msg.Type {
-	case tea.KeyUp:
-		m.logView.ScrollUpOne()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case tea.KeyDown:
-		m.logView.ScrollDownOne()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case tea.KeyPgUp:
-		m.logView.ScrollPageUp()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case tea.KeyPgDown:
-		m.logView.ScrollPageDown()
-		m.updateSelectedEntry()
+	if _, _, ok := m.logView.VisualRange(); ok && msg.Type == tea.KeyEsc {
+		m.stopVisual()
 		return m, tickCmd()
 	}
 
-	switch msg.String() {
-	case m.keyMap.Quit.key.String():
-		return m, tea.Quit
-	case m.keyMap.ForceQuit.key.String():
-		return m, tea.Quit
-	case m.keyMap.ScrollUp.key.String():
-		m.logView.ScrollUpOne()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case m.keyMap.ScrollDown.key.String():
-		m.logView.ScrollDownOne()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case m.keyMap.ScrollToTop.key.String():
-		m.logView.ScrollToTop()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case m.keyMap.ScrollToBottom.key.String():
-		m.logView.ScrollToBottom()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case m.keyMap.ScrollPageUp.key.String():
-		m.logView.ScrollPageUp()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case m.keyMap.ScrollPageDown.key.String():
-		m.logView.ScrollPageDown()
-		m.updateSelectedEntry()
-		return m, tickCmd()
-	case m.keyMap.Search.key.String():
-		m.searchBar.Show()
-		m.mode = "search"
-		return m, tickCmd()
-	case m.keyMap.SearchNext.key.String():
-		return m.searchNext()
-	case m.keyMap.SearchPrev.key.String():
-		return m.searchPrev()
-	case m.keyMap.Filter.key.String():
-		m.filterBar.Show()
-		m.mode = "filter"
-		return m, tickCmd()
-	case m.keyMap.ToggleHelp.key.String():
-		m.help.Show()
-		m.mode = "help"
-		return m, tickCmd()
-	case m.keyMap.ToggleSidebar.key.String():
-		if m.sidebar.IsVisible() {
-			m.sidebar.Hide()
-		} else {
-			m.sidebar.Show()
-		}
-		return m, tickCmd()
-	case m.keyMap.ToggleDashboard.key.String():
-		if m.dashboard.IsVisible() {
-			m.dashboard.Hide()
-		} else {
-			m.dashboard.Show()
-		}
-		return m, tickCmd()
-	case m.keyMap.ToggleFollow.key.String():
-		m.followMode = !m.followMode
-		m.statusBar.SetFollowing(m.followMode)
-		return m, tickCmd()
-	case m.keyMap.LevelDebug.key.String():
-		if m.levelFilter == logentry.Debug {
-			m.levelFilter = logentry.Unknown
-		} else {
-			m.levelFilter = logentry.Debug
-		}
-		return m.applyLevelFilter()
-	case m.keyMap.LevelInfo.key.String():
-		if m.levelFilter == logentry.Info {
-			m.levelFilter = logentry.Unknown
-		} else {
-			m.levelFilter = logentry.Info
-		}
-		return m.applyLevelFilter()
-	case m.keyMap.LevelWarn.key.String():
-		if m.levelFilter == logentry.Warn {
-			m.levelFilter = logentry.Unknown
-		} else {
-			m.levelFilter = logentry.Warn
-		}
-		return m.applyLevelFilter()
-	case m.keyMap.LevelError.key.String():
-		if m.levelFilter == logentry.Error {
-			m.levelFilter = logentry.Unknown
-		} else {
-			m.levelFilter = logentry.Error
-		}
-		return m.applyLevelFilter()
-	case m.keyMap.LevelFatal.key.String():
-		if m.levelFilter == logentry.Fatal {
-			m.levelFilter = logentry.Unknown
-		} else {
-			m.levelFilter = logentry.Fatal
-		}
-		return m.applyLevelFilter()
-	case m.keyMap.LevelNone.key.String():
-		m.levelFilter = logentry.Unknown
-		return m.applyLevelFilter()
-	case m.keyMap.RefreshFile.key.String():
-		return m, tea.Batch(tickCmd(), loadFileCmd(m.filePath), tickCmd())
-	case m.keyMap.ToggleSort.key.String():
-		m.toggleSort()
-		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
-	case m.keyMap.Expand.key.String():
-		m.logDetail.Show(m.selectedEntry)
+	// Esc: search modundan çık
+	if msg.Type == tea.KeyEsc && m.searchQuery != "" {
+		m.clearSearch()
 		return m, tickCmd()
 	}
 
+	// Esc: filter modundan çık ve filtreyi temizle
+	if msg.Type == tea.KeyEsc && m.pipeline.IsFiltering() {
+		return m, tea.Batch(tickCmd(), m.clearFilter())
+	}
+
+	if a, ok := m.actionFor(msg.String()); ok {
+		return a.run(m)
+	}
 	return m, tickCmd()
 }
 
+// clearSearch drops the search query and its results.
+func (m *Model) clearSearch() {
+	m.searchQuery = ""
+	m.searchResults = nil
+	m.searchIndex = 0
+	m.cancelSearch()
+	m.logView.SetSearchQuery(search.Query{})
+	m.statusBar.SetInfo("")
+}
+
+// clearFilter drops the filter expression and shows every entry again.
+func (m *Model) clearFilter() tea.Cmd {
+	m.pipeline.Clear()
+	m.filterBar.SetValue("")
+	cmd := m.refilter()
+	m.statusBar.SetInfo("Filter cleared")
+	return tea.Batch(cmd, clearInfoCmd(2*time.Second))
+}
+
 func (m *Model) handleResize(msg tea.WindowSizeMsg) {
 	width, height := msg.Width, msg.Height
 
-	m.logView.SetSize(width, height-2)
-	m.statusBar.SetSize(width, 2)
+	m.layout.SetSize(width, height-statusBarHeight)
+	m.resizePanes()
+	m.statusBar.SetSize(width, statusBarHeight)
 	m.searchBar.SetSize(width, 3)
 	m.filterBar.SetSize(width, 3)
 	m.help.SetSize(width, height)
 	m.dashboard.SetSize(width/2, height/2)
-	m.sidebar.SetSize(width/3, height-2)
-	m.treeView.SetSize(width/3, height-2)
 	m.logDetail.SetSize(width, height)
 	m.filePicker.SetSize(width, height)
+	m.presetPicker.SetSize(width, height)
+	m.sourcesPanel.SetSize(width, height)
+	m.bookmarksPanel.SetSize(width, height)
+	m.tracePanel.SetSize(width, height)
+	m.gotoPrompt.SetSize(width, height)
+	m.palette.SetSize(width, height)
 
 	m.statusBar.SetFilePath(m.filePath)
 	m.statusBar.SetTotalLines(len(m.filtered))
@@ -577,17 +894,21 @@ func (m *Model) updateSelectedEntry() {
 		m.sidebar.SetEntry(entry)
 		m.statusBar.SetSelected(index)
 	}
+	// Reaching the newest entry, e.g. with G, resumes auto-scroll.
+	if m.newEntries > 0 && m.atNewest(index) {
+		m.newEntries = 0
+		m.statusBar.SetNewEntries(0)
+	}
 }
 
 func (m Model) renderMain() string {
-	width, height := m.logView.GetSize()
+	width, height := m.layout.Size()
 
 	if width == 0 || height == 0 {
 		return ""
 	}
 
-	m.logView.SetSize(width, height-2)
-	m.statusBar.SetSize(width, 2)
+	m.statusBar.SetSize(width, statusBarHeight)
 
 	var result string
 
@@ -599,13 +920,73 @@ func (m Model) renderMain() string {
 		result += m.filterBar.View() + "\n"
 	}
 
-	result += m.logView.View()
+	result += m.renderPanes()
 	result += "\n"
 	result += m.statusBar.View()
 
 	return result
 }
 
+// renderPanes renders the log view, and the sidebar next to or below it
+// when it is shown.
+func (m Model) renderPanes() string {
+	layout := m.paneLayout()
+	if !m.sidebar.IsVisible() {
+		m.logView.SetSize(layout.Size())
+		// renderMain puts the status bar on the next line.
+		return strings.TrimSuffix(m.logView.View(), "\n")
+	}
+
+	mainWidth, mainHeight, sideWidth, sideHeight := layout.Split()
+	m.logView.SetSize(mainWidth, mainHeight)
+	m.sidebar.SetSize(sideWidth, sideHeight)
+	return layout.Join(m.logView.View(), m.sidebar.View())
+}
+
+// paneLayout returns the layout the panes are sized and rendered with: the
+// screen between the search and filter bars and the status bar.
+func (m Model) paneLayout() ui.Layout {
+	layout := m.layout
+	width, height := layout.Size()
+	layout.SetSize(width, max(height-m.barsHeight(), 1))
+	return layout
+}
+
+// resizePanes sizes the log view and the sidebar to the layout.
+func (m *Model) resizePanes() {
+	layout := m.paneLayout()
+	if !m.sidebar.IsVisible() {
+		m.logView.SetSize(layout.Size())
+		return
+	}
+	mainWidth, mainHeight, sideWidth, sideHeight := layout.Split()
+	m.logView.SetSize(mainWidth, mainHeight)
+	m.sidebar.SetSize(sideWidth, sideHeight)
+}
+
+// toggleSidebar shows or hides the field inspector.
+func (m *Model) toggleSidebar() {
+	if m.sidebar.IsVisible() {
+		m.sidebar.Hide()
+	} else {
+		m.sidebar.Show()
+	}
+	m.resizePanes()
+}
+
+// resizeSidebar gives the field inspector steps more of the screen, or
+// less, and remembers the new share in the config file.
+func (m *Model) resizeSidebar(steps int) tea.Cmd {
+	if !m.sidebar.IsVisible() || !m.layout.Grow(steps) {
+		return nil
+	}
+	m.resizePanes()
+	if m.configPath == "" {
+		return nil
+	}
+	return saveLayoutCmd(m.configPath, m.layout.Ratio())
+}
+
 func (m Model) renderLoading() string {
 	style := lipgloss.NewStyle().
 		Foreground(m.theme.Colors().Foreground).
@@ -618,90 +999,305 @@ func (m Model) renderLoading() string {
 	return style.Render("Loading...")
 }
 
-func (m Model) applyLevelFilter() (Model, tea.Cmd) {
-	if m.levelFilter == logentry.Unknown {
-		// No level filter — restore from expression filter or all entries
-		if m.filter != nil {
-			var filtered []logentry.Entry
-			for _, entry := range m.entries {
-				matches, err := m.filter.Evaluate(entry)
-				if err == nil && matches {
-					filtered = append(filtered, entry)
-				}
-			}
-			m.filtered = filtered
-		} else {
-			m.filtered = m.entries
-		}
-		m.logView.SetEntries(m.filtered)
-		m.statusBar.SetTotalLines(len(m.filtered))
-		m.statusBar.SetInfo(fmt.Sprintf("Level filter cleared — %d entries", len(m.filtered)))
-		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+// refilter rebuilds the visible entries from all entries through the filter
+// pipeline. Up to filterChunk entries are filtered right away; larger lists
+// are filtered in the background by the returned command, showing partial
+// results as they come in.
+func (m *Model) refilter() tea.Cmd {
+	m.cancelFilter()
+	m.filterStale = false
+	m.contextIDs = nil
+	if len(m.entries) <= filterChunk {
+		m.filtered = m.pipeline.Apply(m.entries)
+		m.showFiltered()
+		m.sortSearchResults()
+		return m.restartSearch()
 	}
 
-	var filtered []logentry.Entry
-	for _, entry := range m.entries {
-		// Apply expression filter first if active
-		if m.filter != nil {
-			matches, err := m.filter.Evaluate(entry)
-			if err != nil || !matches {
-				continue
-			}
-		}
-		if entry.Level == m.levelFilter {
-			filtered = append(filtered, entry)
-		}
-	}
+	m.jobs++
+	m.filterJob = m.pipeline.NewJob(m.jobs, m.entries)
+	m.filterCtx, m.filterCancel = context.WithCancel(context.Background())
+	m.statusBar.SetProgress("filter", 0, m.filterJob.Total())
+	return tea.Batch(filterChunkCmd(m.filterCtx, m.filterJob), m.restartSearch())
+}
 
-	m.filtered = filtered
+// showFiltered hands the filtered entries to the log view and status bar.
+func (m *Model) showFiltered() {
+	m.addContext()
+	m.syncPivot()
+	if m.keepsSorted() {
+		m.sortFiltered()
+	} else {
+		// Most logs are written in time order, which lets goto use a
+		// binary search without sorting first.
+		m.timeSorted = inTimeOrder(m.filtered, m.sortOrder)
+	}
+	m.filteredPos = nil
 	m.logView.SetEntries(m.filtered)
+	m.showContextRows()
 	m.statusBar.SetTotalLines(len(m.filtered))
-	m.statusBar.SetInfo(fmt.Sprintf("Level: %s — %d/%d entries", m.levelFilter.String(), len(filtered), len(m.entries)))
+	m.syncFilterStatus()
+	m.reselect()
+}
+
+// cancelFilter stops a running filter job. The entries filtered so far stay
+// visible.
+func (m *Model) cancelFilter() {
+	if m.filterJob == nil {
+		return
+	}
+	m.filterCancel()
+	m.filterJob = nil
+	m.filterStale = true
+	m.statusBar.SetProgress("filter", 0, 0)
+}
+
+// addFilterResults shows a chunk of entries from the running filter job.
+func (m Model) addFilterResults(msg FilterResultsMsg) (Model, tea.Cmd) {
+	if m.filterJob == nil || msg.JobID != m.filterJob.ID {
+		return m, nil
+	}
+
+	if msg.First {
+		m.filtered = nil
+	}
+	m.filtered = append(m.filtered, msg.Entries...)
+	if !msg.Done {
+		m.statusBar.SetProgress("filter", m.filterJob.Scanned(), m.filterJob.Total())
+		m.showFiltered()
+		m.updateSelectedEntry()
+		return m, filterChunkCmd(m.filterCtx, m.filterJob)
+	}
+
+	m.filterJob.Commit()
+	// Entries tailed while the job ran.
+	if total := m.filterJob.Total(); len(m.entries) > total {
+		m.filtered = append(m.filtered, m.pipeline.Append(m.entries[total:])...)
+	}
+	m.filterJob = nil
+	m.filterCancel()
+	m.statusBar.SetProgress("filter", 0, 0)
+	m.showFiltered()
+	m.sortSearchResults()
+	m.updateSelectedEntry()
+	m.statusBar.SetInfo(fmt.Sprintf("Filtered: %d/%d entries", len(m.filtered), len(m.entries)))
+	return m, nil
+}
+
+// syncFilterStatus shows the pipeline state in the status bar.
+func (m *Model) syncFilterStatus() {
+	m.statusBar.SetFilter(m.pipeline.Expression())
+	m.statusBar.SetLevelFilter(m.pipeline.Level())
+	m.statusBar.SetTimeRange(m.pipeline.TimeRange())
+	m.statusBar.SetSources(m.pipeline.Sources())
+
+	excludes := m.pipeline.Excludes()
+	rules := excludes.Rules()
+	counts := excludes.Counts()
+	stats := make([]ui.ExcludeCount, len(rules))
+	for i, r := range rules {
+		stats[i] = ui.ExcludeCount{Rule: r.Pattern, Count: counts[i]}
+	}
+	m.statusBar.SetExcludes(stats, m.pipeline.ShowExcluded())
+	m.statusBar.SetSuspended(m.pipeline.Suspended())
+	if m.contextActive() {
+		m.statusBar.SetContext(m.contextLines)
+	} else {
+		m.statusBar.SetContext(0)
+	}
+}
+
+// toggleLevel sets the minimum level, or clears it if it is already set to level.
+func (m Model) toggleLevel(level logentry.Level) (Model, tea.Cmd) {
+	if m.pipeline.Level() == level {
+		level = logentry.Unknown
+	}
+	return m.applyLevel(level)
+}
+
+// applyLevel sets the minimum level and refilters.
+func (m Model) applyLevel(level logentry.Level) (Model, tea.Cmd) {
+	m.pipeline.SetLevel(level)
+	m.pipeline.SetSuspended(false)
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+
+	if level == logentry.Unknown {
+		m.statusBar.SetInfo(fmt.Sprintf("Level filter cleared — %d entries", len(m.filtered)))
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+	m.statusBar.SetInfo(fmt.Sprintf("Level: >= %s — %d/%d entries", level.String(), len(m.filtered), len(m.entries)))
 	return m, tickCmd()
 }
 
 func (m Model) applyFilter(expr string) (Model, tea.Cmd) {
-	if expr == "" {
-		m.filter = nil
-		m.filterExpr = ""
-		m.filtered = m.entries
-		m.logView.SetEntries(m.filtered)
-		m.statusBar.SetTotalLines(len(m.filtered))
+	if err := m.pipeline.SetExpression(expr); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Filter error: %v", err))
 		return m, tickCmd()
 	}
+	m.pipeline.SetSuspended(false)
 
-	parsed, err := filter.Parse(expr)
-	if err != nil {
-		m.statusBar.SetError(fmt.Sprintf("Filter error: %v", err))
-		return m, tickCmd()
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+	if m.pipeline.Expression() != "" {
+		m.statusBar.SetInfo(fmt.Sprintf("Filtered: %d/%d entries", len(m.filtered), len(m.entries)))
 	}
+	return m, tickCmd()
+}
+
+// SetFilter sets the filter expression and minimum level before any file is
+// loaded. They are applied once entries arrive.
+func (m *Model) SetFilter(expr string, level logentry.Level) error {
+	if err := m.pipeline.SetExpression(expr); err != nil {
+		return fmt.Errorf("filter: %w", err)
+	}
+	m.pipeline.SetLevel(level)
+	m.filterBar.SetValue(m.pipeline.Expression())
+	m.syncFilterStatus()
+	return nil
+}
+
+// SetTimeRange limits the shown entries to a time window. Zero times leave
+// that end open.
+func (m *Model) SetTimeRange(since, until time.Time) {
+	m.pipeline.SetTimeRange(since, until)
+	m.syncFilterStatus()
+}
 
-	compiled, err := filter.Compile(parsed)
+// SetExcludes sets the exclusion rules from --exclude flags and the config file.
+func (m *Model) SetExcludes(patterns []string) error {
+	excludes, err := filter.NewExcludes(patterns)
 	if err != nil {
+		return err
+	}
+	m.pipeline.SetExcludes(excludes)
+	m.syncFilterStatus()
+	return nil
+}
+
+// excludeLikeSelected adds a rule hiding entries with the selected entry's message.
+func (m Model) excludeLikeSelected() (Model, tea.Cmd) {
+	if m.selectedEntry.Message == "" {
+		m.statusBar.SetError("Selected entry has no message to exclude")
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+	excludes := m.pipeline.Excludes()
+	pattern := filter.LikeEntryPattern(m.selectedEntry)
+	if _, err := excludes.Add(pattern); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Exclude error: %v", err))
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+	m.statusBar.SetInfo(fmt.Sprintf("Excluded %s — %d suppressed", pattern, excludes.Suppressed()))
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
+
+// toggleExcluded temporarily shows or hides entries suppressed by exclusion rules.
+func (m Model) toggleExcluded() (Model, tea.Cmd) {
+	excludes := m.pipeline.Excludes()
+	if excludes.Len() == 0 {
+		m.statusBar.SetInfo("No exclude rules")
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+	m.pipeline.SetShowExcluded(!m.pipeline.ShowExcluded())
+
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+	if m.pipeline.ShowExcluded() {
+		m.statusBar.SetInfo(fmt.Sprintf("Showing %d excluded entries", excludes.Suppressed()))
+	} else {
+		m.statusBar.SetInfo(fmt.Sprintf("Hiding %d excluded entries", excludes.Suppressed()))
+	}
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
+
+// showPresetPicker opens the preset picker listing all known presets.
+func (m *Model) showPresetPicker() {
+	presets := m.presets.All()
+	items := make([]ui.PresetItem, 0, len(presets))
+	for _, p := range presets {
+		items = append(items, ui.PresetItem{
+			Name:        p.Name,
+			Description: p.Description,
+			Expression:  p.Expression,
+			Level:       p.Level,
+		})
+	}
+	m.presetPicker.SetPresets(items)
+	m.presetPicker.Show()
+	m.mode = "presets"
+}
+
+// setPresetState replaces the active expression and level filter with a preset's.
+func (m *Model) setPresetState(preset filter.Preset) error {
+	if err := m.pipeline.SetExpression(preset.Expression); err != nil {
+		return fmt.Errorf("preset %s: %w", preset.Name, err)
+	}
+	m.pipeline.SetLevel(preset.Level)
+	m.pipeline.SetSuspended(false)
+	m.filterBar.SetValue(preset.Expression)
+	m.syncFilterStatus()
+	return nil
+}
+
+func (m Model) applyPreset(name string) (Model, tea.Cmd) {
+	preset, ok := m.presets.Get(name)
+	if !ok {
+		m.statusBar.SetError(fmt.Sprintf("Preset not found: %s", name))
+		return m, tickCmd()
+	}
+	if err := m.setPresetState(preset); err != nil {
 		m.statusBar.SetError(fmt.Sprintf("Filter error: %v", err))
 		return m, tickCmd()
 	}
 
-	m.filter = compiled
-	m.filterExpr = expr
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+	m.statusBar.SetInfo(fmt.Sprintf("Preset %s — %d/%d entries", name, len(m.filtered), len(m.entries)))
+	return m, tickCmd()
+}
 
-	var filtered []logentry.Entry
-	for _, entry := range m.entries {
-		matches, err := compiled.Evaluate(entry)
-		if err == nil && matches {
-			filtered = append(filtered, entry)
-		}
+// saveCurrentAsPreset stores the active expression and level filter as a
+// named preset and persists it to the config file.
+func (m Model) saveCurrentAsPreset(name string) (Model, tea.Cmd) {
+	expr, level := m.pipeline.Expression(), m.pipeline.Level()
+	if expr == "" && level == logentry.Unknown {
+		m.statusBar.SetError("No active filter to save")
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
 	}
 
-	m.filtered = filtered
-	m.logView.SetEntries(m.filtered)
-	m.statusBar.SetTotalLines(len(m.filtered))
-	m.statusBar.SetInfo(fmt.Sprintf("Filtered: %d/%d entries", len(filtered), len(m.entries)))
+	m.presets.Register(filter.Preset{
+		Name:       name,
+		Expression: expr,
+		Level:      level,
+	})
 
-	return m, tickCmd()
+	preset := config.PresetConfig{Filter: expr}
+	if level != logentry.Unknown {
+		preset.Level = strings.ToLower(level.String())
+	}
+
+	path := m.configPath
+	if path == "" {
+		path = config.DefaultConfigPath()
+	}
+	return m, tea.Batch(tickCmd(), savePresetCmd(path, name, preset))
 }
 
 func (m Model) applySearch(query string) (Model, tea.Cmd) {
+	m.cancelSearch()
 	if query == "" {
 		m.searchQuery = ""
 		m.searchResults = nil
@@ -710,38 +1306,399 @@ func (m Model) applySearch(query string) (Model, tea.Cmd) {
 		return m, tickCmd()
 	}
 
+	cmd, err := m.startSearch(query)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Search error: %v", err))
+		return m, tickCmd()
+	}
+	m.logView.SetSearchQuery(m.parseQuery(query))
+	m.statusBar.SetInfo(fmt.Sprintf("Searching for %q…", query))
+	return m, tea.Batch(tickCmd(), cmd)
+}
+
+// parseQuery parses a search query against the fields of the loaded entries.
+func (m *Model) parseQuery(query string) search.Query {
+	return search.ParseQuery(query, m.fieldStats.Has)
+}
+
+// startSearch starts a background search job over all entries, replacing the
+// current results.
+func (m *Model) startSearch(query string) (tea.Cmd, error) {
+	m.cancelSearch()
+	m.jobs++
+	job, err := search.NewJob(m.jobs, m.parseQuery(query), m.entries, m.index)
+	if err != nil {
+		return nil, err
+	}
+	// The job runs in the background, so it gets its own copy of the filters.
+	job.Keep = m.pipeline.Clone().Visible
+
+	m.searchJob = job
+	m.searchEnd = len(m.entries)
+	m.searchCtx, m.searchCancel = context.WithCancel(context.Background())
 	m.searchQuery = query
-	results := search.SmartMatch(m.filtered, query)
-	m.searchResults = results
+	m.searchResults = nil
 	m.searchIndex = 0
-	m.logView.SetSearchQuery(query)
+	m.statusBar.SetProgress("search", 0, job.Total())
+	return searchChunkCmd(m.searchCtx, job), nil
+}
 
-	if len(results) == 0 {
-		m.statusBar.SetInfo(fmt.Sprintf("No results for %q", query))
-		return m, tickCmd()
+// restartSearch reruns the active search after the filters changed, so the
+// matches are those of the entries now shown.
+func (m *Model) restartSearch() tea.Cmd {
+	if m.searchQuery == "" {
+		return nil
 	}
+	cmd, err := m.startSearch(m.searchQuery)
+	if err != nil {
+		return nil
+	}
+	return cmd
+}
 
-	// İlk eşleşen satıra git
-	m.jumpToSearchResult(0)
-	m.statusBar.SetInfo(fmt.Sprintf("Match 1/%d for %q", len(results), query))
-	return m, tickCmd()
+// cancelSearch stops a running search job. Matches found so far are kept.
+func (m *Model) cancelSearch() {
+	if m.searchJob == nil {
+		return
+	}
+	m.searchCancel()
+	m.searchJob = nil
+	m.statusBar.SetProgress("search", 0, 0)
 }
 
-func (m *Model) jumpToSearchResult(idx int) {
+// addSearchResults appends a chunk of streamed search results, jumping to the
+// first match as soon as it arrives. Results of superseded searches are dropped.
+func (m Model) addSearchResults(msg SearchResultsMsg) (Model, tea.Cmd) {
+	if m.searchJob == nil || msg.JobID != m.searchJob.ID {
+		return m, nil
+	}
+
+	first := len(m.searchResults) == 0
+	m.searchResults = append(m.searchResults, msg.Results...)
+	m.sortSearchResults()
+	if first && len(m.searchResults) > 0 {
+		m.jumpToSearchResult(m.searchIndex)
+	}
+
+	if !msg.Done {
+		m.statusBar.SetProgress("search", m.searchJob.Scanned(), m.searchJob.Total())
+		if len(m.searchResults) > 0 {
+			m.statusBar.SetInfo(fmt.Sprintf("Match %d/%d+ for %q", m.searchIndex+1, len(m.searchResults), m.searchQuery))
+		}
+		return m, searchChunkCmd(m.searchCtx, m.searchJob)
+	}
+
+	m.searchJob = nil
+	m.searchCancel()
+	m.statusBar.SetProgress("search", 0, 0)
+	// Entries tailed while the job ran.
+	if len(m.entries) > m.searchEnd {
+		m.searchAppended(m.searchEnd)
+	}
 	if len(m.searchResults) == 0 {
+		m.statusBar.SetInfo(fmt.Sprintf("No results for %q", m.searchQuery))
+		return m, nil
+	}
+	m.statusBar.SetInfo(fmt.Sprintf("Match %d/%d for %q", m.searchIndex+1, len(m.searchResults), m.searchQuery))
+	return m, nil
+}
+
+// searchAppended adds the matches among the entries from base on, which were
+// appended while tailing, to the search results.
+func (m *Model) searchAppended(base int) {
+	job, err := search.NewJob(0, m.parseQuery(m.searchQuery), m.entries[base:], nil)
+	if err != nil {
+		return
+	}
+	job.Keep = m.pipeline.Visible
+	results := job.Next(context.Background(), job.Total())
+	if len(results) == 0 {
+		return
+	}
+	for i := range results {
+		results[i].Position += base
+	}
+	m.searchResults = append(m.searchResults, results...)
+	m.sortSearchResults()
+}
+
+// sortSearchResults puts the search results in the order they are shown,
+// keeping the current match selected. While a filter job is still running
+// the shown entries are incomplete and the results are left alone.
+func (m *Model) sortSearchResults() {
+	if len(m.searchResults) == 0 || m.filterJob != nil {
+		return
+	}
+	var current logentry.ID
+	if m.searchIndex < len(m.searchResults) {
+		current = m.searchResults[m.searchIndex].ID
+	}
+
+	type shown struct {
+		result search.SearchResult
+		pos    int
+	}
+	results := make([]shown, 0, len(m.searchResults))
+	for _, r := range m.searchResults {
+		// Matches the filters hide are dropped.
+		if pos, ok := m.positionOf(r.ID); ok {
+			results = append(results, shown{r, pos})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].pos < results[j].pos })
+
+	m.searchResults = make([]search.SearchResult, len(results))
+	m.searchIndex = 0
+	for i, r := range results {
+		m.searchResults[i] = r.result
+		if r.result.ID == current {
+			m.searchIndex = i
+		}
+	}
+}
+
+// positionOf returns the index in filtered of the entry with the given identity.
+func (m *Model) positionOf(id logentry.ID) (int, bool) {
+	if m.filteredPos == nil {
+		m.filteredPos = make(map[logentry.ID]int, len(m.filtered))
+		for i, entry := range m.filtered {
+			m.filteredPos[entry.ID()] = i
+		}
+	}
+	pos, ok := m.filteredPos[id]
+	return pos, ok
+}
+
+// keepsSorted reports whether filtered is sorted rather than left in the
+// order entries were read.
+func (m *Model) keepsSorted() bool {
+	return m.sortOrder == SortDesc || m.sortColumn != "" || m.pivot != nil
+}
+
+// atNewest reports whether row is where followed entries arrive, so new
+// entries keep it in view.
+func (m *Model) atNewest(row int) bool {
+	if m.sortOrder == SortDesc {
+		return row <= 0
+	}
+	return row >= len(m.filtered)-1
+}
+
+// scrollToNewest scrolls to where followed entries arrive: the top when
+// sorted in descending order, otherwise the bottom.
+func (m *Model) scrollToNewest() {
+	if m.sortOrder == SortDesc {
+		m.logView.ScrollToTop()
 		return
 	}
-	result := m.searchResults[idx]
-	// filtered slice içindeki gerçek index'i bul
-	for i, entry := range m.filtered {
-		if entry.Raw == result.Entry.Raw && entry.Timestamp.Equal(result.Entry.Timestamp) {
-			m.logView.SetSelected(i)
-			m.selectedEntry = entry
-			m.sidebar.SetEntry(entry)
-			m.statusBar.SetSelected(i)
-			return
+	m.logView.ScrollToBottom()
+}
+
+// appendFiltered adds entries to the end of filtered.
+func (m *Model) appendFiltered(entries []logentry.Entry) {
+	for _, entry := range entries {
+		if m.timeSorted && len(m.filtered) > 0 {
+			last := m.filtered[len(m.filtered)-1].Timestamp
+			if m.sortOrder == SortAsc && entry.Timestamp.Before(last) || m.sortOrder == SortDesc && entry.Timestamp.After(last) {
+				m.timeSorted = false
+			}
 		}
+		if m.filteredPos != nil {
+			m.filteredPos[entry.ID()] = len(m.filtered)
+		}
+		m.filtered = append(m.filtered, entry)
+	}
+}
+
+// toggleFollow turns follow mode on or off. Turning it off pauses the
+// follower; what is appended meanwhile is read when it is turned on again.
+func (m *Model) toggleFollow() tea.Cmd {
+	m.followMode = !m.followMode
+	m.statusBar.SetFollowing(m.followMode)
+	if !m.followMode && m.follower != nil {
+		m.follower.pause()
 	}
+	return m.followCmd()
+}
+
+// followCmd starts following the open file if follow mode is on, and
+// returns a command waiting for the next batch the follower reads.
+func (m *Model) followCmd() tea.Cmd {
+	if !m.followMode || m.filePath == "" || m.loading {
+		return nil
+	}
+	if m.follower == nil {
+		var f *follower
+		var err error
+		if m.loadedFiles != nil {
+			lines := make(map[string]int, len(m.loadedFiles))
+			for _, entry := range m.entries {
+				lines[entry.Source]++
+			}
+			f, err = newDirFollower(m.filePath, m.watchGlob, m.watchDepth, m.loadedFiles, lines, m.tailOptions, m.followParser)
+		} else {
+			f, err = newFollower(m.filePath, m.loadedSize, len(m.entries), m.tailOptions, m.followParser)
+		}
+		if err != nil {
+			m.statusBar.SetError(err.Error())
+			return clearInfoCmd(3 * time.Second)
+		}
+		m.follower = f
+	}
+	m.follower.resume()
+	if m.follower.waiting {
+		return nil
+	}
+	return m.follower.waitCmd()
+}
+
+// followedSources returns the files of a followed directory, to label
+// entries with, or nil when a single file is open.
+func (m *Model) followedSources() []string {
+	if m.loadedFiles == nil {
+		return nil
+	}
+	if m.follower != nil {
+		sources := make([]string, 0, len(m.follower.files))
+		for path := range m.follower.files {
+			sources = append(sources, path)
+		}
+		return sources
+	}
+	sources := make([]string, 0, len(m.loadedFiles))
+	for path := range m.loadedFiles {
+		sources = append(sources, path)
+	}
+	return sources
+}
+
+// addFollowBatch adds what the follower read since the last batch. The
+// entries of the batch are appended together.
+func (m *Model) addFollowBatch(msg FollowBatchMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	var entries []logentry.Entry
+	for _, msg := range msg.Msgs {
+		switch msg := msg.(type) {
+		case NewLinesMsg:
+			if msg.Rotated || msg.Truncated {
+				cmds = append(cmds, m.addFollowMarker(msg))
+			}
+			entries = append(entries, msg.Entries...)
+		case SourceChangedMsg:
+			cmds = append(cmds, m.sourceChanged(msg))
+		}
+	}
+	cmds = append(cmds, m.appendEntries(entries))
+
+	m.statusBar.SetDropped(msg.Dropped)
+	m.sourcesPanel.SetSources(sourceItems(msg.Sources), msg.Dropped)
+	return tea.Batch(cmds...)
+}
+
+// appendEntries adds entries read from the followed files. Scrolling up
+// pauses auto-scroll until the end is reached again.
+func (m *Model) appendEntries(entries []logentry.Entry) tea.Cmd {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	_, selected := m.logView.GetSelected()
+	atEnd := m.atNewest(selected)
+	shown := len(m.filtered)
+	base := len(m.entries)
+	m.entries = append(m.entries, entries...)
+	if m.fieldStats != nil {
+		m.fieldStats.Add(entries...)
+	}
+	cmd := indexEntriesCmd(m.index, base, entries)
+	// A running filter job picks up new entries when it finishes.
+	if m.filterJob == nil {
+		m.appendFiltered(m.pipeline.Append(entries))
+	}
+	if m.contextIDs != nil || m.keepsSorted() {
+		// New entries may be context of earlier matches, or belong
+		// elsewhere than at the end in the current order. The selected
+		// entry stays selected wherever they go.
+		m.keepSelection()
+		m.showFiltered()
+	} else {
+		m.logView.SetEntries(m.filtered)
+		m.statusBar.SetTotalLines(len(m.filtered))
+		m.syncFilterStatus()
+	}
+	if m.searchQuery != "" && m.searchJob == nil {
+		m.searchAppended(base)
+	}
+	if atEnd {
+		m.scrollToNewest()
+		m.updateSelectedEntry()
+	} else {
+		m.newEntries += len(m.filtered) - shown
+		m.statusBar.SetNewEntries(m.newEntries)
+	}
+	return cmd
+}
+
+// sourceChanged reports a followed file that was deleted or attached.
+func (m *Model) sourceChanged(msg SourceChangedMsg) tea.Cmd {
+	switch {
+	case m.loadedFiles == nil:
+		m.statusBar.SetInfo("File deleted, waiting for it to be created again")
+	case msg.Deleted:
+		m.statusBar.SetInfo("Stopped following deleted " + filepath.Base(msg.Path))
+	default:
+		m.statusBar.SetInfo("Following new file " + filepath.Base(msg.Path))
+	}
+	m.logView.SetSourceLabels(m.followedSources())
+	return clearInfoCmd(3 * time.Second)
+}
+
+// sourceItems converts the state of the followed files for the sources
+// panel.
+func sourceItems(statuses []tail.SourceStatus) []ui.SourceItem {
+	items := make([]ui.SourceItem, len(statuses))
+	for i, status := range statuses {
+		items[i] = ui.SourceItem{
+			Path:    status.Path,
+			Status:  status.Status.String(),
+			Lines:   status.Lines,
+			Dropped: status.Dropped,
+		}
+		if status.Error != nil {
+			items[i].Error = status.Error.Error()
+		}
+	}
+	return items
+}
+
+// addFollowMarker marks where the followed file was rotated or truncated,
+// above the first entry read from the start of the file.
+func (m *Model) addFollowMarker(msg NewLinesMsg) tea.Cmd {
+	text, info := "file rotated", "File rotated, following the new file"
+	if msg.Truncated {
+		text, info = "file truncated", "File truncated, reading from the start"
+	}
+	m.markers = append(m.markers, ui.Marker{
+		ID:   logentry.ID{Source: msg.Source, Offset: msg.Base},
+		Text: fmt.Sprintf("%s at %s", text, time.Now().Format("15:04:05")),
+	})
+	m.logView.SetMarkers(m.markers)
+	m.statusBar.SetInfo(info)
+	return clearInfoCmd(3 * time.Second)
+}
+
+func (m *Model) jumpToSearchResult(idx int) {
+	if idx >= len(m.searchResults) {
+		return
+	}
+	i, ok := m.positionOf(m.searchResults[idx].ID)
+	if !ok {
+		return
+	}
+	m.logView.SetSelected(i)
+	m.selectedEntry = m.filtered[i]
+	m.sidebar.SetEntry(m.selectedEntry)
+	m.statusBar.SetSelected(i)
 }
 
 func (m Model) searchNext() (Model, tea.Cmd) {
@@ -774,16 +1731,42 @@ func (m *Model) toggleSort() {
 }
 
 func (m *Model) applySort() {
-	sort.Slice(m.filtered, func(i, j int) bool {
-		if m.sortOrder == SortAsc {
-			return m.filtered[i].Timestamp.Before(m.filtered[j].Timestamp)
-		}
-		return m.filtered[i].Timestamp.After(m.filtered[j].Timestamp)
-	})
+	m.sortFiltered()
+	m.filteredPos = nil
 	m.logView.SetEntries(m.filtered)
+	m.showContextRows()
+	m.sortSearchResults()
+	m.logView.SetSortColumn(m.sortColumn, m.sortOrder == SortDesc)
 	orderText := "ascending"
 	if m.sortOrder == SortDesc {
 		orderText = "descending"
 	}
-	m.statusBar.SetInfo(fmt.Sprintf("Sorted by timestamp: %s", orderText))
+	by := "timestamp"
+	if m.sortColumn != "" {
+		by = m.sortColumn
+	}
+	m.statusBar.SetInfo(fmt.Sprintf("Sorted by %s: %s", by, orderText))
+}
+
+// sortFiltered sorts the visible entries by timestamp, or by the sort
+// column, in the current order.
+func (m *Model) sortFiltered() {
+	if m.sortColumn != "" {
+		m.timeSorted = false
+		sort.SliceStable(m.filtered, func(i, j int) bool {
+			c := columns.Compare(m.filtered[i], m.filtered[j], m.sortColumn)
+			if m.sortOrder == SortAsc {
+				return c < 0
+			}
+			return c > 0
+		})
+		return
+	}
+	m.timeSorted = true
+	sort.SliceStable(m.filtered, func(i, j int) bool {
+		if m.sortOrder == SortAsc {
+			return m.filtered[i].Timestamp.Before(m.filtered[j].Timestamp)
+		}
+		return m.filtered[i].Timestamp.After(m.filtered[j].Timestamp)
+	})
 }
@@ -1,11 +1,28 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/bookmark"
+	"github.com/ersanisk/sieve/internal/clipboard"
+	"github.com/ersanisk/sieve/internal/columns"
+	"github.com/ersanisk/sieve/internal/config"
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/history"
+	"github.com/ersanisk/sieve/internal/tail"
+	"github.com/ersanisk/sieve/internal/ui"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
 
@@ -134,8 +151,7 @@ func TestFilterClearWithESC(t *testing.T) {
 	model.filtered = model.entries
 
 	// Level filter uygula
-	model.levelFilter = logentry.Error
-	model, _ = model.applyLevelFilter()
+	model, _ = model.applyLevel(logentry.Error)
 
 	if len(model.filtered) != 1 {
 		t.Errorf("Expected 1 filtered entry, got %d", len(model.filtered))
@@ -147,7 +163,7 @@ func TestFilterClearWithESC(t *testing.T) {
 	model = newModel.(Model)
 
 	// Filter temizlenmeli
-	if model.levelFilter != logentry.Unknown {
+	if model.pipeline.Level() != logentry.Unknown {
 		t.Error("Level filter should be cleared after ESC")
 	}
 
@@ -155,3 +171,1679 @@ func TestFilterClearWithESC(t *testing.T) {
 		t.Errorf("Expected all entries after filter clear, got %d", len(model.filtered))
 	}
 }
+
+func TestApplyPreset(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	model.presets.Register(filter.Preset{Name: "auth", Expression: `.service == "auth"`})
+	model.presets.Register(filter.Preset{Name: "auth-warn", Expression: `.service == "auth"`, Level: logentry.Warn})
+	model.entries = []logentry.Entry{
+		{Level: logentry.Info, Message: "a", Fields: map[string]any{"service": "auth"}, Offset: 0},
+		{Level: logentry.Info, Message: "b", Fields: map[string]any{"service": "api"}, Offset: 1},
+		{Level: logentry.Warn, Message: "c", Fields: map[string]any{"service": "auth"}, Offset: 2},
+		{Level: logentry.Error, Message: "d", Fields: map[string]any{"service": "auth"}, Offset: 3},
+	}
+	model.filtered = model.entries
+
+	newModel, _ := model.Update(ui.FilterSetPresetMsg{Preset: "auth"})
+	model = newModel.(Model)
+
+	if got := model.pipeline.Expression(); got != `.service == "auth"` {
+		t.Errorf("Expression() = %q, want preset expression", got)
+	}
+	if len(model.filtered) != 3 {
+		t.Errorf("Expected the 3 auth entries, got %v", model.filtered)
+	}
+
+	// A preset's level is a minimum, as on the command line.
+	newModel, _ = model.Update(ui.FilterSetPresetMsg{Preset: "auth-warn"})
+	model = newModel.(Model)
+	if len(model.filtered) != 2 || model.filtered[0].Message != "c" || model.filtered[1].Message != "d" {
+		t.Errorf("Expected the auth entries at warn and above, got %v", model.filtered)
+	}
+
+	if _, ok := NewModel("", "kanagawa", false).presets.Get("auth"); ok {
+		t.Error("presets registered on one model should not leak into another")
+	}
+}
+
+func TestSaveCurrentAsPreset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	model := NewModel("", "kanagawa", false)
+	model.SetConfigPath(path)
+	if err := model.pipeline.SetExpression(`.status >= 500`); err != nil {
+		t.Fatalf("SetExpression() error = %v", err)
+	}
+	model.pipeline.SetLevel(logentry.Error)
+
+	newModel, cmd := model.Update(ui.PresetSaveMsg{Name: "server-errors"})
+	model = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected save command")
+	}
+
+	if _, ok := model.presets.Get("server-errors"); !ok {
+		t.Error("Saved preset should be registered immediately")
+	}
+
+	var saved tea.Msg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(ui.PresetSavedMsg); ok {
+			saved = msg
+		}
+	}
+	if savedMsg, ok := saved.(ui.PresetSavedMsg); !ok || savedMsg.Err != nil || savedMsg.Name != "server-errors" {
+		t.Fatalf("Expected successful PresetSavedMsg, got %#v", saved)
+	}
+
+	cfg, err := config.LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if got := cfg.Filters.Presets["server-errors"]; got.Filter != `.status >= 500` || got.Level != "error" {
+		t.Errorf("persisted preset = %+v", got)
+	}
+}
+
+func TestSubmitRecordsHistory(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	searchHistory := history.New("", 10)
+	filterHistory := history.New("", 10)
+	model.SetHistory(searchHistory, filterHistory)
+	model.entries = []logentry.Entry{{Level: logentry.Error, Message: "boom"}}
+	model.filtered = model.entries
+
+	model.searchBar.SetValue("boom")
+	newModel, _ := model.Update(ui.SearchSubmitMsg{})
+	model = newModel.(Model)
+
+	model.filterBar.SetValue(".level >=")
+	newModel, _ = model.Update(ui.FilterSubmitMsg{})
+	model = newModel.(Model)
+
+	if got := searchHistory.Entries(); len(got) != 1 || got[0] != "boom" {
+		t.Errorf("search history = %v, want [boom]", got)
+	}
+	if got := filterHistory.Entries(); len(got) != 0 {
+		t.Errorf("invalid filter should not be recorded, got %v", got)
+	}
+}
+
+func TestSearchStreamsResults(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "request served", Raw: "request served", Offset: 0},
+		{Level: logentry.Error, Message: "connection timeout", Raw: "connection timeout", Offset: 15},
+		{Level: logentry.Debug, Message: "retrying after timeout", Raw: "retrying after timeout", Offset: 34},
+		{Level: logentry.Error, Message: "timeout again", Raw: "timeout again", Offset: 57},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	model.index.Add(0, entries)
+	model, _ = model.applyLevel(logentry.Info)
+
+	model, _ = model.applySearch("timeout")
+	stale := model.searchJob
+	model, _ = model.applySearch("timeout")
+	if model.searchJob == nil || model.searchJob == stale {
+		t.Fatal("applySearch() should start a new job")
+	}
+
+	// A cancelled job sends nothing, and results it sent before being
+	// superseded are ignored.
+	if msg := searchChunkCmd(model.searchCtx, model.searchJob)(); msg == nil {
+		t.Fatal("searchChunkCmd() returned nil for the running job")
+	}
+	model, _ = model.applySearch("timeout")
+	newModel, _ = model.Update(searchChunkCmd(context.Background(), stale)())
+	model = newModel.(Model)
+	if len(model.searchResults) != 0 {
+		t.Fatalf("stale job added %d results", len(model.searchResults))
+	}
+
+	for model.searchJob != nil {
+		newModel, _ = model.Update(searchChunkCmd(model.searchCtx, model.searchJob)())
+		model = newModel.(Model)
+	}
+	if len(model.searchResults) != 2 {
+		t.Fatalf("searchResults = %d, want 2 matches passing the level filter", len(model.searchResults))
+	}
+	if got := model.selectedEntry.Message; got != "connection timeout" {
+		t.Errorf("selected %q, want first match", got)
+	}
+
+	newLines := NewLinesMsg{Entries: []logentry.Entry{{Level: logentry.Warn, Message: "late timeout", Raw: "late timeout", Offset: 71}}}
+	newModel, _ = model.Update(newLines)
+	model = newModel.(Model)
+	model.index.Add(len(entries), newLines.Entries)
+	if model.index.Len() != len(entries)+1 {
+		t.Errorf("index.Len() = %d, want appended entries indexed", model.index.Len())
+	}
+	if len(model.searchResults) != 3 {
+		t.Errorf("searchResults = %d, want tailed match added", len(model.searchResults))
+	}
+}
+
+func TestSearchKeepsRunningWhileTyping(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "connection timeout", Offset: 0},
+		{Level: logentry.Info, Message: "timeout again", Offset: 19},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	model, _ = model.applySearch("timeout")
+	job := model.searchJob
+
+	// Opening the search bar, typing and leaving with Esc keeps the
+	// running search.
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune{'/'}},
+		{Type: tea.KeyRunes, Runes: []rune{'x'}},
+		{Type: tea.KeyEsc},
+	} {
+		newModel, _ = model.Update(msg)
+		model = newModel.(Model)
+	}
+	if model.searchJob != job {
+		t.Fatal("typing in the search bar stopped the running search")
+	}
+	for model.searchJob != nil {
+		newModel, _ = model.Update(searchChunkCmd(model.searchCtx, model.searchJob)())
+		model = newModel.(Model)
+	}
+	if len(model.searchResults) != 2 {
+		t.Errorf("searchResults = %d, want both matches", len(model.searchResults))
+	}
+}
+
+func TestSearchSurvivesReload(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	old := []logentry.Entry{
+		{Level: logentry.Info, Message: "alpha one", Offset: 0},
+		{Level: logentry.Info, Message: "alpha two", Offset: 10},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: old})
+	model = newModel.(Model)
+	model.index.Add(0, old)
+	model, _ = model.applySearch("beta")
+	for model.searchJob != nil {
+		newModel, _ = model.Update(searchChunkCmd(model.searchCtx, model.searchJob)())
+		model = newModel.(Model)
+	}
+
+	// Reloading restarts the search over the new entries, which the
+	// previous file's index knows nothing about.
+	reloaded := []logentry.Entry{
+		{Level: logentry.Info, Message: "beta one", Offset: 0},
+		{Level: logentry.Info, Message: "alpha two", Offset: 9},
+	}
+	newModel, _ = model.Update(ui.FileLoadedMsg{Entries: reloaded})
+	model = newModel.(Model)
+	for model.searchJob != nil {
+		newModel, _ = model.Update(searchChunkCmd(model.searchCtx, model.searchJob)())
+		model = newModel.(Model)
+	}
+	if len(model.searchResults) != 1 || model.searchResults[0].Entry.Message != "beta one" {
+		t.Errorf("searchResults after reload = %+v, want the beta entry", model.searchResults)
+	}
+}
+
+func TestSearchResultsSurviveSortAndDuplicates(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var entries []logentry.Entry
+	for i := 0; i < 4; i++ {
+		// The same line repeated, told apart by offset only.
+		entries = append(entries, logentry.Entry{
+			Level: logentry.Error, Message: "timeout", Raw: "timeout",
+			Timestamp: base.Add(time.Duration(i) * time.Second), Source: "app.log", Offset: int64(i * 8),
+		})
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+
+	model, _ = model.applySearch("timeout")
+	for model.searchJob != nil {
+		newModel, _ = model.Update(searchChunkCmd(model.searchCtx, model.searchJob)())
+		model = newModel.(Model)
+	}
+	if got := model.searchResults[0]; got.ID != (logentry.ID{Source: "app.log", Offset: 0}) || got.Span != [2]int{0, 7} {
+		t.Errorf("first result ID = %v, Span = %v", got.ID, got.Span)
+	}
+
+	var selected []int64
+	for range entries {
+		selected = append(selected, model.selectedEntry.Offset)
+		model, _ = model.searchNext()
+	}
+	if want := []int64{0, 8, 16, 24}; fmt.Sprint(selected) != fmt.Sprint(want) {
+		t.Errorf("n visits offsets %v, want %v", selected, want)
+	}
+
+	// After n, the current match is offset 8. Reversing the order keeps it
+	// current and n then moves on in display order.
+	model, _ = model.searchNext()
+	model.toggleSort()
+	if got := model.searchResults[model.searchIndex].ID.Offset; got != 8 {
+		t.Fatalf("current match after sort = offset %d, want 8", got)
+	}
+	model, _ = model.searchNext()
+	if got := model.selectedEntry.Offset; got != 0 {
+		t.Errorf("n after reversing selected offset %d, want 0", got)
+	}
+	if got := model.searchResults[0].ID.Offset; got != 24 {
+		t.Errorf("first result after reversing = offset %d, want 24", got)
+	}
+}
+
+func TestAsyncFilter(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	entries := make([]logentry.Entry, 2*filterChunk+10)
+	for i := range entries {
+		level := logentry.Info
+		if i%10 == 0 {
+			level = logentry.Error
+		}
+		entries[i] = logentry.Entry{Level: level, Message: fmt.Sprintf("entry %d", i), Raw: fmt.Sprintf("entry %d", i)}
+	}
+	model.entries = entries
+	model.filtered = entries
+
+	model, cmd := model.applyLevel(logentry.Error)
+	if cmd == nil || model.filterJob == nil {
+		t.Fatal("applyLevel() on a large list should start a filter job")
+	}
+	job := model.filterJob
+
+	// First chunk replaces the shown entries with partial results.
+	newModel, _ := model.Update(filterChunkCmd(model.filterCtx, job)())
+	model = newModel.(Model)
+	if got, want := len(model.filtered), filterChunk/10; got != want {
+		t.Fatalf("filtered = %d after first chunk, want %d", got, want)
+	}
+
+	// Typing in the filter bar cancels the job and Esc finishes it again.
+	model.filterBar.Show()
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'.'}})
+	model = newModel.(Model)
+	if model.filterJob != nil {
+		t.Fatal("keystroke in the filter bar should cancel the filter job")
+	}
+	if msg := filterChunkCmd(model.filterCtx, job)(); msg != nil {
+		t.Errorf("cancelled job sent %T", msg)
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = newModel.(Model)
+	if model.filterJob == nil {
+		t.Fatal("closing the filter bar should restart the cancelled filter")
+	}
+
+	newModel, _ = model.Update(NewLinesMsg{Entries: []logentry.Entry{{Level: logentry.Error, Message: "tailed", Raw: "tailed"}}})
+	model = newModel.(Model)
+	for model.filterJob != nil {
+		newModel, _ = model.Update(filterChunkCmd(model.filterCtx, model.filterJob)())
+		model = newModel.(Model)
+	}
+	if got, want := len(model.filtered), (2*filterChunk+10)/10+1; got != want {
+		t.Errorf("filtered = %d, want %d including the tailed entry", got, want)
+	}
+	if got := model.filtered[len(model.filtered)-1].Message; got != "tailed" {
+		t.Errorf("last entry = %q, want tailed entry last", got)
+	}
+}
+
+func TestExcludeRules(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	if err := model.SetExcludes([]string{"/healthz/"}); err != nil {
+		t.Fatalf("SetExcludes() error = %v", err)
+	}
+
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: []logentry.Entry{
+		{Level: logentry.Info, Message: "GET /healthz"},
+		{Level: logentry.Info, Message: "cache warmed"},
+		{Level: logentry.Info, Message: "user login"},
+		{Level: logentry.Info, Message: "cache warmed"},
+	}})
+	model = newModel.(Model)
+	if len(model.filtered) != 3 {
+		t.Fatalf("filtered = %d entries, want 3 after --exclude", len(model.filtered))
+	}
+
+	model.updateSelectedEntry()
+	model, _ = model.excludeLikeSelected()
+	if len(model.filtered) != 1 || model.filtered[0].Message != "user login" {
+		t.Fatalf("filtered = %v, want only the login entry", model.filtered)
+	}
+	if got := model.pipeline.Excludes().Counts(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Counts() = %v, want [1 2]", got)
+	}
+
+	model, _ = model.toggleExcluded()
+	if len(model.filtered) != 4 {
+		t.Errorf("filtered = %d entries while revealed, want 4", len(model.filtered))
+	}
+	model, _ = model.toggleExcluded()
+	if len(model.filtered) != 1 {
+		t.Errorf("filtered = %d entries after hiding again, want 1", len(model.filtered))
+	}
+
+	if err := model.SetExcludes([]string{".msg =="}); err == nil {
+		t.Error("SetExcludes() with an invalid expression should fail")
+	}
+}
+
+func TestNewLinesAreFiltered(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	if err := model.SetFilter(`.service == "auth"`, logentry.Warn); err != nil {
+		t.Fatalf("SetFilter() error = %v", err)
+	}
+
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: []logentry.Entry{
+		{Level: logentry.Error, Message: "a", Fields: map[string]any{"service": "auth"}},
+		{Level: logentry.Info, Message: "b", Fields: map[string]any{"service": "auth"}},
+	}})
+	model = newModel.(Model)
+	if len(model.filtered) != 1 {
+		t.Fatalf("filtered = %d entries after load, want 1", len(model.filtered))
+	}
+
+	newModel, _ = model.Update(NewLinesMsg{Entries: []logentry.Entry{
+		{Level: logentry.Error, Message: "c", Fields: map[string]any{"service": "api"}},
+		{Level: logentry.Warn, Message: "d", Fields: map[string]any{"service": "auth"}},
+		{Level: logentry.Debug, Message: "e", Fields: map[string]any{"service": "auth"}},
+	}})
+	model = newModel.(Model)
+
+	if len(model.entries) != 5 {
+		t.Errorf("entries = %d, want all 5 kept", len(model.entries))
+	}
+	if len(model.filtered) != 2 || model.filtered[1].Message != "d" {
+		t.Errorf("filtered = %v, want only matching new lines appended", model.filtered)
+	}
+
+	// Applying a new expression keeps the level threshold.
+	model, _ = model.applyFilter(`.service == "api"`)
+	if len(model.filtered) != 1 || model.filtered[0].Message != "c" {
+		t.Errorf("filtered = %v, want level threshold kept with new expression", model.filtered)
+	}
+}
+
+// followMsgs hands out the messages of a follower's batches one at a time.
+type followMsgs struct {
+	f      *follower
+	queued []tea.Msg
+}
+
+// next returns the next message, waiting for another batch when none are
+// left.
+func (q *followMsgs) next(t *testing.T) tea.Msg {
+	t.Helper()
+	for len(q.queued) == 0 {
+		q.queued = nextFollowBatch(t, q.f).Msgs
+	}
+	msg := q.queued[0]
+	q.queued = q.queued[1:]
+	return msg
+}
+
+// nextFollowBatch runs the follower's wait command, failing if nothing comes.
+func nextFollowBatch(t *testing.T, f *follower) FollowBatchMsg {
+	t.Helper()
+	msgCh := make(chan tea.Msg, 1)
+	go func() { msgCh <- f.waitCmd()() }()
+	select {
+	case msg := <-msgCh:
+		batch, ok := msg.(FollowBatchMsg)
+		if !ok {
+			t.Fatalf("waitCmd() = %T, want FollowBatchMsg", msg)
+		}
+		return batch
+	case <-time.After(4 * time.Second):
+		t.Fatal("timeout waiting for the follower")
+		return FollowBatchMsg{}
+	}
+}
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatalf("WriteString() error = %v", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	loaded, ok := loadFileCmd(path, true)().(ui.FileLoadedMsg)
+	if !ok || loaded.Size != 12 {
+		t.Fatalf("loadFileCmd() = %+v, want 12 bytes loaded", loaded)
+	}
+	model := NewModel(path, "kanagawa", false)
+	newModel, _ := model.Update(loaded)
+	model = newModel.(Model)
+
+	f, err := newFollower(path, loaded.Size, len(loaded.Entries), tail.ManagerOptions{}, model.followParser)
+	if err != nil {
+		t.Fatalf("newFollower() error = %v", err)
+	}
+	defer f.stop()
+	model.follower = f
+	msgs := &followMsgs{f: f}
+
+	// A line still being written is held back until its newline arrives.
+	appendFile(t, path, "old 3\nold")
+	msg, _ := msgs.next(t).(NewLinesMsg)
+	if len(msg.Entries) != 1 || msg.Entries[0].Raw != "old 3" || msg.Entries[0].Offset != 12 || msg.Entries[0].Line != 3 {
+		t.Fatalf("Entries = %+v, want old 3 at offset 12, line 3", msg.Entries)
+	}
+	newModel, _ = model.Update(msg)
+	model = newModel.(Model)
+	appendFile(t, path, " 4\n")
+	msg, _ = msgs.next(t).(NewLinesMsg)
+	if len(msg.Entries) != 1 || msg.Entries[0].Raw != "old 4" || msg.Entries[0].Offset != 18 {
+		t.Fatalf("Entries = %+v, want old 4 at offset 18", msg.Entries)
+	}
+	newModel, _ = model.Update(msg)
+	model = newModel.(Model)
+
+	// Rotate: move the file away and start a new one in its place.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("new 1\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	msg, _ = msgs.next(t).(NewLinesMsg)
+	if !msg.Rotated || msg.Base != 24 {
+		t.Fatalf("msg = %+v, want rotation with offsets from 24", msg)
+	}
+	newModel, _ = model.Update(msg)
+	model = newModel.(Model)
+	msg, _ = msgs.next(t).(NewLinesMsg)
+	if len(msg.Entries) != 1 || msg.Entries[0].Offset != 24 || msg.Entries[0].Line != 5 {
+		t.Fatalf("Entries = %+v, want new 1 at offset 24, line 5", msg.Entries)
+	}
+	newModel, _ = model.Update(msg)
+	model = newModel.(Model)
+
+	if len(model.entries) != 5 {
+		t.Errorf("entries = %d, want 5", len(model.entries))
+	}
+	if len(model.markers) != 1 || model.markers[0].ID != msg.Entries[0].ID() {
+		t.Errorf("markers = %v, want one at the first new entry", model.markers)
+	}
+
+	// Batches of a follower that was replaced are dropped.
+	newModel, _ = model.Update(FollowBatchMsg{Msgs: []tea.Msg{NewLinesMsg{Entries: msg.Entries}}, from: &follower{}})
+	if model = newModel.(Model); len(model.entries) != 5 {
+		t.Errorf("entries = %d after a stale message, want 5", len(model.entries))
+	}
+}
+
+func TestFollowDirectory(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+	if err := os.WriteFile(a, []byte(`{"time":"2024-01-15T10:00:00Z","msg":"a1"}`+"\n"+`{"time":"2024-01-15T10:02:00Z","msg":"a2"}`+"\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(b, []byte(`{"time":"2024-01-15T10:01:00Z","msg":"b1"}`+"\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skipped\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	loaded, ok := loadDirCmd(dir, "*.log", 0)().(ui.FileLoadedMsg)
+	if !ok {
+		t.Fatalf("loadDirCmd() did not load the directory")
+	}
+	var got []string
+	for _, entry := range loaded.Entries {
+		got = append(got, entry.Message)
+	}
+	if fmt.Sprint(got) != "[a1 b1 a2]" {
+		t.Errorf("entries = %v, want files interleaved by time", got)
+	}
+	if len(loaded.Files) != 2 || loaded.Files[b] != 43 {
+		t.Errorf("Files = %v, want a.log and b.log with their sizes", loaded.Files)
+	}
+
+	model := NewModel(dir, "kanagawa", false)
+	newModel, _ := model.Update(loaded)
+	model = newModel.(Model)
+
+	f, err := newDirFollower(dir, "*.log", 0, loaded.Files, map[string]int{a: 2, b: 1}, tail.ManagerOptions{}, model.followParser)
+	if err != nil {
+		t.Fatalf("newDirFollower() error = %v", err)
+	}
+	defer f.stop()
+	model.follower = f
+	msgs := &followMsgs{f: f}
+
+	// New files are attached and read from the beginning.
+	c := filepath.Join(dir, "c.log")
+	if err := os.WriteFile(c, []byte(`{"msg":"c1"}`+"\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	changed, _ := msgs.next(t).(SourceChangedMsg)
+	if changed.Path != c || changed.Deleted {
+		t.Fatalf("msg = %+v, want %s attached", changed, c)
+	}
+	newModel, _ = model.Update(changed)
+	model = newModel.(Model)
+	if sources := model.followedSources(); len(sources) != 3 {
+		t.Errorf("followedSources() = %v, want 3 files", sources)
+	}
+
+	msg, _ := msgs.next(t).(NewLinesMsg)
+	if len(msg.Entries) != 1 || msg.Entries[0].Source != c || msg.Entries[0].Line != 1 {
+		t.Fatalf("Entries = %+v, want c1 from c.log", msg.Entries)
+	}
+
+	// Existing files continue where loading stopped.
+	appendFile(t, b, `{"msg":"b2"}`+"\n")
+	msg, _ = msgs.next(t).(NewLinesMsg)
+	if len(msg.Entries) != 1 || msg.Entries[0].Source != b || msg.Entries[0].Offset != 43 || msg.Entries[0].Line != 2 {
+		t.Fatalf("Entries = %+v, want b2 at offset 43, line 2 of b.log", msg.Entries)
+	}
+
+	// Deleted files are detached.
+	if err := os.Remove(a); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	changed, _ = msgs.next(t).(SourceChangedMsg)
+	if changed.Path != a || !changed.Deleted {
+		t.Errorf("msg = %+v, want %s detached", changed, a)
+	}
+}
+
+func TestFollowFinishesLineBeingWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old 1\nhal"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if loaded, _ := loadFileCmd(path, false)().(ui.FileLoadedMsg); len(loaded.Entries) != 2 || loaded.Size != 9 {
+		t.Errorf("loadFileCmd() without follow = %d entries, %d bytes; want the whole file", len(loaded.Entries), loaded.Size)
+	}
+
+	loaded, _ := loadFileCmd(path, true)().(ui.FileLoadedMsg)
+	if len(loaded.Entries) != 1 || loaded.Size != 6 {
+		t.Fatalf("loadFileCmd() = %d entries, %d bytes; want the unfinished line held back", len(loaded.Entries), loaded.Size)
+	}
+	model := NewModel(path, "kanagawa", false)
+	newModel, _ := model.Update(loaded)
+	model = newModel.(Model)
+
+	f, err := newFollower(path, loaded.Size, len(loaded.Entries), tail.ManagerOptions{}, model.followParser)
+	if err != nil {
+		t.Fatalf("newFollower() error = %v", err)
+	}
+	defer f.stop()
+	msgs := &followMsgs{f: f}
+
+	appendFile(t, path, "f\n")
+	msg, _ := msgs.next(t).(NewLinesMsg)
+	if len(msg.Entries) != 1 || msg.Entries[0].Raw != "half" || msg.Entries[0].Offset != 6 || msg.Entries[0].Line != 2 {
+		t.Fatalf("Entries = %+v, want half at offset 6, line 2", msg.Entries)
+	}
+}
+
+func TestFollowBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old 1\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	loaded, _ := loadFileCmd(path, true)().(ui.FileLoadedMsg)
+	model := NewModel(path, "kanagawa", false)
+	newModel, _ := model.Update(loaded)
+	model = newModel.(Model)
+
+	f, err := newFollower(path, loaded.Size, len(loaded.Entries), tail.ManagerOptions{MaxBatchRate: 5}, model.followParser)
+	if err != nil {
+		t.Fatalf("newFollower() error = %v", err)
+	}
+	defer f.stop()
+	model.follower = f
+
+	// Lines written between batches arrive together.
+	appendFile(t, path, "new 1\n")
+	appendFile(t, path, "new 2\n")
+	appendFile(t, path, "new 3\n")
+	var batches []FollowBatchMsg
+	for lines := 0; lines < 3; {
+		batch := nextFollowBatch(t, f)
+		for _, msg := range batch.Msgs {
+			lines += len(msg.(NewLinesMsg).Entries)
+		}
+		batches = append(batches, batch)
+	}
+	for _, batch := range batches {
+		newModel, _ = model.Update(batch)
+		model = newModel.(Model)
+	}
+	if len(model.entries) != 4 {
+		t.Errorf("entries = %d, want 4", len(model.entries))
+	}
+	if sources := batches[0].Sources; len(sources) != 1 || sources[0].Path != path || sources[0].Status != tail.TailStatusRunning {
+		t.Errorf("Sources = %+v, want %s running", sources, path)
+	}
+
+	newModel, _ = model.Update(FollowBatchMsg{Dropped: 7, from: f})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	model = newModel.(Model)
+	if view := model.View(); !strings.Contains(view, "app.log") || !strings.Contains(view, "running") {
+		t.Errorf("View() = %q, want the sources panel listing app.log", view)
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model = newModel.(Model); model.sourcesPanel.IsVisible() {
+		t.Error("sources panel still visible after esc")
+	}
+
+	// Turning follow mode off pauses the follower.
+	model.followMode = true
+	newModel, _ = model.Update(ui.ToggleFollowMsg{})
+	model = newModel.(Model)
+	if status := f.manager.Statuses()[0].Status; status != tail.TailStatusPaused {
+		t.Errorf("status = %v after turning follow mode off, want paused", status)
+	}
+}
+
+func TestFollowPausesWhenScrolledUp(t *testing.T) {
+	model := NewModel("", "kanagawa", true)
+	if err := model.SetFilter(`.service == "auth"`, logentry.Unknown); err != nil {
+		t.Fatalf("SetFilter() error = %v", err)
+	}
+	entries := make([]logentry.Entry, 5)
+	for i := range entries {
+		entries[i] = logentry.Entry{Message: fmt.Sprintf("auth %d", i), Fields: map[string]any{"service": "auth"}, Offset: int64(i)}
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+
+	appendLines := func(services ...string) {
+		t.Helper()
+		var lines []logentry.Entry
+		for _, service := range services {
+			lines = append(lines, logentry.Entry{Message: service, Fields: map[string]any{"service": service}})
+		}
+		newModel, _ := model.Update(NewLinesMsg{Entries: lines})
+		model = newModel.(Model)
+	}
+
+	// At the end, new entries scroll into view.
+	model.logView.ScrollToBottom()
+	appendLines("auth")
+	if _, selected := model.logView.GetSelected(); selected != 5 || model.newEntries != 0 {
+		t.Fatalf("selected = %d, newEntries = %d; want 5 and 0 when following the end", selected, model.newEntries)
+	}
+
+	// Scrolled up, the view stays put and only matching entries are counted.
+	model.logView.ScrollToTop()
+	model.updateSelectedEntry()
+	appendLines("auth", "api", "auth")
+	if _, selected := model.logView.GetSelected(); selected != 0 {
+		t.Errorf("selected = %d, want 0 while scrolled up", selected)
+	}
+	if model.newEntries != 2 {
+		t.Errorf("newEntries = %d, want 2 matching entries", model.newEntries)
+	}
+
+	// G jumps to the end and resumes auto-scroll.
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	model = newModel.(Model)
+	if _, selected := model.logView.GetSelected(); selected != len(model.filtered)-1 || model.newEntries != 0 {
+		t.Errorf("selected = %d, newEntries = %d after G; want the last entry and 0", selected, model.newEntries)
+	}
+	appendLines("auth")
+	if _, selected := model.logView.GetSelected(); selected != len(model.filtered)-1 {
+		t.Errorf("selected = %d, want the new last entry after resuming", selected)
+	}
+}
+
+func TestFollowKeepsSortOrder(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entry := func(i, status int) logentry.Entry {
+		return logentry.Entry{
+			Message: fmt.Sprintf("m%d", i), Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Fields: map[string]any{"status": status}, Offset: int64(i),
+		}
+	}
+	model := NewModel("", "kanagawa", true)
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: []logentry.Entry{entry(0, 100), entry(1, 200), entry(2, 300)}})
+	model = newModel.(Model)
+
+	// Newest first, new entries arrive at the top.
+	model.toggleSort()
+	newModel, _ = model.Update(NewLinesMsg{Entries: []logentry.Entry{entry(3, 150)}})
+	model = newModel.(Model)
+	if model.filtered[0].Message != "m3" {
+		t.Errorf("filtered[0] = %s, want the new entry first in descending order", model.filtered[0].Message)
+	}
+
+	// Sorted by a column, new entries go where they sort.
+	model.sortColumn, model.sortOrder = "status", SortAsc
+	model.applySort()
+	newModel, _ = model.Update(NewLinesMsg{Entries: []logentry.Entry{entry(4, 250)}})
+	model = newModel.(Model)
+	var got []string
+	for _, e := range model.filtered {
+		got = append(got, e.Message)
+	}
+	if fmt.Sprint(got) != "[m0 m3 m1 m4 m2]" {
+		t.Errorf("filtered = %v, want entries sorted by status", got)
+	}
+}
+
+func TestFollowDescending(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entry := func(i int) logentry.Entry {
+		return logentry.Entry{Message: fmt.Sprintf("m%d", i), Timestamp: base.Add(time.Duration(i) * time.Minute), Offset: int64(i)}
+	}
+	model := NewModel("", "kanagawa", true)
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: []logentry.Entry{entry(0), entry(1), entry(2)}})
+	model = newModel.(Model)
+	model.toggleSort()
+
+	// Newest first, the top is where entries arrive and are followed.
+	model.logView.ScrollToTop()
+	model.updateSelectedEntry()
+	newModel, _ = model.Update(NewLinesMsg{Entries: []logentry.Entry{entry(3)}})
+	model = newModel.(Model)
+	if e, selected := model.logView.GetSelected(); selected != 0 || e.Message != "m3" || model.newEntries != 0 {
+		t.Errorf("selected = %s at %d, newEntries = %d; want the new entry at the top and 0", e.Message, selected, model.newEntries)
+	}
+
+	// Scrolled down to older entries, new ones are counted instead.
+	model.logView.ScrollToBottom()
+	model.updateSelectedEntry()
+	newModel, _ = model.Update(NewLinesMsg{Entries: []logentry.Entry{entry(4)}})
+	model = newModel.(Model)
+	if e, _ := model.logView.GetSelected(); e.Message != "m0" || model.newEntries != 1 {
+		t.Errorf("selected = %s, newEntries = %d; want m0 kept and 1 new entry", e.Message, model.newEntries)
+	}
+
+	// Going back to the top resumes following.
+	model.logView.ScrollToTop()
+	model.updateSelectedEntry()
+	if model.newEntries != 0 {
+		t.Errorf("newEntries = %d at the top, want 0", model.newEntries)
+	}
+}
+
+func TestResolveGoto(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	model := NewModel("", "kanagawa", false)
+	for i := 0; i < 11; i++ {
+		model.entries = append(model.entries, logentry.Entry{
+			Level: logentry.Info, Message: fmt.Sprintf("m%d", i), Line: i*2 + 1,
+			Timestamp: base.Add(time.Duration(i) * time.Minute), Offset: int64(i),
+		})
+	}
+	// An entry without a timestamp.
+	model.entries = append(model.entries, logentry.Entry{Message: "plain", Line: 23, Offset: 11})
+	model.refilter()
+	model.selectedEntry = model.filtered[0]
+
+	tests := []struct {
+		input   string
+		sort    SortOrder
+		want    int
+		wantErr bool
+	}{
+		{input: "1", want: 0},
+		{input: "8", want: 3},
+		{input: ":21", want: 10},
+		{input: "1000", want: 11},
+		{input: "50%", want: 5},
+		{input: "100%", want: 11},
+		{input: "150%", wantErr: true},
+		{input: "10:04:20", want: 4},
+		{input: "10:04:40", want: 5},
+		{input: "@2024-01-15T10:07", want: 7},
+		{input: "@2024-01-15 09:00", want: 0},
+		{input: "10:07", sort: SortDesc, want: 3},
+		{input: "09:00", sort: SortDesc, want: 10},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m := model
+			m.filtered = append([]logentry.Entry(nil), model.filtered...)
+			m.timeSorted = false
+			if tt.sort == SortDesc {
+				m.sortOrder = SortDesc
+				m.sortFiltered()
+			}
+			got, err := m.resolveGoto(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveGoto(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("resolveGoto(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	// Binary search and a linear scan agree.
+	sorted := model
+	sorted.filtered = append([]logentry.Entry(nil), model.filtered...)
+	sorted.sortFiltered()
+	for _, input := range []string{"10:03:29", "10:03:31", "09:00", "11:00"} {
+		want, _ := model.resolveGoto(input)
+		got, err := sorted.resolveGoto(input)
+		if err != nil || sorted.filtered[got].Offset != model.filtered[want].Offset {
+			t.Errorf("sorted resolveGoto(%q) = %d (%v), want entry at unsorted row %d", input, got, err, want)
+		}
+	}
+}
+
+func TestTimeSortedOnLoad(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var entries []logentry.Entry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, logentry.Entry{
+			Level: logentry.Info, Message: fmt.Sprintf("m%d", i), Line: i + 1,
+			Timestamp: base.Add(time.Duration(i) * time.Minute), Offset: int64(i),
+		})
+	}
+
+	model := NewModel("", "kanagawa", false)
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	if !model.timeSorted {
+		t.Error("entries loaded in time order should be time sorted")
+	}
+
+	entries[1].Timestamp, entries[3].Timestamp = entries[3].Timestamp, entries[1].Timestamp
+	model = NewModel("", "kanagawa", false)
+	newModel, _ = model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	if model.timeSorted {
+		t.Error("entries loaded out of time order should not be time sorted")
+	}
+	if got, err := model.resolveGoto("@2024-01-15T10:03"); err != nil || model.filtered[got].Message != "m1" {
+		t.Errorf("resolveGoto(@2024-01-15T10:03) = %d (%v), want the entry at 10:03", got, err)
+	}
+}
+
+func TestGotoPrompt(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	var entries []logentry.Entry
+	for i := 0; i < 100; i++ {
+		entries = append(entries, logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("m%d", i), Line: i + 1, Offset: int64(i)})
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	model = newModel.(Model)
+	if !model.gotoPrompt.IsVisible() {
+		t.Fatal("':' should open the goto prompt")
+	}
+	for _, r := range "42" {
+		newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = newModel.(Model)
+	}
+	if got := model.gotoPreview(model.gotoPrompt.Value()); got.Row != 41 || got.Entry.Message != "m41" {
+		t.Errorf("preview = %+v, want row 41", got)
+	}
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+
+	newModel, cmd := model.Update(ui.GotoSubmitMsg{Input: "42"})
+	model = newModel.(Model)
+	msg, ok := cmd().(ui.ScrollToLineMsg)
+	if !ok || msg.Line != 41 {
+		t.Fatalf("GotoSubmitMsg command = %#v, want ScrollToLineMsg{41}", msg)
+	}
+	newModel, _ = model.Update(msg)
+	model = newModel.(Model)
+	if model.gotoPrompt.IsVisible() {
+		t.Error("Enter should close the goto prompt")
+	}
+	if got := model.selectedEntry.Message; got != "m41" {
+		t.Errorf("selected %q after :42, want m41", got)
+	}
+}
+
+func TestViewFillsScreen(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	var entries []logentry.Entry
+	for i := 0; i < 100; i++ {
+		entries = append(entries, logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("entry %d", i), Offset: int64(i),
+			Fields: map[string]any{"service": "api", "status": 200.0, "path": "/", "user": "ada"}})
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	model = newModel.(Model)
+
+	// The panes get every row above the status bar, and the log view
+	// scrolls against the height it is drawn with.
+	for _, inspector := range []bool{false, true} {
+		if inspector {
+			model.toggleSidebar()
+		}
+		if got := lipgloss.Height(model.View()); got != 30 {
+			t.Errorf("inspector %v: View() is %d rows, want 30", inspector, got)
+		}
+		if _, height := model.logView.GetSize(); height != 30-statusBarHeight {
+			t.Errorf("inspector %v: log view height = %d, want %d", inspector, height, 30-statusBarHeight)
+		}
+	}
+
+	// The filter bar, with its completion popup open, takes rows from the
+	// panes instead of pushing the status bar off screen.
+	model.toggleSidebar()
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'f'}}, {Type: tea.KeyRunes, Runes: []rune{'.'}}} {
+		newModel, _ = model.Update(msg)
+		model = newModel.(Model)
+	}
+	bars := model.barsHeight()
+	if bars < 3 {
+		t.Fatalf("barsHeight() = %d, want the filter bar and its popup", bars)
+	}
+	if got := lipgloss.Height(model.View()); got != 30 {
+		t.Errorf("with the filter popup View() is %d rows, want 30", got)
+	}
+	if _, height := model.logView.GetSize(); height != 30-statusBarHeight-bars {
+		t.Errorf("with the filter popup log view height = %d, want %d", height, 30-statusBarHeight-bars)
+	}
+}
+
+func TestSplitPanes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	model := NewModel("", "kanagawa", false)
+	model.SetConfigPath(configPath)
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "first", Raw: "first", Fields: map[string]any{"user": map[string]any{"id": 7.0, "name": "ada"}, "status": 200.0}},
+		{Level: logentry.Warn, Message: "second", Raw: "second", Fields: map[string]any{"status": 500.0}},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	model = newModel.(Model)
+	newModel, _ = model.Update(ui.ScrollToTopMsg{})
+	model = newModel.(Model)
+
+	key := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}} }
+
+	// Tab opens the inspector and focuses it.
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = newModel.(Model)
+	if !model.sidebar.IsVisible() || !model.sidebar.IsFocused() {
+		t.Fatal("Tab should show and focus the inspector")
+	}
+	if width, _ := model.logView.GetSize(); width >= 120 {
+		t.Errorf("log view width = %d, want room for the inspector", width)
+	}
+	if view := model.View(); !strings.Contains(view, "+ user") {
+		t.Errorf("View() = %q, want the inspector with the user field", view)
+	}
+
+	// j/k and Enter move in the tree instead of the log.
+	for _, msg := range []tea.KeyMsg{key('j'), {Type: tea.KeyEnter}} {
+		newModel, _ = model.Update(msg)
+		model = newModel.(Model)
+	}
+	if _, selected := model.logView.GetSelected(); selected != 0 {
+		t.Errorf("log selection = %d, want it unchanged while the inspector is focused", selected)
+	}
+	if field := model.sidebar.SelectedField(); field == nil || field.Path != ".user" {
+		t.Errorf("SelectedField() = %+v, want .user", field)
+	}
+	if view := model.View(); !strings.Contains(view, "- user") || !strings.Contains(view, "name: ada") {
+		t.Errorf("View() = %q, want .user expanded", view)
+	}
+
+	// Esc gives focus back; j moves the log selection again.
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = newModel.(Model)
+	newModel, _ = model.Update(key('j'))
+	model = newModel.(Model)
+	if _, selected := model.logView.GetSelected(); selected != 1 || model.sidebar.IsFocused() {
+		t.Errorf("log selection = %d, want 1 after giving focus back", selected)
+	}
+
+	// > widens the inspector and remembers it.
+	newModel, cmd := model.Update(key('>'))
+	model = newModel.(Model)
+	if model.layout.Ratio() != 0.4 {
+		t.Errorf("Ratio() = %v, want 0.4", model.layout.Ratio())
+	}
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if msg != nil {
+			msg()
+		}
+	}
+	cfg, err := config.LoadFrom(configPath)
+	if err != nil || cfg.Layout.PaneRatio != 0.4 {
+		t.Errorf("saved PaneRatio = %v (%v), want 0.4", cfg.Layout.PaneRatio, err)
+	}
+
+	// i hides it again.
+	newModel, _ = model.Update(key('i'))
+	if model = newModel.(Model); model.sidebar.IsVisible() {
+		t.Error("i should hide the inspector")
+	}
+	if width, _ := model.logView.GetSize(); width != 120 {
+		t.Errorf("log view width = %d, want the full width", width)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	store := bookmark.New(filepath.Join(t.TempDir(), "bookmarks.json"))
+	model := NewModel("app.log", "kanagawa", false)
+	model.SetBookmarks(store)
+	var entries []logentry.Entry
+	for i := 0; i < 10; i++ {
+		entries = append(entries, logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("m%d", i), Raw: fmt.Sprintf("m%d", i), Line: i + 1, Source: "app.log", Offset: int64(i * 10)})
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model = newModel.(Model)
+	newModel, _ = model.Update(ui.ScrollToTopMsg{})
+	model = newModel.(Model)
+
+	keys := func(s string) {
+		t.Helper()
+		for _, r := range s {
+			newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			model = newModel.(Model)
+		}
+	}
+	selected := func() string {
+		entry, _ := model.logView.GetSelected()
+		return entry.Message
+	}
+
+	// ma on m2, anonymous bookmarks on m5 and m8.
+	keys("jjmajjjbjjjb")
+	if got := store.List(model.fileKey()); len(got) != 3 || got[0].Name != "a" || got[0].Offset != 20 {
+		t.Fatalf("bookmarks = %+v, want mark a at offset 20 and two more", got)
+	}
+	if view := model.View(); !strings.Contains(view, "a ") || !strings.Contains(view, "●") {
+		t.Errorf("View() is missing the gutter markers")
+	}
+
+	keys("gg'a")
+	if got := selected(); got != "m2" {
+		t.Errorf("'a selected %q, want m2", got)
+	}
+	for _, want := range []string{"m5", "m8", "m2"} {
+		keys("''")
+		if got := selected(); got != want {
+			t.Errorf("'' selected %q, want %q", got, want)
+		}
+	}
+
+	// b on a bookmarked entry removes it.
+	keys("''b")
+	if got := store.List(model.fileKey()); len(got) != 2 {
+		t.Errorf("bookmarks after toggling m5 = %+v, want 2", got)
+	}
+
+	keys("B")
+	if !model.bookmarksPanel.IsVisible() {
+		t.Fatal("B should show the bookmarks panel")
+	}
+	if view := model.View(); !strings.Contains(view, "m8") {
+		t.Errorf("bookmarks panel = %q, want m8 listed", view)
+	}
+
+	// Bookmarks survive a restart.
+	model.saveBookmarksCmd()()
+	loaded, err := bookmark.Open(store.Path())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := loaded.List(model.fileKey()); len(got) != 2 || got[0].Name != "a" || got[1].Message != "m8" {
+		t.Errorf("saved bookmarks = %+v, want mark a and m8", got)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	t.Setenv("SSH_TTY", "")
+	t.Setenv("SSH_CONNECTION", "")
+	var copied string
+	model := NewModel("", "kanagawa", false)
+	model.SetClipboard(clipboard.New(io.Discard, func(text string) error {
+		copied = text
+		return nil
+	}))
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "first", Raw: `{"msg":"first","user":{"id":7}}`, Fields: map[string]any{"msg": "first", "user": map[string]any{"id": 7.0}}},
+		{Level: logentry.Warn, Message: "second", Raw: "WARN second"},
+		{Level: logentry.Error, Message: "third", Raw: "ERROR third"},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	model = newModel.(Model)
+	newModel, _ = model.Update(ui.ScrollToTopMsg{})
+	model = newModel.(Model)
+
+	// press sends keys and runs the copy they start, returning its result.
+	press := func(keys ...tea.KeyMsg) ui.CopiedMsg {
+		t.Helper()
+		var cmd tea.Cmd
+		for _, key := range keys {
+			var newModel tea.Model
+			newModel, cmd = model.Update(key)
+			model = newModel.(Model)
+		}
+		batch, ok := cmd().(tea.BatchMsg)
+		if !ok {
+			t.Fatal("no copy was started")
+		}
+		for _, c := range batch {
+			if c == nil {
+				continue
+			}
+			if msg, ok := c().(ui.CopiedMsg); ok {
+				newModel, _ := model.Update(msg)
+				model = newModel.(Model)
+				return msg
+			}
+		}
+		t.Fatal("no copy was started")
+		return ui.CopiedMsg{}
+	}
+	key := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}} }
+
+	if msg := press(key('y')); msg.What != "entry" || copied != entries[0].Raw {
+		t.Errorf("y copied %q (%+v), want the raw entry", copied, msg)
+	}
+	if !strings.Contains(model.View(), "Copied entry to clipboard") {
+		t.Error("status bar does not confirm the copy")
+	}
+
+	press(key('j'), key('Y'))
+	if want := "{\n  \"level\": \"WARN\",\n  \"message\": \"second\"\n}"; copied != want {
+		t.Errorf("Y copied %q, want %q", copied, want)
+	}
+
+	// V selects a range; y copies it and ends the selection.
+	if msg := press(key('V'), key('j'), key('y')); msg.What != "2 entries" || copied != "WARN second\nERROR third" {
+		t.Errorf("V j y copied %q (%+v), want the last two entries", copied, msg)
+	}
+	if _, _, ok := model.logView.VisualRange(); ok {
+		t.Error("range still selected after copying it")
+	}
+
+	// In the inspector, y copies the field and Y its path.
+	newModel, _ = model.Update(ui.ScrollToTopMsg{})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = newModel.(Model)
+	press(key('y'))
+	if copied != "first" {
+		t.Errorf("y in the inspector copied %q, want the msg field", copied)
+	}
+	press(key('j'), key('y'))
+	if copied != "{\n  \"id\": 7\n}" {
+		t.Errorf("y in the inspector copied %q, want the user object as JSON", copied)
+	}
+	press(key('Y'))
+	if copied != ".user" {
+		t.Errorf("Y in the inspector copied %q, want .user", copied)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	store := columns.NewStore(filepath.Join(t.TempDir(), "columns.json"))
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "ok", Source: "api.log", Offset: 0, Fields: map[string]any{"status": 200.0, "path": "/users"}},
+		{Level: logentry.Error, Message: "failed", Source: "api.log", Offset: 10, Fields: map[string]any{"status": 503.0, "path": "/orders"}},
+		{Level: logentry.Warn, Message: "missing", Source: "api.log", Offset: 20, Fields: map[string]any{"status": 404.0, "path": "/items"}},
+	}
+	open := func() Model {
+		model := NewModel("api.log", "kanagawa", false)
+		model.SetColumns([]string{"ts", "level", "msg"})
+		model.SetLayouts(store)
+		newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+		model = newModel.(Model)
+		newModel, _ = model.Update(tea.WindowSizeMsg{Width: 140, Height: 30})
+		model = newModel.(Model)
+		newModel, _ = model.Update(ui.ScrollToTopMsg{})
+		return newModel.(Model)
+	}
+	model := open()
+	press := func(keys ...tea.KeyMsg) {
+		t.Helper()
+		for _, key := range keys {
+			newModel, _ := model.Update(key)
+			model = newModel.(Model)
+		}
+	}
+	key := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}} }
+	statuses := func() []string {
+		var got []string
+		for _, entry := range model.filtered {
+			got = append(got, columns.Text(entry, "status"))
+		}
+		return got
+	}
+
+	if got := model.logView.Columns(); !slices.Equal(got, []string{"ts", "level", "msg"}) {
+		t.Errorf("Columns() = %q, want the configured ones", got)
+	}
+
+	// c in the inspector pins the selected field before the message.
+	press(tea.KeyMsg{Type: tea.KeyTab}, key('j'), key('c'), tea.KeyMsg{Type: tea.KeyEsc})
+	if got, want := model.logView.Columns(), []string{"ts", "level", "status", "msg"}; !slices.Equal(got, want) {
+		t.Errorf("Columns() after pinning = %q, want %q", got, want)
+	}
+	if view := model.View(); !strings.Contains(view, "status") || !strings.Contains(view, "503") {
+		t.Errorf("View() = %q, want the status column", view)
+	}
+
+	// s moves the sort through the columns; r reverses it.
+	press(key('s'), key('s'), key('s'), key('r'))
+	if got, want := statuses(), []string{"503", "404", "200"}; !slices.Equal(got, want) {
+		t.Errorf("statuses sorted by status descending = %q, want %q", got, want)
+	}
+
+	// C switches back to the usual view and keeps the columns.
+	press(key('C'))
+	if got := model.logView.Columns(); len(got) != 0 {
+		t.Errorf("Columns() after C = %q, want none", got)
+	}
+	press(key('C'))
+
+	// The layout is saved for the file and restored when it is opened again.
+	model.saveColumnsCmd()()
+	reloaded, err := columns.OpenStore(store.Path())
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	if layout, _ := reloaded.Get(model.fileKey()); layout.SortBy != "status" || !layout.Desc || len(layout.Columns) != 4 {
+		t.Errorf("saved layout = %+v, want 4 columns sorted by status descending", layout)
+	}
+	model = open()
+	if got := model.logView.Columns(); len(got) != 4 || !slices.Equal(statuses(), []string{"503", "404", "200"}) {
+		t.Errorf("reopened with columns %q and statuses %q, want the saved layout", got, statuses())
+	}
+
+	// --columns replaces the saved layout.
+	model.SetFileColumns([]string{"path"})
+	if got := model.logView.Columns(); !slices.Equal(got, []string{"path"}) {
+		t.Errorf("Columns() = %q, want the --columns ones", got)
+	}
+}
+
+func TestMouse(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	model := NewModel("", "kanagawa", false)
+	model.SetConfigPath(configPath)
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "first", Raw: "first", Fields: map[string]any{"service": "api", "status": 200.0}},
+		{Level: logentry.Warn, Message: "second", Raw: "second", Fields: map[string]any{"service": "auth", "status": 500.0}},
+		{Level: logentry.Error, Message: "third", Raw: "third", Fields: map[string]any{"service": "api", "status": 503.0}},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	newModel, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	model = newModel.(Model)
+	newModel, _ = model.Update(ui.ScrollToTopMsg{})
+	model = newModel.(Model)
+
+	mouse := func(x, y int, button tea.MouseButton, action tea.MouseAction) tea.Cmd {
+		t.Helper()
+		newModel, cmd := model.Update(tea.MouseMsg{X: x, Y: y, Button: button, Action: action})
+		model = newModel.(Model)
+		return cmd
+	}
+	selected := func() int {
+		_, index := model.logView.GetSelected()
+		return index
+	}
+
+	// A click selects the entry under it and the wheel scrolls.
+	mouse(10, 1, tea.MouseButtonLeft, tea.MouseActionPress)
+	if got := selected(); got != 1 {
+		t.Errorf("selected = %d after clicking row 1, want 1", got)
+	}
+	mouse(10, 1, tea.MouseButtonWheelUp, tea.MouseActionPress)
+	if got := selected(); got != 0 {
+		t.Errorf("selected = %d after scrolling up, want 0", got)
+	}
+
+	// A double click opens the entry.
+	mouse(10, 2, tea.MouseButtonLeft, tea.MouseActionPress)
+	mouse(10, 2, tea.MouseButtonLeft, tea.MouseActionPress)
+	if !model.logDetail.IsVisible() || model.selectedEntry.Message != "third" {
+		t.Errorf("double click opened %q (visible %v), want the third entry's details", model.selectedEntry.Message, model.logDetail.IsVisible())
+	}
+	model.logDetail.Hide()
+
+	// Clicking a field in the inspector filters on it; the inspector is
+	// right of the 78 cells wide log view and its fields start on row 3.
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	model = newModel.(Model)
+	mouse(100, 3, tea.MouseButtonLeft, tea.MouseActionPress)
+	if got := model.pipeline.Expression(); got != `.service == "api"` {
+		t.Errorf("Expression() = %q after clicking .service, want it filtered on", got)
+	}
+	if len(model.filtered) != 2 {
+		t.Errorf("filtered = %d entries, want the 2 from api", len(model.filtered))
+	}
+	mouse(100, 4, tea.MouseButtonLeft, tea.MouseActionPress)
+	if got, want := model.pipeline.Expression(), `.service == "api" and .status == 503`; got != want {
+		t.Errorf("Expression() = %q after clicking .status, want %q", got, want)
+	}
+
+	// Dragging the border resizes the panes and remembers the size.
+	mouse(78, 5, tea.MouseButtonLeft, tea.MouseActionPress)
+	mouse(60, 5, tea.MouseButtonLeft, tea.MouseActionMotion)
+	if model.layout.Ratio() != 0.5 {
+		t.Errorf("Ratio() = %v after dragging the border to the middle, want 0.5", model.layout.Ratio())
+	}
+	for _, msg := range mouse(60, 5, tea.MouseButtonLeft, tea.MouseActionRelease)().(tea.BatchMsg) {
+		if msg != nil {
+			msg()
+		}
+	}
+	cfg, err := config.LoadFrom(configPath)
+	if err != nil || cfg.Layout.PaneRatio != 0.5 {
+		t.Errorf("saved PaneRatio = %v (%v), want 0.5", cfg.Layout.PaneRatio, err)
+	}
+}
+
+func TestCommandPalette(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: []logentry.Entry{{Level: logentry.Info, Message: "m0", Line: 1}}})
+	model = newModel.(Model)
+
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	model = newModel.(Model)
+	if !model.palette.IsVisible() {
+		t.Fatal("ctrl+p should open the command palette")
+	}
+	for _, r := range "line wrap" {
+		newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = newModel.(Model)
+	}
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	if model.palette.IsVisible() {
+		t.Fatal("Enter should close the command palette")
+	}
+	var run tea.Msg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(ui.PaletteRunMsg); ok {
+			run = msg
+		}
+	}
+	if run != (ui.PaletteRunMsg{ID: "action:toggle-wrap"}) {
+		t.Fatalf("Enter ran %#v, want toggle-wrap", run)
+	}
+	newModel, _ = model.Update(run)
+	model = newModel.(Model)
+	if !model.logView.IsWrapped() {
+		t.Error("running toggle-wrap should wrap lines")
+	}
+
+	newModel, _ = model.Update(ui.PaletteRunMsg{ID: "theme:nord"})
+	model = newModel.(Model)
+	if got := model.theme.Name(); got != "nord" {
+		t.Errorf("theme = %q after switching, want nord", got)
+	}
+
+	// ':' followed by a letter is a command, and ':' in the palette goes
+	// back to the goto prompt.
+	for _, r := range ":w" {
+		newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = newModel.(Model)
+	}
+	if !model.palette.IsVisible() || model.gotoPrompt.IsVisible() || model.palette.Value() != "w" {
+		t.Fatalf("':w' should open the palette with %q, got visible=%v value=%q", "w", model.palette.IsVisible(), model.palette.Value())
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	model = newModel.(Model)
+	for _, r := range ":12" {
+		newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = newModel.(Model)
+	}
+	if model.palette.IsVisible() || !model.gotoPrompt.IsVisible() || model.gotoPrompt.Value() != "12" {
+		t.Errorf("':12' in the palette should go to the goto prompt, got %q", model.gotoPrompt.Value())
+	}
+}
+
+func TestActionKeys(t *testing.T) {
+	keyMap := DefaultKeyMap()
+	seen := make(map[string]string)
+	for _, a := range actions() {
+		for _, key := range a.actionKeys(&keyMap) {
+			if other, ok := seen[key]; ok {
+				t.Errorf("key %q runs both %s and %s", key, other, a.id)
+			}
+			seen[key] = a.id
+		}
+	}
+
+	help := keyMap.FullHelp()
+	for _, want := range []string{"Navigation:", "Ctrl+P", "Command palette", "PgDn", "Toggle line wrap"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("FullHelp() is missing %q:\n%s", want, help)
+		}
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	var all []logentry.Entry
+	for i := 0; i < 12; i++ {
+		all = append(all, logentry.Entry{Message: fmt.Sprintf("m%d", i), Offset: int64(i)})
+	}
+	matches := []logentry.Entry{all[2], all[3], all[9]}
+
+	shown, context, breaks := withContext(all, matches, 1)
+	var got []string
+	for _, e := range shown {
+		got = append(got, e.Message)
+	}
+	if want := "m1,m2,m3,m4,m8,m9,m10"; strings.Join(got, ",") != want {
+		t.Errorf("shown = %s, want %s", strings.Join(got, ","), want)
+	}
+	if len(context) != 4 || !context[all[1].ID()] || context[all[2].ID()] {
+		t.Errorf("context = %v, want m1, m4, m8 and m10", context)
+	}
+	if len(breaks) != 1 || breaks[all[8].ID()] != 3 {
+		t.Errorf("breaks = %v, want 3 skipped before m8", breaks)
+	}
+
+	if shown, _, breaks := withContext(all, matches, 0); len(shown) != 3 || len(breaks) != 1 {
+		t.Errorf("withContext(0) = %d entries and %d breaks, want the matches only", len(shown), len(breaks))
+	}
+}
+
+func TestContextView(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	var entries []logentry.Entry
+	for i := 0; i < 20; i++ {
+		level := logentry.Info
+		if i == 5 || i == 15 {
+			level = logentry.Error
+		}
+		entries = append(entries, logentry.Entry{Level: level, Message: fmt.Sprintf("m%d", i), Line: i + 1, Offset: int64(i)})
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	model, _ = model.applyLevel(logentry.Error)
+	if len(model.filtered) != 2 {
+		t.Fatalf("filtered = %d entries, want the 2 errors", len(model.filtered))
+	}
+
+	press := func(key string) {
+		t.Helper()
+		newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = newModel.(Model)
+	}
+
+	model.logView.SetSelected(1)
+	model.updateSelectedEntry()
+	press("a")
+	if got := len(model.filtered); got != 14 {
+		t.Fatalf("filtered = %d entries with context, want 2 matches and 12 around them", got)
+	}
+	if model.selectedEntry.Message != "m15" {
+		t.Errorf("selected %q after turning context on, want m15 kept", model.selectedEntry.Message)
+	}
+	press("-")
+	if got := len(model.filtered); got != 10 || model.contextLines != 2 {
+		t.Errorf("filtered = %d entries with %d context lines after '-', want 10 with 2", got, model.contextLines)
+	}
+	press("+")
+	press("+")
+	if got := len(model.filtered); got != 18 {
+		t.Errorf("filtered = %d entries after '+' twice, want 18", got)
+	}
+
+	// Suspending the filter shows everything and keeps the selection.
+	press("u")
+	if len(model.filtered) != 20 || model.selectedEntry.Message != "m15" {
+		t.Errorf("suspended: %d entries, selected %q; want 20 and m15", len(model.filtered), model.selectedEntry.Message)
+	}
+	press("u")
+	if len(model.filtered) != 18 || model.selectedEntry.Message != "m15" {
+		t.Errorf("resumed: %d entries, selected %q; want 18 and m15", len(model.filtered), model.selectedEntry.Message)
+	}
+
+	press("a")
+	if len(model.filtered) != 2 || model.selectedEntry.Message != "m15" {
+		t.Errorf("context off: %d entries, selected %q; want 2 and m15", len(model.filtered), model.selectedEntry.Message)
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	model.handleResize(tea.WindowSizeMsg{Width: 120, Height: 30})
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entry := func(source string, offset int64, ms int, msg, traceID, spanID, parentID string) logentry.Entry {
+		fields := map[string]any{"trace_id": traceID, "span_id": spanID}
+		if parentID != "" {
+			fields["parent_span_id"] = parentID
+		}
+		return logentry.Entry{Level: logentry.Info, Message: msg, Timestamp: base.Add(time.Duration(ms) * time.Millisecond),
+			Fields: fields, Source: source, Offset: offset}
+	}
+	entries := []logentry.Entry{
+		entry("api.log", 0, 0, "GET /checkout", "t1", "root", ""),
+		entry("api.log", 1, 50, "GET /health", "t2", "root", ""),
+		entry("api.log", 2, 120, "200 OK", "t1", "root", ""),
+		entry("db.log", 0, 20, "query orders", "t1", "db", "root"),
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+	model, _ = model.applyFilter(`.message == "GET /checkout"`)
+
+	press := func(key string) {
+		t.Helper()
+		newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = newModel.(Model)
+	}
+
+	press("T")
+	var got []string
+	for _, e := range model.filtered {
+		got = append(got, e.Message)
+	}
+	if want := []string{"GET /checkout", "query orders", "200 OK"}; !slices.Equal(got, want) {
+		t.Fatalf("pivot = %q, want the t1 entries of both files in time order", got)
+	}
+	if view := model.logView.View(); !strings.Contains(view, "+0.020s") || !strings.Contains(view, "+0.120s") {
+		t.Errorf("log view doesn't show offsets from the first entry:\n%s", view)
+	}
+
+	press("W")
+	if !model.tracePanel.IsVisible() || len(model.traceSpans) != 2 {
+		t.Fatalf("call tree visible %v with %d spans, want root and db", model.tracePanel.IsVisible(), len(model.traceSpans))
+	}
+	press("j")
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+	jump := cmd().(tea.BatchMsg)[0]
+	newModel, _ = model.Update(jump())
+	model = newModel.(Model)
+	if model.selectedEntry.Message != "query orders" {
+		t.Errorf("selected %q after jumping to the db span, want query orders", model.selectedEntry.Message)
+	}
+
+	// Pressing T again goes back to the filter from before.
+	press("T")
+	if len(model.filtered) != 1 || model.pivot != nil {
+		t.Errorf("left pivot: %d entries, want the 1 from the previous filter", len(model.filtered))
+	}
+	if strings.Contains(model.logView.View(), "+0.") {
+		t.Error("log view still shows offsets after leaving the pivot")
+	}
+
+	model.SetCorrelationKeys([]string{"user_id"})
+	press("T")
+	if model.pivot != nil {
+		t.Error("pivoted on an entry without the configured keys")
+	}
+}
+
+func TestRelativeTimeAndMarkers(t *testing.T) {
+	model := NewModel("", "kanagawa", false)
+	model.handleResize(tea.WindowSizeMsg{Width: 120, Height: 30})
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []logentry.Entry{
+		{Level: logentry.Info, Message: "start", Timestamp: base, Line: 1, Offset: 0},
+		{Level: logentry.Info, Message: "retry", Timestamp: base.Add(250 * time.Millisecond), Line: 2, Offset: 1},
+		{Level: logentry.Info, Message: "recovered", Timestamp: base.Add(time.Minute), Line: 3, Offset: 2},
+	}
+	newModel, _ := model.Update(ui.FileLoadedMsg{Entries: entries})
+	model = newModel.(Model)
+
+	press := func(key string) {
+		t.Helper()
+		newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = newModel.(Model)
+	}
+
+	if !strings.Contains(model.logView.View(), "1m0s gap") {
+		t.Error("log view doesn't mark the minute without entries")
+	}
+	press("z")
+	if strings.Contains(model.logView.View(), "gap") {
+		t.Error("z didn't turn the markers off")
+	}
+
+	press("e")
+	if view := model.logView.View(); !strings.Contains(view, "+0.250s") || !strings.Contains(view, "+59.750s") {
+		t.Errorf("relative time since the previous entry missing:\n%s", view)
+	}
+
+	// The next press pins the selected entry, as none is pinned yet.
+	model.logView.SetSelected(1)
+	model.updateSelectedEntry()
+	press("e")
+	if model.relativeTime != relativePinned || model.pinned == nil || model.pinned.Line != 2 {
+		t.Fatalf("relative time %v pinned %v, want line 2 pinned", model.relativeTime, model.pinned)
+	}
+	if view := model.logView.View(); !strings.Contains(view, "-0.250s") || !strings.Contains(view, "+59.750s") {
+		t.Errorf("relative time since the pinned entry missing:\n%s", view)
+	}
+
+	press("E")
+	if model.pinned != nil || strings.Contains(model.logView.View(), "+59.750s") {
+		t.Error("E on the pinned entry should unpin it and hide the column")
+	}
+}
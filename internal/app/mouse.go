@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/ui"
+)
+
+// doubleClickTime is how soon a second click on the same entry opens it.
+const doubleClickTime = 400 * time.Millisecond
+
+// handleMouse scrolls what is under the pointer with the wheel, selects
+// what is clicked and resizes the panes when their border is dragged.
+func (m Model) handleMouse(msg tea.MouseMsg) (Model, tea.Cmd) {
+	switch {
+	case m.filePicker.IsVisible():
+		m.filePicker.Update(msg)
+		return m, tickCmd()
+	case m.presetPicker.IsVisible(), m.sourcesPanel.IsVisible(), m.bookmarksPanel.IsVisible(), m.tracePanel.IsVisible(),
+		m.gotoPrompt.IsVisible(), m.palette.IsVisible(), m.loading:
+		return m, nil
+	case m.help.IsVisible():
+		m.help, _ = m.help.Update(msg)
+		return m, tickCmd()
+	case m.dashboard.IsVisible():
+		return m, nil
+	case m.logDetail.IsVisible():
+		m.logDetail.Update(msg)
+		return m, tickCmd()
+	}
+
+	layout := m.paneLayout()
+	width, height := layout.Size()
+	x, y := msg.X, msg.Y-m.barsHeight()
+
+	if m.dragging {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			ratio := float64(width-x) / float64(width)
+			if layout.Stacked() {
+				ratio = float64(height-y) / float64(height)
+			}
+			if m.layout.SetRatio(ratio) {
+				m.resizePanes()
+			}
+		case tea.MouseActionRelease:
+			m.dragging = false
+			if m.configPath != "" {
+				return m, tea.Batch(tickCmd(), saveLayoutCmd(m.configPath, m.layout.Ratio()))
+			}
+		}
+		return m, tickCmd()
+	}
+
+	if y < 0 || y >= height {
+		return m, nil
+	}
+	if !m.sidebar.IsVisible() {
+		return m.mouseLog(msg, y, width, height)
+	}
+
+	mainWidth, mainHeight, _, _ := layout.Split()
+	stacked := layout.Stacked()
+	onBorder := (stacked && y == mainHeight) || (!stacked && x == mainWidth)
+	if onBorder && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+		m.dragging = true
+		return m, tickCmd()
+	}
+	switch {
+	case stacked && y >= mainHeight:
+		return m.mouseSidebar(msg, y-mainHeight)
+	case !stacked && x >= mainWidth:
+		return m.mouseSidebar(msg, y)
+	}
+	return m.mouseLog(msg, y, mainWidth, mainHeight)
+}
+
+// barsHeight returns the rows the search and filter bars take above the
+// panes.
+func (m Model) barsHeight() int {
+	rows := 0
+	if m.searchBar.IsVisible() {
+		rows += lipgloss.Height(m.searchBar.View())
+	}
+	if m.filterBar.IsVisible() {
+		rows += lipgloss.Height(m.filterBar.View())
+	}
+	return rows
+}
+
+// mouseLog handles the mouse over the log view, which is width by height
+// cells: the wheel scrolls, a click selects the entry on row y and a
+// double click opens it.
+func (m Model) mouseLog(msg tea.MouseMsg, y, width, height int) (Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.logView.ScrollUp(ui.WheelStep)
+		m.updateSelectedEntry()
+		return m, tickCmd()
+	case tea.MouseButtonWheelDown:
+		m.logView.ScrollDown(ui.WheelStep)
+		m.updateSelectedEntry()
+		return m, tickCmd()
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+
+	// Find the entry as the log view is rendered, at the size of its pane.
+	view := m.logView
+	view.SetSize(width, height)
+	index := view.EntryAt(y)
+	if index < 0 {
+		return m, nil
+	}
+	m.sidebar.Blur()
+	m.logView.SetSelected(index)
+	m.updateSelectedEntry()
+
+	now := time.Now()
+	if index == m.lastClickIndex && now.Sub(m.lastClick) < doubleClickTime {
+		m.lastClick = time.Time{}
+		m.logDetail.Show(m.selectedEntry)
+		return m, tickCmd()
+	}
+	m.lastClick, m.lastClickIndex = now, index
+	return m, tickCmd()
+}
+
+// mouseSidebar handles the mouse over the field inspector: the wheel moves
+// the selection and a click on row y focuses it, expands or collapses a
+// field with children and adds any other field to the filter.
+func (m Model) mouseSidebar(msg tea.MouseMsg, y int) (Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.sidebar.Scroll(-ui.WheelStep)
+		return m, tickCmd()
+	case tea.MouseButtonWheelDown:
+		m.sidebar.Scroll(ui.WheelStep)
+		return m, tickCmd()
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+
+	m.sidebar.Focus()
+	field := m.sidebar.SelectAt(y)
+	if field == nil {
+		return m, tickCmd()
+	}
+	if !field.IsLeaf {
+		m.sidebar.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return m, tickCmd()
+	}
+	return m.filterByField(field)
+}
+
+// filterByField narrows the filter to entries with the value of field.
+func (m Model) filterByField(field *ui.TreeNode) (Model, tea.Cmd) {
+	clause, ok := filter.FieldPattern(strings.TrimPrefix(field.Path, "."), field.Value)
+	if !ok {
+		m.statusBar.SetInfo(fmt.Sprintf("Can't filter on %s", field.Path))
+		return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+	}
+	expr := filter.AndPattern(m.pipeline.Expression(), clause)
+	m.filterBar.SetValue(expr)
+	return m.applyFilter(expr)
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// relativeMode is what the relative time column measures entries from.
+type relativeMode int
+
+const (
+	// relativeOff hides the column, except in a pivot, where it measures
+	// from the first entry of the pivot.
+	relativeOff relativeMode = iota
+	relativePrevious
+	relativePinned
+)
+
+// showRelativeTime shows the relative time column of the log view for the
+// current mode.
+func (m *Model) showRelativeTime() {
+	switch {
+	case m.relativeTime == relativePrevious:
+		m.logView.SetRelativeToPrevious()
+	case m.relativeTime == relativePinned && m.pinned != nil:
+		m.logView.SetRelativeTo(m.pinned.Timestamp)
+	case m.pivot != nil:
+		m.logView.SetRelativeTo(earliest(m.filtered))
+	default:
+		m.logView.SetRelativeTo(time.Time{})
+	}
+}
+
+// cycleRelativeTime switches the relative time column from off to the time
+// since the previous entry, then to the time since the pinned entry,
+// pinning the selected one if none is, and back off.
+func (m Model) cycleRelativeTime() (Model, tea.Cmd) {
+	switch m.relativeTime {
+	case relativeOff:
+		m.relativeTime = relativePrevious
+		m.statusBar.SetInfo("Relative time: since the previous entry")
+	case relativePrevious:
+		if m.pinned == nil {
+			return m.pinReference()
+		}
+		m.relativeTime = relativePinned
+		m.statusBar.SetInfo(fmt.Sprintf("Relative time: since line %d", m.pinned.Line))
+	default:
+		m.relativeTime = relativeOff
+		m.statusBar.SetInfo("Relative time off")
+	}
+	m.showRelativeTime()
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
+
+// pinReference makes the selected entry the one the relative time column
+// measures from, or unpins it if it already is.
+func (m Model) pinReference() (Model, tea.Cmd) {
+	entry, index := m.logView.GetSelected()
+	switch {
+	case index < 0:
+		m.statusBar.SetInfo("No entry selected")
+	case entry.Timestamp.IsZero():
+		m.statusBar.SetInfo("Entry has no timestamp to measure from")
+	case m.relativeTime == relativePinned && m.pinned != nil && m.pinned.ID() == entry.ID():
+		m.unpin()
+		m.statusBar.SetInfo("Reference entry unpinned")
+	default:
+		m.pinned = &entry
+		m.relativeTime = relativePinned
+		m.statusBar.SetInfo(fmt.Sprintf("Relative time: since line %d", entry.Line))
+	}
+	m.showRelativeTime()
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
+
+// unpin drops the reference entry, turning the relative time column off if
+// it measured from it.
+func (m *Model) unpin() {
+	m.pinned = nil
+	if m.relativeTime == relativePinned {
+		m.relativeTime = relativeOff
+	}
+}
+
+// syncTimeMarkers shows the gap and burst markers in the log view while
+// they are on.
+func (m *Model) syncTimeMarkers() {
+	if m.showMarkers {
+		m.logView.SetTimeMarkers(m.gapThreshold, m.burstThreshold)
+	} else {
+		m.logView.SetTimeMarkers(0, 0)
+	}
+}
+
+// toggleTimeMarkers turns the gap and burst markers on or off.
+func (m Model) toggleTimeMarkers() (Model, tea.Cmd) {
+	m.showMarkers = !m.showMarkers
+	m.syncTimeMarkers()
+	switch {
+	case !m.showMarkers:
+		m.statusBar.SetInfo("Gap and burst markers off")
+	case m.gapThreshold <= 0 && m.burstThreshold <= 0:
+		m.statusBar.SetInfo("Gap and burst markers are turned off in the config file")
+	default:
+		m.statusBar.SetInfo(fmt.Sprintf("Gap and burst markers on: %s", markerThresholds(m.gapThreshold, m.burstThreshold)))
+	}
+	return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+}
+
+// markerThresholds describes when the gap and burst markers are shown.
+func markerThresholds(gap time.Duration, burst int) string {
+	switch {
+	case burst <= 0:
+		return fmt.Sprintf("gaps over %s", gap)
+	case gap <= 0:
+		return fmt.Sprintf("%d+ entries a second", burst)
+	}
+	return fmt.Sprintf("gaps over %s, %d+ entries a second", gap, burst)
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/trace"
+	"github.com/ersanisk/sieve/internal/ui"
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// pivot is the filter showing every entry that shares a correlation ID with
+// the entry it was made from, across all sources.
+type pivot struct {
+	// label names the ID, as key=value.
+	label string
+	// expr is the filter expression matching the ID.
+	expr string
+	// the filter before the pivot, set again when leaving it
+	prevExpr    string
+	prevLevel   logentry.Level
+	prevSince   time.Time
+	prevUntil   time.Time
+	prevSources []string
+}
+
+// correlate shows every entry sharing a correlation ID with the selected
+// entry, ordered by time with offsets from the first. The level, time window
+// and source selection are set aside so the whole request is shown; it
+// leaves the pivot instead if one is shown.
+func (m Model) correlate() (Model, tea.Cmd) {
+	if m.pivot != nil {
+		return m.leavePivot()
+	}
+	entry, index := m.logView.GetSelected()
+	if index < 0 {
+		m.statusBar.SetInfo("No entry selected")
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+	key, value, ok := trace.KeyOf(entry, m.correlationKeys)
+	if !ok {
+		m.statusBar.SetInfo(fmt.Sprintf("Entry has no %s", strings.Join(m.correlationKeys, ", ")))
+		return m, tea.Batch(tickCmd(), clearInfoCmd(2*time.Second))
+	}
+	clause, ok := filter.FieldPattern(key, value)
+	if !ok {
+		m.statusBar.SetError(fmt.Sprintf("Can't filter on %s", key))
+		return m, tea.Batch(tickCmd(), clearInfoCmd(3*time.Second))
+	}
+
+	since, until := m.pipeline.TimeRange()
+	p := &pivot{
+		label:       fmt.Sprintf("%s=%v", key, value),
+		prevExpr:    m.pipeline.Expression(),
+		prevLevel:   m.pipeline.Level(),
+		prevSince:   since,
+		prevUntil:   until,
+		prevSources: m.pipeline.Sources(),
+	}
+	m.pipeline.Clear()
+	if err := m.pipeline.SetExpression(clause); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Filter error: %v", err))
+		return m, tickCmd()
+	}
+	p.expr = m.pipeline.Expression()
+	m.pivot = p
+	m.filterBar.SetValue(p.expr)
+	m.keepSelection()
+
+	if cmd := m.refilter(); cmd != nil {
+		return m, tea.Batch(tickCmd(), cmd)
+	}
+	m.updateSelectedEntry()
+	m.statusBar.SetInfo(fmt.Sprintf("%s: %d entries over %s — T to go back", p.label, len(m.filtered), timeSpan(m.filtered)))
+	return m, tickCmd()
+}
+
+// leavePivot sets the filter from before the pivot again.
+func (m Model) leavePivot() (Model, tea.Cmd) {
+	p := m.pivot
+	m.pivot = nil
+	m.pipeline.SetLevel(p.prevLevel)
+	m.pipeline.SetTimeRange(p.prevSince, p.prevUntil)
+	m.pipeline.SetSources(p.prevSources...)
+	m.filterBar.SetValue(p.prevExpr)
+	m.keepSelection()
+	m, cmd := m.applyFilter(p.prevExpr)
+	if m.filterJob == nil {
+		m.statusBar.SetInfo(fmt.Sprintf("Left %s — %d/%d entries", p.label, len(m.filtered), len(m.entries)))
+	}
+	return m, tea.Batch(cmd, clearInfoCmd(2*time.Second))
+}
+
+// syncPivot drops the pivot once the filter has changed from it, and shows
+// the relative time column, which measures from the first entry of a pivot
+// unless set otherwise.
+func (m *Model) syncPivot() {
+	if m.pivot != nil && m.pipeline.Expression() != m.pivot.expr {
+		m.pivot = nil
+	}
+	m.showRelativeTime()
+}
+
+// showCallTree shows the spans of the pivot as a call tree, pivoting to the
+// selected entry first if needed.
+func (m Model) showCallTree() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if m.pivot == nil {
+		m, cmd = m.correlate()
+		if m.pivot == nil {
+			return m, cmd
+		}
+	}
+
+	entries, err := m.pivotEntries()
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Filter error: %v", err))
+		return m, tickCmd()
+	}
+	m.traceSpans = trace.Tree(entries)
+	origin := earliest(entries)
+	items := make([]ui.TraceSpanItem, len(m.traceSpans))
+	for i, span := range m.traceSpans {
+		items[i] = ui.TraceSpanItem{
+			Name:     span.Name,
+			Depth:    span.Depth,
+			Duration: span.Duration(),
+			Entries:  span.Entries,
+		}
+		if !span.Start.IsZero() && !origin.IsZero() {
+			items[i].Start = span.Start.Sub(origin)
+		}
+	}
+	m.tracePanel.SetSpans(m.pivot.label, items)
+	m.tracePanel.Show()
+	return m, tea.Batch(cmd, tickCmd())
+}
+
+// pivotEntries returns the loaded entries the pivot matches, whether or not
+// a filter job has got to them yet.
+func (m *Model) pivotEntries() ([]logentry.Entry, error) {
+	expr, err := filter.Parse(m.pivot.expr)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := filter.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	var entries []logentry.Entry
+	for _, entry := range m.entries {
+		if ok, _ := compiled.Evaluate(entry); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// jumpToSpan selects the first entry of a span of the call tree.
+func (m *Model) jumpToSpan(index int) tea.Cmd {
+	if index < 0 || index >= len(m.traceSpans) {
+		return nil
+	}
+	span := m.traceSpans[index]
+	pos, ok := m.positionOf(span.First)
+	if !ok {
+		m.statusBar.SetError(fmt.Sprintf("Span %s is not shown yet", span.ID))
+		return clearInfoCmd(3 * time.Second)
+	}
+	m.logView.SetSelected(pos)
+	m.updateSelectedEntry()
+	return nil
+}
+
+// earliest returns the earliest timestamp of entries, or the zero time if
+// none has one.
+func earliest(entries []logentry.Entry) time.Time {
+	var first time.Time
+	for _, entry := range entries {
+		if !entry.Timestamp.IsZero() && (first.IsZero() || entry.Timestamp.Before(first)) {
+			first = entry.Timestamp
+		}
+	}
+	return first
+}
+
+// timeSpan returns how long passed between the earliest and latest entries.
+func timeSpan(entries []logentry.Entry) time.Duration {
+	first := earliest(entries)
+	var last time.Time
+	for _, entry := range entries {
+		if entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+	}
+	if first.IsZero() {
+		return 0
+	}
+	return last.Sub(first)
+}
@@ -0,0 +1,177 @@
+package bookmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Bookmark marks an entry of a file by where its line starts, so it still
+// points at the same entry after the file is reopened, sorted or filtered.
+type Bookmark struct {
+	// Name is the letter of a named mark (a-z), or empty for an anonymous
+	// bookmark.
+	Name string `json:"name,omitempty"`
+	// Source is the file the entry was read from, relative to the
+	// bookmarked path when that is a directory; empty for the path itself.
+	Source string `json:"source,omitempty"`
+	// Offset is the byte offset of the entry's line in Source.
+	Offset int64 `json:"offset"`
+	// Line and Message describe the entry in the bookmarks panel.
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// at reports whether b marks the entry at offset in source.
+func (b Bookmark) at(source string, offset int64) bool {
+	return b.Source == source && b.Offset == offset
+}
+
+// Store holds the bookmarks of every file, persisted as JSON.
+type Store struct {
+	path  string
+	files map[string][]Bookmark
+	mu    sync.Mutex
+}
+
+// New creates an empty Store backed by the file at path. An empty path keeps
+// the bookmarks in memory only.
+func New(path string) *Store {
+	return &Store{path: path, files: make(map[string][]Bookmark)}
+}
+
+// Open creates a Store and loads any bookmarks saved at path.
+func Open(path string) (*Store, error) {
+	s := New(path)
+	if err := s.Load(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Load reads the bookmarks file, replacing the bookmarks in memory. A
+// missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+
+	files := make(map[string][]Bookmark)
+	if err := json.Unmarshal(data, &files); err != nil {
+		return fmt.Errorf("failed to parse bookmarks: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files = files
+	return nil
+}
+
+// Save writes the bookmarks file, creating its directory if needed.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.files, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create bookmarks directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// Path returns the file the bookmarks are persisted to.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns the bookmarks of file, ordered by source and offset.
+func (s *Store) List(file string) []Bookmark {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Bookmark(nil), s.files[file]...)
+}
+
+// Named returns the bookmark of file with the given name.
+func (s *Store) Named(file, name string) (Bookmark, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.files[file] {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+// Set adds a bookmark to file. It replaces the bookmark already at its
+// entry, and a named bookmark moves the mark of the same name.
+func (s *Store) Set(file string, b Bookmark) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.files[file][:0:0]
+	for _, old := range s.files[file] {
+		if old.at(b.Source, b.Offset) || (b.Name != "" && old.Name == b.Name) {
+			continue
+		}
+		kept = append(kept, old)
+	}
+	kept = append(kept, b)
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Source != kept[j].Source {
+			return kept[i].Source < kept[j].Source
+		}
+		return kept[i].Offset < kept[j].Offset
+	})
+	s.files[file] = kept
+}
+
+// Toggle removes the bookmark at b's entry if there is one, named or not,
+// and adds b otherwise. It reports whether b was added.
+func (s *Store) Toggle(file string, b Bookmark) bool {
+	if s.Remove(file, b.Source, b.Offset) {
+		return false
+	}
+	s.Set(file, b)
+	return true
+}
+
+// Remove removes the bookmark at the entry at offset in source. It reports
+// whether there was one.
+func (s *Store) Remove(file, source string, offset int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, b := range s.files[file] {
+		if b.at(source, offset) {
+			s.files[file] = append(s.files[file][:i:i], s.files[file][i+1:]...)
+			if len(s.files[file]) == 0 {
+				delete(s.files, file)
+			}
+			return true
+		}
+	}
+	return false
+}
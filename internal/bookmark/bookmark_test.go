@@ -0,0 +1,61 @@
+package bookmark
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStore_SetAndToggle(t *testing.T) {
+	s := New("")
+	s.Set("/logs/app.log", Bookmark{Name: "a", Offset: 40, Line: 3})
+	s.Set("/logs/app.log", Bookmark{Offset: 10, Line: 2})
+
+	// Setting a named mark again moves it.
+	s.Set("/logs/app.log", Bookmark{Name: "a", Offset: 90, Line: 7})
+	if got := s.List("/logs/app.log"); len(got) != 2 || got[0].Offset != 10 || got[1].Offset != 90 {
+		t.Errorf("List() = %+v, want offsets 10 and 90", got)
+	}
+	if b, ok := s.Named("/logs/app.log", "a"); !ok || b.Line != 7 {
+		t.Errorf("Named(a) = %+v, %v; want line 7", b, ok)
+	}
+
+	// Toggling a marked entry removes its bookmark, named or not.
+	if s.Toggle("/logs/app.log", Bookmark{Offset: 90}) {
+		t.Error("Toggle() on a marked entry added a bookmark")
+	}
+	if _, ok := s.Named("/logs/app.log", "a"); ok {
+		t.Error("Named(a) still found after toggling it off")
+	}
+	if !s.Toggle("/logs/app.log", Bookmark{Offset: 50}) {
+		t.Error("Toggle() on an unmarked entry did not add a bookmark")
+	}
+	if got := s.List("/logs/other.log"); len(got) != 0 {
+		t.Errorf("List() of another file = %+v, want none", got)
+	}
+}
+
+func TestStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "bookmarks.json")
+
+	s := New(path)
+	s.Set("/logs", Bookmark{Name: "b", Source: "api/app.log", Offset: 120, Line: 4, Message: "timeout"})
+	s.Set("/logs/app.log", Bookmark{Offset: 0, Line: 1, Message: "start"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, file := range []string{"/logs", "/logs/app.log"} {
+		if got, want := loaded.List(file), s.List(file); !reflect.DeepEqual(got, want) {
+			t.Errorf("List(%s) = %+v, want %+v", file, got, want)
+		}
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Open() of a missing file error = %v", err)
+	}
+}
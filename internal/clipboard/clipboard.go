@@ -0,0 +1,78 @@
+package clipboard
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Method is how text was copied.
+type Method string
+
+const (
+	// System means the text went to the local clipboard.
+	System Method = "clipboard"
+	// OSC52 means the text was sent to the terminal as an OSC52 escape
+	// sequence, which sets the clipboard of the machine the terminal runs
+	// on, even over SSH.
+	OSC52 Method = "OSC52"
+)
+
+// Clipboard copies text to the system clipboard, falling back to OSC52 when
+// there is none.
+type Clipboard struct {
+	out    io.Writer
+	native func(string) error
+	// remote skips the system clipboard, which belongs to the remote
+	// machine in an SSH session.
+	remote bool
+	tmux   bool
+	screen bool
+}
+
+// New creates a Clipboard that copies with native, writing OSC52 sequences
+// to out when native fails. A nil native always uses OSC52.
+func New(out io.Writer, native func(string) error) *Clipboard {
+	return &Clipboard{
+		out:    out,
+		native: native,
+		remote: os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "",
+		tmux:   os.Getenv("TMUX") != "",
+		screen: strings.HasPrefix(os.Getenv("TERM"), "screen"),
+	}
+}
+
+// Default creates a Clipboard using the system clipboard and the terminal
+// on standard output.
+func Default() *Clipboard {
+	native := clipboard.WriteAll
+	if clipboard.Unsupported {
+		native = nil
+	}
+	return New(os.Stdout, native)
+}
+
+// Copy copies text and returns how it was copied.
+func (c *Clipboard) Copy(text string) (Method, error) {
+	if c.native != nil && !c.remote {
+		if err := c.native(text); err == nil {
+			return System, nil
+		}
+	}
+
+	seq := osc52.New(text)
+	switch {
+	case c.tmux:
+		seq = seq.Tmux()
+	case c.screen:
+		seq = seq.Screen()
+	}
+	if _, err := seq.WriteTo(c.out); err != nil {
+		return OSC52, fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	return OSC52, nil
+}
@@ -0,0 +1,54 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClipboard_Copy(t *testing.T) {
+	for _, env := range []string{"SSH_TTY", "SSH_CONNECTION", "TMUX"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("TERM", "xterm-256color")
+
+	var copied string
+	var out bytes.Buffer
+	c := New(&out, func(text string) error {
+		copied = text
+		return nil
+	})
+	if method, err := c.Copy("hello"); err != nil || method != System {
+		t.Errorf("Copy() = %v, %v; want System", method, err)
+	}
+	if copied != "hello" || out.Len() != 0 {
+		t.Errorf("copied %q and wrote %q, want the system clipboard only", copied, out.String())
+	}
+
+	// Without a working clipboard the text goes to the terminal.
+	c = New(&out, func(string) error { return errors.New("no clipboard") })
+	if method, err := c.Copy("hello"); err != nil || method != OSC52 {
+		t.Errorf("Copy() = %v, %v; want OSC52", method, err)
+	}
+	if got, want := out.String(), "\x1b]52;c;aGVsbG8=\x07"; got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
+
+func TestClipboard_CopyRemote(t *testing.T) {
+	t.Setenv("SSH_TTY", "/dev/pts/0")
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	var out bytes.Buffer
+	c := New(&out, func(string) error {
+		t.Error("the system clipboard was used in an SSH session")
+		return nil
+	})
+	if method, err := c.Copy("hello"); err != nil || method != OSC52 {
+		t.Errorf("Copy() = %v, %v; want OSC52", method, err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "\x1bPtmux;") || !strings.Contains(got, "aGVsbG8=") {
+		t.Errorf("wrote %q, want an OSC52 sequence wrapped for tmux", got)
+	}
+}
@@ -0,0 +1,167 @@
+package columns
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// Built-in column names, taken from the parsed entry rather than its fields.
+const (
+	Time    = "ts"
+	Level   = "level"
+	Message = "msg"
+	Source  = "source"
+	Line    = "line"
+)
+
+// aliases maps other names of the built-in columns to theirs.
+var aliases = map[string]string{
+	"time":      Time,
+	"timestamp": Time,
+	"message":   Message,
+}
+
+// Parse splits a comma-separated list of column names, as given to
+// --columns.
+func Parse(spec string) []string {
+	var columns []string
+	for _, name := range strings.Split(spec, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// Builtin returns the built-in column a name refers to, if any.
+func Builtin(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := aliases[name]; ok {
+		return alias, true
+	}
+	switch name {
+	case Time, Level, Message, Source, Line:
+		return name, true
+	}
+	return "", false
+}
+
+// Value returns the value of column name in an entry: a built-in column,
+// a field, or a nested field by dotted path ("http.status").
+func Value(entry logentry.Entry, name string) (any, bool) {
+	if col, ok := Builtin(name); ok {
+		switch col {
+		case Time:
+			return entry.Timestamp, !entry.Timestamp.IsZero()
+		case Level:
+			return entry.Level, true
+		case Message:
+			return entry.Message, true
+		case Source:
+			return filepath.Base(entry.Source), entry.Source != ""
+		case Line:
+			return entry.Line, entry.Line > 0
+		}
+	}
+
+	if v, ok := entry.GetField(name); ok {
+		return v, true
+	}
+	var v any = entry.Fields
+	for _, key := range strings.Split(name, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Text returns the value of column name in an entry as shown in its cell,
+// or "" when the entry doesn't have it.
+func Text(entry logentry.Entry, name string) string {
+	v, ok := Value(entry, name)
+	if !ok || v == nil {
+		return ""
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format("15:04:05.000")
+	case logentry.Level:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Compare orders two entries by column name: numbers numerically, times and
+// levels in their order, anything else as text. Entries without the column
+// sort first.
+func Compare(a, b logentry.Entry, name string) int {
+	va, oka := Value(a, name)
+	vb, okb := Value(b, name)
+	switch {
+	case !oka && !okb:
+		return 0
+	case !oka:
+		return -1
+	case !okb:
+		return 1
+	}
+
+	switch x := va.(type) {
+	case time.Time:
+		if y, ok := vb.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case logentry.Level:
+		if y, ok := vb.(logentry.Level); ok {
+			return int(x) - int(y)
+		}
+	}
+	if x, ok := number(va); ok {
+		if y, ok := number(vb); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(Text(a, name), Text(b, name))
+}
+
+// number returns v as a number, parsing numeric strings.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
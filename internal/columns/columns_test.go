@@ -0,0 +1,99 @@
+package columns
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+func TestParse(t *testing.T) {
+	got := Parse(" ts, level,,http.status ")
+	if want := []string{"ts", "level", "http.status"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %q, want %q", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	entry := logentry.Entry{
+		Level:     logentry.Warn,
+		Message:   "slow request",
+		Timestamp: time.Date(2024, 1, 15, 10, 42, 5, 120e6, time.UTC),
+		Source:    "/var/log/api.log",
+		Line:      12,
+		Fields: map[string]any{
+			"status":      503.0,
+			"duration_ms": 1250.5,
+			"service":     "api",
+			"http":        map[string]any{"method": "GET"},
+		},
+	}
+	tests := map[string]string{
+		"ts":          "10:42:05.120",
+		"timestamp":   "10:42:05.120",
+		"level":       "WARN",
+		"msg":         "slow request",
+		"source":      "api.log",
+		"line":        "12",
+		"status":      "503",
+		"duration_ms": "1250.5",
+		"service":     "api",
+		"http":        `{"method":"GET"}`,
+		"http.method": "GET",
+		"missing":     "",
+	}
+	for name, want := range tests {
+		if got := Text(entry, name); got != want {
+			t.Errorf("Text(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	entries := []logentry.Entry{
+		{Message: "a", Fields: map[string]any{"status": 500.0}},
+		{Message: "b", Fields: map[string]any{"status": 99.0}},
+		{Message: "c"},
+		{Message: "d", Fields: map[string]any{"status": "404"}},
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return Compare(entries[i], entries[j], "status") < 0 })
+
+	var got string
+	for _, entry := range entries {
+		got += entry.Message
+	}
+	if want := "cbda"; got != want {
+		t.Errorf("sorted by status = %q, want %q", got, want)
+	}
+
+	if c := Compare(logentry.Entry{Level: logentry.Error}, logentry.Entry{Level: logentry.Info}, "level"); c <= 0 {
+		t.Errorf("Compare(ERROR, INFO) = %d, want > 0", c)
+	}
+}
+
+func TestStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "columns.json")
+
+	s := NewStore(path)
+	s.Set("/logs/api.log", Layout{Columns: []string{"ts", "status", "path"}, SortBy: "status", Desc: true})
+	s.Set("/logs/other.log", Layout{Columns: []string{"ts"}})
+	s.Set("/logs/other.log", Layout{})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	got, ok := loaded.Get("/logs/api.log")
+	if want, _ := s.Get("/logs/api.log"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %+v, %v; want %+v", got, ok, want)
+	}
+	if _, ok := loaded.Get("/logs/other.log"); ok {
+		t.Error("Get() found a layout set without columns")
+	}
+}
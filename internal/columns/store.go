@@ -0,0 +1,114 @@
+package columns
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Layout is the columns view of a file.
+type Layout struct {
+	Columns []string `json:"columns"`
+	// SortBy is the column entries are sorted by, or empty for file order.
+	SortBy string `json:"sort_by,omitempty"`
+	// Desc sorts by SortBy in descending order.
+	Desc bool `json:"desc,omitempty"`
+}
+
+// Store holds the column layouts of every file, persisted as JSON.
+type Store struct {
+	path    string
+	layouts map[string]Layout
+	mu      sync.Mutex
+}
+
+// NewStore creates an empty Store backed by the file at path. An empty path
+// keeps the layouts in memory only.
+func NewStore(path string) *Store {
+	return &Store{path: path, layouts: make(map[string]Layout)}
+}
+
+// OpenStore creates a Store and loads any layouts saved at path.
+func OpenStore(path string) (*Store, error) {
+	s := NewStore(path)
+	if err := s.Load(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Load reads the layouts file, replacing the layouts in memory. A missing
+// file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read column layouts: %w", err)
+	}
+
+	layouts := make(map[string]Layout)
+	if err := json.Unmarshal(data, &layouts); err != nil {
+		return fmt.Errorf("failed to parse column layouts: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.layouts = layouts
+	return nil
+}
+
+// Save writes the layouts file, creating its directory if needed.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.layouts, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode column layouts: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create column layouts directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write column layouts: %w", err)
+	}
+	return nil
+}
+
+// Path returns the file the layouts are persisted to.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns the layout saved for file.
+func (s *Store) Get(file string) (Layout, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	layout, ok := s.layouts[file]
+	layout.Columns = append([]string(nil), layout.Columns...)
+	return layout, ok
+}
+
+// Set sets the layout of file. A layout without columns removes it.
+func (s *Store) Set(file string, layout Layout) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(layout.Columns) == 0 {
+		delete(s.layouts, file)
+		return
+	}
+	layout.Columns = append([]string(nil), layout.Columns...)
+	s.layouts[file] = layout
+}
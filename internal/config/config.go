@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,17 +17,88 @@ type Config struct {
 	TimestampFormat string   `mapstructure:"timestamp_format"`
 	ShowLineNumbers bool     `mapstructure:"show_line_numbers"`
 	WrapLines       bool     `mapstructure:"wrap_lines"`
+	Mouse           bool     `mapstructure:"mouse"`
+	ContextLines    int      `mapstructure:"context_lines"`
 	JSONIndent      int      `mapstructure:"json_indent"`
 	MaxBufferSize   int      `mapstructure:"max_buffer_size"`
 	WorkerCount     int      `mapstructure:"worker_count"`
 	Follow          bool     `mapstructure:"follow"`
 	LevelFilter     string   `mapstructure:"level_filter"`
 	FilterExpr      string   `mapstructure:"filter_expr"`
+	Filters         Filters  `mapstructure:"filters"`
+	Watch           Watch    `mapstructure:"watch"`
+	Tail            Tail     `mapstructure:"tail"`
+	Layout          Layout   `mapstructure:"layout"`
+	Markers         Markers  `mapstructure:"markers"`
+	Columns         []string `mapstructure:"columns"`
+	CorrelationKeys []string `mapstructure:"correlation_keys"`
 	FilePaths       []string `mapstructure:"-"`
+	// Path is the config file that was read, or the default location
+	// new settings are written to when no file exists yet.
+	Path string `mapstructure:"-"`
+}
+
+// Filters holds the filters section of the config file.
+type Filters struct {
+	Presets map[string]PresetConfig `mapstructure:"presets"`
+	// Exclude lists filter expressions or /regexes/ whose matching entries
+	// are hidden.
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// Watch holds the watch section of the config file: which files are
+// followed when following a directory.
+type Watch struct {
+	// Glob is matched against file names.
+	Glob string `mapstructure:"glob"`
+	// Depth is how many directories below the followed one are searched.
+	Depth int `mapstructure:"depth"`
+}
+
+// Tail holds the tail section of the config file: how lines of followed
+// files are batched.
+type Tail struct {
+	// MaxBatchRate is how many batches of new lines are shown per second.
+	MaxBatchRate int `mapstructure:"max_batch_rate"`
+	// MaxPending is how many lines may wait to be shown before the oldest
+	// are dropped.
+	MaxPending int `mapstructure:"max_pending"`
+}
+
+// Layout holds the layout section of the config file: how the screen is
+// split between the log view and the field inspector.
+type Layout struct {
+	// PaneRatio is the share of the screen the inspector gets.
+	PaneRatio float64 `mapstructure:"pane_ratio"`
+	// StackWidth is the terminal width below which the inspector is shown
+	// below the log view instead of next to it.
+	StackWidth int `mapstructure:"stack_width"`
+}
+
+// Markers holds the markers section of the config file: when the log view
+// marks a gap in time or a burst of entries.
+type Markers struct {
+	// Gap is how long the log must go quiet for a separator to be shown.
+	Gap time.Duration `mapstructure:"gap"`
+	// Burst is how many entries logged within one second are marked.
+	Burst int `mapstructure:"burst"`
+}
+
+// PresetConfig is a named filter preset as stored in the config file.
+type PresetConfig struct {
+	Description string `mapstructure:"description" yaml:"description,omitempty"`
+	Level       string `mapstructure:"level" yaml:"level,omitempty"`
+	Filter      string `mapstructure:"filter" yaml:"filter,omitempty"`
 }
 
 // Load reads configuration from file and environment, applying defaults.
 func Load() (*Config, error) {
+	return LoadFrom("")
+}
+
+// LoadFrom reads configuration from the given file, or searches the default
+// locations when path is empty.
+func LoadFrom(path string) (*Config, error) {
 	v := viper.New()
 
 	// Set defaults
@@ -32,19 +106,32 @@ func Load() (*Config, error) {
 	v.SetDefault("timestamp_format", DefaultTimestampFormat)
 	v.SetDefault("show_line_numbers", DefaultShowLineNumbers)
 	v.SetDefault("wrap_lines", DefaultWrapLines)
+	v.SetDefault("mouse", DefaultMouse)
+	v.SetDefault("context_lines", DefaultContextLines)
 	v.SetDefault("json_indent", DefaultJSONIndent)
 	v.SetDefault("max_buffer_size", DefaultMaxBufferSize)
 	v.SetDefault("worker_count", DefaultWorkerCount)
 	v.SetDefault("follow", false)
+	v.SetDefault("watch.glob", DefaultWatchGlob)
+	v.SetDefault("watch.depth", DefaultWatchDepth)
+	v.SetDefault("tail.max_batch_rate", DefaultTailMaxBatchRate)
+	v.SetDefault("tail.max_pending", DefaultTailMaxPending)
+	v.SetDefault("layout.pane_ratio", DefaultPaneRatio)
+	v.SetDefault("layout.stack_width", DefaultStackWidth)
+	v.SetDefault("markers.gap", DefaultMarkerGap)
+	v.SetDefault("markers.burst", DefaultMarkerBurst)
 
 	// Config file search paths
-	v.SetConfigName("config")
 	v.SetConfigType("yaml")
-
-	if configDir, err := os.UserConfigDir(); err == nil {
-		v.AddConfigPath(filepath.Join(configDir, "sieve"))
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.SetConfigName("config")
+		if configDir, err := os.UserConfigDir(); err == nil {
+			v.AddConfigPath(filepath.Join(configDir, "sieve"))
+		}
+		v.AddConfigPath(".")
 	}
-	v.AddConfigPath(".")
 
 	// Environment variables
 	v.SetEnvPrefix("SIEVE")
@@ -52,7 +139,8 @@ func Load() (*Config, error) {
 
 	// Read config file (ignore if not found)
 	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) && !(path != "" && errors.Is(err, fs.ErrNotExist)) {
 			return nil, fmt.Errorf("reading config file: %w", err)
 		}
 	}
@@ -62,5 +150,35 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("unmarshalling config: %w", err)
 	}
 
+	cfg.Path = v.ConfigFileUsed()
+	if path != "" {
+		cfg.Path = path
+	}
+	if cfg.Path == "" {
+		cfg.Path = DefaultConfigPath()
+	}
+
 	return cfg, nil
 }
+
+// DefaultConfigPath returns the location of the user's config file.
+func DefaultConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.yaml"
+	}
+	return filepath.Join(configDir, "sieve", "config.yaml")
+}
+
+// StateDir returns the directory for persistent state such as history,
+// following the XDG base directory spec ($XDG_STATE_HOME/sieve).
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "sieve")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "sieve")
+	}
+	return filepath.Join(home, ".local", "state", "sieve")
+}
@@ -1,6 +1,12 @@
 package config
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestLoadDefaults(t *testing.T) {
 	cfg, err := Load()
@@ -20,6 +26,14 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.WrapLines != DefaultWrapLines {
 		t.Errorf("WrapLines = %v, want %v", cfg.WrapLines, DefaultWrapLines)
 	}
+	// Capturing the mouse takes text selection away from the terminal, so
+	// it is opt-in.
+	if cfg.Mouse {
+		t.Error("Mouse = true, want mouse support off unless configured")
+	}
+	if cfg.ContextLines != DefaultContextLines {
+		t.Errorf("ContextLines = %d, want %d", cfg.ContextLines, DefaultContextLines)
+	}
 	if cfg.JSONIndent != DefaultJSONIndent {
 		t.Errorf("JSONIndent = %d, want %d", cfg.JSONIndent, DefaultJSONIndent)
 	}
@@ -32,4 +46,141 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.Follow {
 		t.Error("Follow = true, want false")
 	}
+	if cfg.Watch.Glob != DefaultWatchGlob || cfg.Watch.Depth != DefaultWatchDepth {
+		t.Errorf("Watch = %+v, want %q and depth %d", cfg.Watch, DefaultWatchGlob, DefaultWatchDepth)
+	}
+	if cfg.Tail.MaxBatchRate != DefaultTailMaxBatchRate || cfg.Tail.MaxPending != DefaultTailMaxPending {
+		t.Errorf("Tail = %+v, want rate %d and %d pending", cfg.Tail, DefaultTailMaxBatchRate, DefaultTailMaxPending)
+	}
+	if cfg.Layout.PaneRatio != DefaultPaneRatio || cfg.Layout.StackWidth != DefaultStackWidth {
+		t.Errorf("Layout = %+v, want ratio %v and stack width %d", cfg.Layout, DefaultPaneRatio, DefaultStackWidth)
+	}
+	if cfg.Markers.Gap != DefaultMarkerGap || cfg.Markers.Burst != DefaultMarkerBurst {
+		t.Errorf("Markers = %+v, want gap %v and burst %d", cfg.Markers, DefaultMarkerGap, DefaultMarkerBurst)
+	}
+}
+
+func TestSavePresetRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	existing := "# my settings\ntheme: nord\n"
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	preset := PresetConfig{Level: "warn", Filter: `.service == "auth"`}
+	if err := SavePreset(path, "auth-issues", preset); err != nil {
+		t.Fatalf("SavePreset() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "# my settings") {
+		t.Errorf("SavePreset() dropped existing comments:\n%s", data)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Theme != "nord" {
+		t.Errorf("Theme = %q, want %q", cfg.Theme, "nord")
+	}
+	if cfg.Path != path {
+		t.Errorf("Path = %q, want %q", cfg.Path, path)
+	}
+	got, ok := cfg.Filters.Presets["auth-issues"]
+	if !ok {
+		t.Fatalf("preset not loaded, got %v", cfg.Filters.Presets)
+	}
+	if got != preset {
+		t.Errorf("preset = %+v, want %+v", got, preset)
+	}
+}
+
+func TestSavePaneRatio(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := SavePaneRatio(path, 0.5); err != nil {
+		t.Fatalf("SavePaneRatio() error = %v", err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Layout.PaneRatio != 0.5 || cfg.Layout.StackWidth != DefaultStackWidth {
+		t.Errorf("Layout = %+v, want ratio 0.5 and the default stack width", cfg.Layout)
+	}
+}
+
+func TestSaveValueKeepsOnlyComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("# sieve settings\n# see README\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := SavePaneRatio(path, 0.5); err != nil {
+		t.Fatalf("SavePaneRatio() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# sieve settings\n# see README\n") {
+		t.Errorf("SavePaneRatio() dropped the comments:\n%s", data)
+	}
+	cfg, err := LoadFrom(path)
+	if err != nil || cfg.Layout.PaneRatio != 0.5 {
+		t.Errorf("PaneRatio = %v (%v), want 0.5", cfg.Layout.PaneRatio, err)
+	}
+}
+
+func TestSaveValueRefusesOtherFormats(t *testing.T) {
+	for _, name := range []string{"config.json", "config.toml"} {
+		path := filepath.Join(t.TempDir(), name)
+		original := []byte(`{"theme": "nord"}`)
+		if err := os.WriteFile(path, original, 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if err := SavePreset(path, "errors", PresetConfig{Level: "error"}); err == nil {
+			t.Errorf("SavePreset(%s) should refuse to write a non-YAML config", name)
+		}
+		if data, _ := os.ReadFile(path); string(data) != string(original) {
+			t.Errorf("SavePreset(%s) changed the file to:\n%s", name, data)
+		}
+	}
+}
+
+func TestLoadMarkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("markers:\n  gap: 2m\n  burst: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Markers.Gap != 2*time.Minute || cfg.Markers.Burst != 0 {
+		t.Errorf("Markers = %+v, want a 2m gap and bursts off", cfg.Markers)
+	}
+}
+
+func TestLoadFromMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Path != path {
+		t.Errorf("Path = %q, want %q", cfg.Path, path)
+	}
+}
+
+func TestStateDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	if got, want := StateDir(), filepath.Join("/tmp/xdg-state", "sieve"); got != want {
+		t.Errorf("StateDir() = %q, want %q", got, want)
+	}
 }
@@ -1,12 +1,24 @@
 package config
 
+import "time"
+
 // Default configuration values.
 const (
-	DefaultTheme           = "kanagawa"
-	DefaultTimestampFormat = "2006-01-02T15:04:05Z07:00"
-	DefaultShowLineNumbers = true
-	DefaultWrapLines       = false
-	DefaultJSONIndent      = 2
-	DefaultMaxBufferSize   = 100000
-	DefaultWorkerCount     = 4
+	DefaultTheme            = "kanagawa"
+	DefaultTimestampFormat  = "2006-01-02T15:04:05Z07:00"
+	DefaultShowLineNumbers  = true
+	DefaultWrapLines        = false
+	DefaultMouse            = false
+	DefaultContextLines     = 3
+	DefaultJSONIndent       = 2
+	DefaultMaxBufferSize    = 100000
+	DefaultWorkerCount      = 4
+	DefaultWatchGlob        = "*.log"
+	DefaultWatchDepth       = 0
+	DefaultTailMaxBatchRate = 20
+	DefaultTailMaxPending   = 100000
+	DefaultPaneRatio        = 0.35
+	DefaultStackWidth       = 100
+	DefaultMarkerGap        = 30 * time.Second
+	DefaultMarkerBurst      = 100
 )
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SavePreset writes a named filter preset to the config file at path,
+// creating the file if needed.
+func SavePreset(path, name string, preset PresetConfig) error {
+	if name == "" {
+		return fmt.Errorf("preset name is empty")
+	}
+	return SaveValue(path, []string{"filters", "presets", name}, preset)
+}
+
+// SavePaneRatio writes the share of the screen the inspector gets to the
+// config file at path.
+func SavePaneRatio(path string, ratio float64) error {
+	return SaveValue(path, []string{"layout", "pane_ratio"}, ratio)
+}
+
+// SaveValue sets a nested key in the YAML config file at path and writes it
+// back. Other keys, comments and ordering in the file are preserved. Config
+// files in other formats are left alone, as writing YAML would break them.
+func SaveValue(path string, keys []string, value any) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no key given")
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case "", ".yaml", ".yml":
+	default:
+		return fmt.Errorf("can't save to %s: only YAML config files can be written", path)
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path) // #nosec G304 -- user's own config file
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parsing config file: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		// A file of only comments parses to nothing; keep them at the top.
+		doc = yaml.Node{
+			Kind:        yaml.DocumentNode,
+			HeadComment: strings.TrimSpace(string(data)),
+			Content:     []*yaml.Node{{Kind: yaml.MappingNode}},
+		}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file root is not a mapping")
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("encoding value: %w", err)
+	}
+
+	node := root
+	for _, key := range keys[:len(keys)-1] {
+		child := mappingValue(node, key)
+		if child == nil || child.Kind != yaml.MappingNode {
+			fresh := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(node, key, fresh)
+			child = fresh
+		}
+		node = child
+	}
+	setMappingValue(node, keys[len(keys)-1], &valueNode)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encoding config file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("encoding config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces or appends the value for key in a mapping node.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
@@ -0,0 +1,214 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// maxTrackedValues bounds how many distinct values are remembered per field,
+// so high-cardinality fields like ids or messages don't grow without limit.
+const maxTrackedValues = 1000
+
+// FieldStats tracks which fields appear in log entries and how often each
+// value is seen. It feeds field and value completion in the filter bar.
+type FieldStats struct {
+	fields map[string]int
+	values map[string]map[string]int
+}
+
+// NewFieldStats creates field statistics for the given entries.
+func NewFieldStats(entries []logentry.Entry) *FieldStats {
+	s := &FieldStats{
+		fields: make(map[string]int),
+		values: make(map[string]map[string]int),
+	}
+	s.Add(entries...)
+	return s
+}
+
+// Add records the fields of entries.
+func (s *FieldStats) Add(entries ...logentry.Entry) {
+	for _, entry := range entries {
+		for key, val := range entry.Fields {
+			s.fields[key]++
+
+			str, ok := scalarString(val)
+			if !ok {
+				continue
+			}
+			counts := s.values[key]
+			if counts == nil {
+				counts = make(map[string]int)
+				s.values[key] = counts
+			}
+			if _, seen := counts[str]; seen || len(counts) < maxTrackedValues {
+				counts[str]++
+			}
+		}
+	}
+}
+
+// Has reports whether any entry has field. A nil FieldStats has no fields.
+func (s *FieldStats) Has(field string) bool {
+	return s != nil && s.fields[field] > 0
+}
+
+// Len returns the number of distinct fields seen.
+func (s *FieldStats) Len() int {
+	return len(s.fields)
+}
+
+// Fields returns field names starting with prefix, most frequent first.
+func (s *FieldStats) Fields(prefix string) []string {
+	var names []string
+	for name := range s.fields {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sortByCount(names, s.fields)
+	return names
+}
+
+// Values returns up to n observed values of field starting with prefix,
+// most frequent first. A non-positive n returns all of them.
+func (s *FieldStats) Values(field, prefix string, n int) []string {
+	counts := s.values[field]
+	var vals []string
+	for val := range counts {
+		if strings.HasPrefix(val, prefix) {
+			vals = append(vals, val)
+		}
+	}
+	sortByCount(vals, counts)
+	if n > 0 && len(vals) > n {
+		vals = vals[:n]
+	}
+	return vals
+}
+
+// sortByCount sorts keys by descending count, then alphabetically.
+func sortByCount(keys []string, counts map[string]int) {
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := counts[keys[i]], counts[keys[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// scalarString formats strings, numbers and booleans for completion.
+func scalarString(val any) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, v != ""
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// CompletionKind is what is being completed at the end of an expression.
+type CompletionKind int
+
+const (
+	// CompleteNone means nothing can be completed.
+	CompleteNone CompletionKind = iota
+	// CompleteField means a field name after '.' is being typed.
+	CompleteField
+	// CompleteValue means a quoted value compared against a field is being typed.
+	CompleteValue
+)
+
+// Completion describes the token being typed at the end of a partial filter
+// expression.
+type Completion struct {
+	Kind   CompletionKind
+	Field  string // field the value is compared against, for CompleteValue
+	Prefix string // partial text already typed
+	Start  int    // byte offset of Prefix in the input
+	Quote  byte   // opening quote, for CompleteValue
+}
+
+// CompletionAt inspects the end of input and reports what can be completed.
+func CompletionAt(input string) Completion {
+	if quotePos, quote, open := openQuote(input); open {
+		field, ok := comparedField(input[:quotePos])
+		if !ok {
+			return Completion{}
+		}
+		return Completion{
+			Kind:   CompleteValue,
+			Field:  field,
+			Prefix: input[quotePos+1:],
+			Start:  quotePos + 1,
+			Quote:  quote,
+		}
+	}
+
+	end := len(input)
+	start := end
+	for start > 0 && isFieldChar(input[start-1]) {
+		start--
+	}
+	if start == 0 || input[start-1] != '.' {
+		return Completion{}
+	}
+	return Completion{Kind: CompleteField, Prefix: input[start:end], Start: start}
+}
+
+// openQuote returns the position of an unterminated string literal's opening
+// quote in input.
+func openQuote(input string) (int, byte, bool) {
+	var quote byte
+	pos := -1
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+		switch {
+		case quote != 0 && ch == '\\':
+			i++
+		case quote != 0 && ch == quote:
+			quote = 0
+		case quote == 0 && (ch == '"' || ch == '\''):
+			quote = ch
+			pos = i
+		}
+	}
+	return pos, quote, quote != 0
+}
+
+// comparedField returns the field on the left of a trailing comparison
+// operator, e.g. "service" for `.level > 30 and .service == `.
+func comparedField(input string) (string, bool) {
+	rest := strings.TrimRight(input, " \t")
+	found := false
+	for _, op := range []string{"==", "!=", ">=", "<=", ">", "<", "contains", "matches"} {
+		if strings.HasSuffix(rest, op) {
+			rest = strings.TrimRight(strings.TrimSuffix(rest, op), " \t")
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	end := len(rest)
+	start := end
+	for start > 0 && isFieldChar(rest[start-1]) {
+		start--
+	}
+	if start == end || start == 0 || rest[start-1] != '.' {
+		return "", false
+	}
+	return rest[start:end], true
+}
+
+func isFieldChar(ch byte) bool {
+	return ch == '_' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
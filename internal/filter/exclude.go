@@ -0,0 +1,239 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// ExcludeRule hides entries that match a filter expression or a regular
+// expression. Patterns starting with '.' or "not " are filter expressions,
+// patterns of the form /re/ or re:re are regular expressions matched against
+// the raw line, and anything else is tried as an expression first and falls
+// back to a regular expression.
+type ExcludeRule struct {
+	Pattern string
+	expr    *CompiledFilter
+	re      *regexp.Regexp
+}
+
+// ParseExcludeRule parses an exclusion pattern.
+func ParseExcludeRule(pattern string) (*ExcludeRule, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("empty exclude pattern")
+	}
+
+	if re, ok := regexPattern(pattern); ok {
+		compiled, err := regexp.Compile(re)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude regex %q: %w", re, err)
+		}
+		return &ExcludeRule{Pattern: pattern, re: compiled}, nil
+	}
+
+	parsed, err := Parse(pattern)
+	if err == nil {
+		compiled, err := Compile(parsed)
+		if err != nil {
+			return nil, err
+		}
+		return &ExcludeRule{Pattern: pattern, expr: compiled}, nil
+	}
+	if strings.HasPrefix(pattern, ".") || strings.HasPrefix(pattern, "not ") {
+		return nil, fmt.Errorf("invalid exclude expression %q: %w", pattern, err)
+	}
+
+	compiled, reErr := regexp.Compile(pattern)
+	if reErr != nil {
+		return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, reErr)
+	}
+	return &ExcludeRule{Pattern: pattern, re: compiled}, nil
+}
+
+// regexPattern extracts the regular expression from /re/ and re:re patterns.
+func regexPattern(pattern string) (string, bool) {
+	if strings.HasPrefix(pattern, "re:") {
+		return strings.TrimPrefix(pattern, "re:"), true
+	}
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return pattern[1 : len(pattern)-1], true
+	}
+	return "", false
+}
+
+// Match reports whether the rule hides entry.
+func (r *ExcludeRule) Match(entry logentry.Entry) bool {
+	if r.re != nil {
+		text := entry.Raw
+		if text == "" {
+			text = entry.Message
+		}
+		return r.re.MatchString(text)
+	}
+	matches, err := r.expr.Evaluate(entry)
+	return err == nil && matches
+}
+
+// LikeEntryPattern returns an exclude pattern hiding entries with the same
+// message as entry.
+func LikeEntryPattern(entry logentry.Entry) string {
+	msg := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(entry.Message)
+	return fmt.Sprintf(`.message == "%s"`, msg)
+}
+
+// FieldPattern returns an expression matching entries whose field has
+// value. It reports false for values an expression can't compare, and for
+// field names it can't refer to, such as nested paths.
+func FieldPattern(field string, value any) (string, bool) {
+	if field == "" || strings.IndexFunc(field, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) >= 0 {
+		return "", false
+	}
+	switch v := value.(type) {
+	case string:
+		s := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v)
+		return fmt.Sprintf(`.%s == "%s"`, field, s), true
+	case bool:
+		return fmt.Sprintf(".%s == %t", field, v), true
+	case float64:
+		return fmt.Sprintf(".%s == %s", field, strconv.FormatFloat(v, 'f', -1, 64)), true
+	case int:
+		return fmt.Sprintf(".%s == %d", field, v), true
+	}
+	return "", false
+}
+
+// AndPattern narrows expr with clause. Expressions already joined by and/or
+// can't be narrowed further without parentheses, so clause replaces them.
+func AndPattern(expr, clause string) string {
+	parsed, err := Parse(expr)
+	if err != nil {
+		return clause
+	}
+	if _, compound := parsed.(CompoundExpr); compound {
+		return clause
+	}
+	return strings.TrimSpace(expr) + " and " + clause
+}
+
+// Excludes is an ordered list of exclusion rules that counts how many entries
+// each rule suppressed.
+type Excludes struct {
+	rules  []*ExcludeRule
+	counts []int
+}
+
+// NewExcludes parses patterns into an exclusion list.
+func NewExcludes(patterns []string) (*Excludes, error) {
+	x := &Excludes{}
+	for _, p := range patterns {
+		if _, err := x.Add(p); err != nil {
+			return nil, err
+		}
+	}
+	return x, nil
+}
+
+// Add parses pattern and appends it as a rule. It returns false if a rule
+// with the same pattern already exists.
+func (x *Excludes) Add(pattern string) (bool, error) {
+	rule, err := ParseExcludeRule(pattern)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range x.rules {
+		if r.Pattern == rule.Pattern {
+			return false, nil
+		}
+	}
+	x.rules = append(x.rules, rule)
+	x.counts = append(x.counts, 0)
+	return true, nil
+}
+
+// Len returns the number of rules.
+func (x *Excludes) Len() int {
+	if x == nil {
+		return 0
+	}
+	return len(x.rules)
+}
+
+// Rules returns the rules in order.
+func (x *Excludes) Rules() []*ExcludeRule {
+	if x == nil {
+		return nil
+	}
+	return x.rules
+}
+
+// Counts returns how many entries each rule suppressed since the last Reset.
+func (x *Excludes) Counts() []int {
+	if x == nil {
+		return nil
+	}
+	return x.counts
+}
+
+// Suppressed returns the total number of suppressed entries.
+func (x *Excludes) Suppressed() int {
+	total := 0
+	for _, c := range x.Counts() {
+		total += c
+	}
+	return total
+}
+
+// Reset zeroes the suppressed counts.
+func (x *Excludes) Reset() {
+	if x == nil {
+		return
+	}
+	for i := range x.counts {
+		x.counts[i] = 0
+	}
+}
+
+// Match returns the index of the first rule hiding entry, or -1.
+func (x *Excludes) Match(entry logentry.Entry) int {
+	if x == nil {
+		return -1
+	}
+	for i, r := range x.rules {
+		if r.Match(entry) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Filter returns the entries no rule hides, adding the hidden ones to the
+// per-rule counts.
+func (x *Excludes) Filter(entries []logentry.Entry) []logentry.Entry {
+	if x.Len() == 0 {
+		return entries
+	}
+	kept := make([]logentry.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if !x.hide(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// hide reports whether a rule hides entry and counts it against that rule.
+func (x *Excludes) hide(entry logentry.Entry) bool {
+	i := x.Match(entry)
+	if i < 0 {
+		return false
+	}
+	x.counts[i]++
+	return true
+}
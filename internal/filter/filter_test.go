@@ -1,7 +1,11 @@
 package filter
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
@@ -384,21 +388,22 @@ func TestPreset(t *testing.T) {
 		{"non-existent preset", "nonexistent", true},
 	}
 
+	presets := NewPresetRegistry()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			preset, ok := GetPreset(tt.preset)
+			preset, ok := presets.Get(tt.preset)
 			if tt.wantErr {
 				if ok {
-					t.Errorf("GetPreset() returned true for non-existent preset")
+					t.Errorf("Get() returned true for non-existent preset")
 				}
 				return
 			}
 			if !ok {
-				t.Errorf("GetPreset() returned false for existing preset")
+				t.Errorf("Get() returned false for existing preset")
 				return
 			}
 			if preset.Name != tt.preset {
-				t.Errorf("GetPreset() Name = %v, want %v", preset.Name, tt.preset)
+				t.Errorf("Get() Name = %v, want %v", preset.Name, tt.preset)
 			}
 		})
 	}
@@ -415,15 +420,16 @@ func TestNewPresetFilter(t *testing.T) {
 		{"non-existent preset", "nonexistent", true},
 	}
 
+	presets := NewPresetRegistry()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filter, err := NewPresetFilter(tt.preset)
+			filter, err := presets.NewFilter(tt.preset)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("NewPresetFilter() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("NewFilter() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if tt.wantErr && filter != nil {
-				t.Errorf("NewPresetFilter() returned filter for error case")
+				t.Errorf("NewFilter() returned filter for error case")
 			}
 		})
 	}
@@ -455,3 +461,435 @@ func TestOperator_String(t *testing.T) {
 		})
 	}
 }
+
+func TestPresetRegistry_Register(t *testing.T) {
+	presets := NewPresetRegistry()
+	presets.Register(Preset{Name: "auth-warn", Expression: `.service == "auth"`, Level: logentry.Warn})
+
+	pf, err := presets.NewFilter("auth-warn")
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		entry logentry.Entry
+		want  bool
+	}{
+		{"matching service and level", logentry.Entry{Level: logentry.Error, Fields: map[string]any{"service": "auth"}}, true},
+		{"below level", logentry.Entry{Level: logentry.Info, Fields: map[string]any{"service": "auth"}}, false},
+		{"other service", logentry.Entry{Level: logentry.Warn, Fields: map[string]any{"service": "api"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pf.Evaluate(tt.entry)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	presets.Register(Preset{Name: "auth-warn", Level: logentry.Error})
+	if got := len(presets.All()); got != len(builtinPresets)+1 {
+		t.Errorf("Register() duplicated preset, got %d presets", got)
+	}
+	pf, err = presets.NewFilter("auth-warn")
+	if err != nil {
+		t.Fatalf("NewFilter() level-only error = %v", err)
+	}
+	if pf.Compiled() != nil {
+		t.Error("level-only preset should have no compiled expression")
+	}
+
+	if _, ok := NewPresetRegistry().Get("auth-warn"); ok {
+		t.Error("a preset registered in one registry should not appear in a new one")
+	}
+}
+
+func TestCompletionAt(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Completion
+	}{
+		{input: "", want: Completion{}},
+		{input: ".", want: Completion{Kind: CompleteField, Start: 1}},
+		{input: ".ser", want: Completion{Kind: CompleteField, Prefix: "ser", Start: 1}},
+		{input: ".level > 30 and .sta", want: Completion{Kind: CompleteField, Prefix: "sta", Start: 17}},
+		{input: `.service == "`, want: Completion{Kind: CompleteValue, Field: "service", Start: 13, Quote: '"'}},
+		{input: `.service=='au`, want: Completion{Kind: CompleteValue, Field: "service", Prefix: "au", Start: 11, Quote: '\''}},
+		{input: `.msg contains "a\"b`, want: Completion{Kind: CompleteValue, Field: "msg", Prefix: `a\"b`, Start: 15, Quote: '"'}},
+		{input: `.service == "auth"`, want: Completion{}},
+		{input: `"abc`, want: Completion{}},
+		{input: ".level > 3", want: Completion{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := CompletionAt(tt.input); got != tt.want {
+				t.Errorf("CompletionAt(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldStats(t *testing.T) {
+	stats := NewFieldStats([]logentry.Entry{
+		{Fields: map[string]any{"service": "auth", "status": float64(200)}},
+		{Fields: map[string]any{"service": "api", "status": float64(500)}},
+	})
+	stats.Add(logentry.Entry{Fields: map[string]any{"service": "api", "tags": []any{"a"}}})
+
+	if got := stats.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	if got := stats.Fields("s"); len(got) != 2 || got[0] != "service" || got[1] != "status" {
+		t.Errorf("Fields(s) = %v, want [service status]", got)
+	}
+	if got := stats.Values("service", "", 0); len(got) != 2 || got[0] != "api" || got[1] != "auth" {
+		t.Errorf("Values(service) = %v, want [api auth]", got)
+	}
+	if got := stats.Values("status", "5", 0); len(got) != 1 || got[0] != "500" {
+		t.Errorf("Values(status, 5) = %v, want [500]", got)
+	}
+	if got := stats.Values("tags", "", 0); len(got) != 0 {
+		t.Errorf("Values(tags) = %v, want none for non-scalar values", got)
+	}
+}
+
+func TestParseExcludeRule(t *testing.T) {
+	entry := logentry.Entry{
+		Message: "GET /healthz",
+		Raw:     `{"msg":"GET /healthz","path":"/healthz"}`,
+		Fields:  map[string]any{"path": "/healthz"},
+	}
+
+	tests := []struct {
+		pattern   string
+		wantErr   bool
+		wantMatch bool
+	}{
+		{pattern: `.path == "/healthz"`, wantMatch: true},
+		{pattern: `.path == "/api"`, wantMatch: false},
+		{pattern: "/heal+thz/", wantMatch: true},
+		{pattern: "re:^GET", wantMatch: false},
+		{pattern: `re:^\{"msg":"GET`, wantMatch: true},
+		{pattern: "healthz", wantMatch: true},
+		{pattern: ".path ==", wantErr: true},
+		{pattern: "/[/", wantErr: true},
+		{pattern: "  ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			rule, err := ParseExcludeRule(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExcludeRule(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := rule.Match(entry); got != tt.wantMatch {
+				t.Errorf("Match() = %v, want %v", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestExcludes_Filter(t *testing.T) {
+	excludes, err := NewExcludes([]string{`.path == "/healthz"`, "/debug/"})
+	if err != nil {
+		t.Fatalf("NewExcludes() error = %v", err)
+	}
+	if added, _ := excludes.Add("/debug/"); added {
+		t.Error("Add() of a duplicate pattern = true, want false")
+	}
+
+	entries := []logentry.Entry{
+		{Message: "ok", Fields: map[string]any{"path": "/healthz"}},
+		{Message: "debug chatter"},
+		{Message: "debug again"},
+		{Message: "keep me"},
+	}
+	kept := excludes.Filter(entries)
+	if len(kept) != 1 || kept[0].Message != "keep me" {
+		t.Errorf("Filter() kept %v, want only %q", kept, "keep me")
+	}
+	if got := excludes.Counts(); got[0] != 1 || got[1] != 2 {
+		t.Errorf("Counts() = %v, want [1 2]", got)
+	}
+
+	excludes.Filter(entries[:1])
+	if got := excludes.Suppressed(); got != 4 {
+		t.Errorf("Suppressed() = %d, want 4 after incremental filter", got)
+	}
+	excludes.Reset()
+	if got := excludes.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d after Reset, want 0", got)
+	}
+}
+
+func TestLikeEntryPattern(t *testing.T) {
+	entry := logentry.Entry{Message: `say "hi" \o/`}
+	rule, err := ParseExcludeRule(LikeEntryPattern(entry))
+	if err != nil {
+		t.Fatalf("ParseExcludeRule(LikeEntryPattern()) error = %v", err)
+	}
+	if !rule.Match(entry) {
+		t.Errorf("rule %q does not match its own entry", rule.Pattern)
+	}
+}
+
+func TestFieldPattern(t *testing.T) {
+	entry := logentry.Entry{Fields: map[string]any{
+		"service": `say "hi"`,
+		"status":  503.0,
+		"retry":   true,
+		"latency": 0.25,
+	}}
+	for field, value := range entry.Fields {
+		pattern, ok := FieldPattern(field, value)
+		if !ok {
+			t.Errorf("FieldPattern(%q) not ok", field)
+			continue
+		}
+		rule, err := ParseExcludeRule(pattern)
+		if err != nil {
+			t.Errorf("FieldPattern(%q) = %q: %v", field, pattern, err)
+			continue
+		}
+		if !rule.Match(entry) {
+			t.Errorf("FieldPattern(%q) = %q does not match its entry", field, pattern)
+		}
+	}
+
+	if _, ok := FieldPattern("user.id", "ada"); ok {
+		t.Error("FieldPattern() ok for a nested path")
+	}
+	if _, ok := FieldPattern("tags", []any{"a"}); ok {
+		t.Error("FieldPattern() ok for an array")
+	}
+}
+
+func TestAndPattern(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", `.app == "api"`},
+		{`.level == "error"`, `.level == "error" and .app == "api"`},
+		{`not .retry == true`, `not .retry == true and .app == "api"`},
+		{`.a == 1 or .b == 2`, `.app == "api"`},
+		{`.a == 1 and .b == 2`, `.app == "api"`},
+	}
+	for _, tt := range tests {
+		got := AndPattern(tt.expr, `.app == "api"`)
+		if got != tt.want {
+			t.Errorf("AndPattern(%q) = %q, want %q", tt.expr, got, tt.want)
+			continue
+		}
+		if _, err := Parse(got); err != nil {
+			t.Errorf("AndPattern(%q) = %q does not parse: %v", tt.expr, got, err)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []logentry.Entry{
+		{Level: logentry.Debug, Message: "d", Timestamp: base, Source: "a.log"},
+		{Level: logentry.Warn, Message: "w", Timestamp: base.Add(time.Minute), Source: "a.log", Fields: map[string]any{"service": "auth"}},
+		{Level: logentry.Error, Message: "e", Timestamp: base.Add(2 * time.Minute), Source: "b.log", Fields: map[string]any{"service": "auth"}},
+		{Level: logentry.Error, Message: "healthz", Timestamp: base.Add(3 * time.Minute), Source: "a.log", Fields: map[string]any{"service": "api"}},
+		{Level: logentry.Fatal, Message: "no time", Source: "a.log"},
+	}
+
+	messages := func(entries []logentry.Entry) string {
+		var msgs []string
+		for _, e := range entries {
+			msgs = append(msgs, e.Message)
+		}
+		return strings.Join(msgs, ",")
+	}
+
+	p := NewPipeline()
+	if got := messages(p.Apply(entries)); got != "d,w,e,healthz,no time" {
+		t.Errorf("empty pipeline = %s, want every entry", got)
+	}
+
+	p.SetLevel(logentry.Warn)
+	if got := messages(p.Apply(entries)); got != "w,e,healthz,no time" {
+		t.Errorf("level threshold = %s", got)
+	}
+
+	excludes, err := NewExcludes([]string{"/healthz/"})
+	if err != nil {
+		t.Fatalf("NewExcludes() error = %v", err)
+	}
+	p.SetExcludes(excludes)
+	p.SetTimeRange(base.Add(30*time.Second), base.Add(5*time.Minute))
+	if got := messages(p.Apply(entries)); got != "w,e" {
+		t.Errorf("time range and excludes = %s", got)
+	}
+	if got := excludes.Suppressed(); got != 1 {
+		t.Errorf("Suppressed() = %d, want 1", got)
+	}
+
+	if err := p.SetExpression(`.service == "auth"`); err != nil {
+		t.Fatalf("SetExpression() error = %v", err)
+	}
+	p.SetSources("b.log")
+	if got := messages(p.Apply(entries)); got != "e" {
+		t.Errorf("all stages = %s, want e", got)
+	}
+	if err := p.SetExpression(".service =="); err == nil {
+		t.Error("SetExpression() with a bad expression should fail")
+	}
+	if got := p.Expression(); got != `.service == "auth"` {
+		t.Errorf("Expression() = %q, want previous expression kept after error", got)
+	}
+
+	want := `level>=WARN .service == "auth" since 2024-01-15 10:00:30 until 2024-01-15 10:05:00 sources:b.log excluded:0`
+	if got := p.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	suspended := p.Clone()
+	suspended.SetSuspended(true)
+	if got := messages(suspended.Apply(entries)); got != "d,w,e,no time" {
+		t.Errorf("suspended = %s, want every entry but the excluded one", got)
+	}
+	if got := suspended.String(); !strings.Contains(got, "(suspended)") {
+		t.Errorf("String() = %q, want the filter marked suspended", got)
+	}
+
+	p.SetSuspended(true)
+	p.Clear()
+	if p.IsFiltering() || p.Suspended() {
+		t.Error("IsFiltering() or Suspended() = true after Clear")
+	}
+	p.SetShowExcluded(true)
+	if got := messages(p.Append(entries[3:4])); got != "healthz" {
+		t.Errorf("Append() with excluded revealed = %s, want healthz", got)
+	}
+	if got := excludes.Suppressed(); got != 1 {
+		t.Errorf("Suppressed() = %d, want revealed entries still counted", got)
+	}
+}
+
+func TestPipeline_VisibleAndClone(t *testing.T) {
+	healthz := logentry.Entry{Level: logentry.Info, Message: "healthz", Source: "a.log"}
+	login := logentry.Entry{Level: logentry.Warn, Message: "login", Source: "a.log"}
+
+	excludes, err := NewExcludes([]string{"/healthz/"})
+	if err != nil {
+		t.Fatalf("NewExcludes() error = %v", err)
+	}
+	p := NewPipeline()
+	p.SetExcludes(excludes)
+	p.SetSources("a.log")
+
+	if p.Visible(healthz) {
+		t.Error("Visible() = true for an excluded entry")
+	}
+	if !p.Visible(login) {
+		t.Error("Visible() = false for a matching entry")
+	}
+	if got := excludes.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d, want Visible not to count", got)
+	}
+
+	c := p.Clone()
+	p.SetLevel(logentry.Error)
+	p.SetSources("b.log")
+	if _, err := excludes.Add("/login/"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if !c.Visible(login) {
+		t.Error("clone changed with the original pipeline")
+	}
+	c.Apply([]logentry.Entry{healthz})
+	if got := excludes.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d, want clone counts kept separate", got)
+	}
+}
+
+func TestPipelineJob(t *testing.T) {
+	var entries []logentry.Entry
+	for i := 0; i < 10; i++ {
+		level := logentry.Info
+		if i%2 == 0 {
+			level = logentry.Error
+		}
+		entries = append(entries, logentry.Entry{Level: level, Message: fmt.Sprintf("m%d", i), Raw: fmt.Sprintf("m%d", i)})
+	}
+
+	excludes, err := NewExcludes([]string{"/m0/"})
+	if err != nil {
+		t.Fatalf("NewExcludes() error = %v", err)
+	}
+	p := NewPipeline()
+	p.SetExcludes(excludes)
+	p.SetLevel(logentry.Error)
+
+	job := p.NewJob(1, entries)
+	// Changing the pipeline doesn't affect a running job.
+	p.SetLevel(logentry.Unknown)
+
+	var kept []logentry.Entry
+	for !job.Done() {
+		kept = append(kept, job.Next(context.Background(), 3)...)
+		if excludes.Suppressed() != 0 {
+			t.Fatal("Suppressed() changed before Commit")
+		}
+	}
+	if len(kept) != 4 {
+		t.Errorf("Next() kept %d entries, want 4", len(kept))
+	}
+	if job.Scanned() != job.Total() {
+		t.Errorf("Scanned() = %d, want %d", job.Scanned(), job.Total())
+	}
+	job.Commit()
+	if got := excludes.Suppressed(); got != 1 {
+		t.Errorf("Suppressed() = %d after Commit, want 1", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job = p.NewJob(2, entries)
+	if got := job.Next(ctx, len(entries)); len(got) != 0 || job.Done() {
+		t.Errorf("Next() after cancel = %d entries, Done() = %v", len(got), job.Done())
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "", want: time.Time{}},
+		{input: "1h ago", want: now.Add(-time.Hour)},
+		{input: "15m", want: now.Add(-15 * time.Minute)},
+		{input: "2024-01-14T08:30:00Z", want: time.Date(2024, 1, 14, 8, 30, 0, 0, time.UTC)},
+		{input: "2024-01-14 08:30", want: time.Date(2024, 1, 14, 8, 30, 0, 0, time.UTC)},
+		{input: "2024-01-14", want: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{input: "10:42:05", want: time.Date(2024, 1, 15, 10, 42, 5, 0, time.UTC)},
+		{input: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTime(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// cancelCheck is how many entries are filtered between checks for
+// cancellation.
+const cancelCheck = 1024
+
+// Job applies a pipeline to a list of entries a chunk at a time, so filtering
+// a large file can run in the background and show partial results. It works
+// on a copy of the pipeline taken when the job is created; Commit copies the
+// exclusion counts it gathered back once it is done.
+type Job struct {
+	ID int
+
+	orig    *Pipeline
+	p       *Pipeline
+	entries []logentry.Entry
+	pos     int
+}
+
+// NewJob prepares filtering entries with the pipeline's current settings.
+func (p *Pipeline) NewJob(id int, entries []logentry.Entry) *Job {
+	return &Job{ID: id, orig: p, p: p.Clone(), entries: entries}
+}
+
+// Total returns the number of entries the job filters.
+func (j *Job) Total() int {
+	return len(j.entries)
+}
+
+// Scanned returns the number of entries filtered so far.
+func (j *Job) Scanned() int {
+	return j.pos
+}
+
+// Done reports whether every entry has been filtered.
+func (j *Job) Done() bool {
+	return j.pos >= len(j.entries)
+}
+
+// Next filters up to n more entries and returns those that pass. It stops
+// early, without being done, when ctx is cancelled.
+func (j *Job) Next(ctx context.Context, n int) []logentry.Entry {
+	end := min(j.pos+n, len(j.entries))
+	var kept []logentry.Entry
+	for j.pos < end {
+		if ctx.Err() != nil {
+			break
+		}
+		step := min(j.pos+cancelCheck, end)
+		kept = append(kept, j.p.Append(j.entries[j.pos:step])...)
+		j.pos = step
+	}
+	return kept
+}
+
+// Commit copies the exclusion counts of a finished job to the pipeline it was
+// created from. Nothing is copied if the rules changed since.
+func (j *Job) Commit() {
+	from, to := j.p.excludes, j.orig.excludes
+	if !j.Done() || from.Len() != to.Len() {
+		return
+	}
+	for i, r := range from.rules {
+		if to.rules[i] != r {
+			return
+		}
+	}
+	copy(to.counts, from.counts)
+}
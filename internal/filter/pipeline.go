@@ -0,0 +1,299 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// Pipeline decides which entries are shown. It combines a minimum level, a
+// filter expression, a time window, a source selection and exclusion rules,
+// applied in that order. Exclusion rules run last so their counts reflect
+// entries that would otherwise be visible.
+type Pipeline struct {
+	level        logentry.Level
+	expr         string
+	compiled     *CompiledFilter
+	since        time.Time
+	until        time.Time
+	sources      map[string]bool
+	excludes     *Excludes
+	showExcluded bool
+	// suspended lets every entry through the stages before the exclusion
+	// rules while keeping them, to drop the filter for a moment
+	suspended bool
+}
+
+// NewPipeline creates a pipeline that lets every entry through.
+func NewPipeline() *Pipeline {
+	return &Pipeline{excludes: &Excludes{}}
+}
+
+// Level returns the minimum level, or logentry.Unknown if there is none.
+func (p *Pipeline) Level() logentry.Level {
+	return p.level
+}
+
+// SetLevel sets the minimum level. logentry.Unknown disables it.
+func (p *Pipeline) SetLevel(level logentry.Level) {
+	p.level = level
+}
+
+// Expression returns the filter expression.
+func (p *Pipeline) Expression() string {
+	return p.expr
+}
+
+// SetExpression parses and sets the filter expression. An empty expression
+// clears it. On error the previous expression is kept.
+func (p *Pipeline) SetExpression(expr string) error {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		p.expr = ""
+		p.compiled = nil
+		return nil
+	}
+
+	parsed, err := Parse(expr)
+	if err != nil {
+		return err
+	}
+	compiled, err := Compile(parsed)
+	if err != nil {
+		return err
+	}
+	p.expr = expr
+	p.compiled = compiled
+	return nil
+}
+
+// TimeRange returns the time window. Zero times are open ends.
+func (p *Pipeline) TimeRange() (since, until time.Time) {
+	return p.since, p.until
+}
+
+// SetTimeRange limits entries to [since, until]. Zero times leave that end open.
+func (p *Pipeline) SetTimeRange(since, until time.Time) {
+	p.since = since
+	p.until = until
+}
+
+// Sources returns the selected sources in sorted order. No sources means all
+// are shown.
+func (p *Pipeline) Sources() []string {
+	sources := make([]string, 0, len(p.sources))
+	for s := range p.sources {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// SetSources selects which sources are shown. Calling it without sources
+// shows all of them.
+func (p *Pipeline) SetSources(sources ...string) {
+	if len(sources) == 0 {
+		p.sources = nil
+		return
+	}
+	p.sources = make(map[string]bool, len(sources))
+	for _, s := range sources {
+		p.sources[s] = true
+	}
+}
+
+// Excludes returns the exclusion rules.
+func (p *Pipeline) Excludes() *Excludes {
+	return p.excludes
+}
+
+// SetExcludes replaces the exclusion rules.
+func (p *Pipeline) SetExcludes(excludes *Excludes) {
+	if excludes == nil {
+		excludes = &Excludes{}
+	}
+	p.excludes = excludes
+}
+
+// ShowExcluded reports whether entries hidden by exclusion rules are shown.
+func (p *Pipeline) ShowExcluded() bool {
+	return p.showExcluded
+}
+
+// SetShowExcluded temporarily shows or hides entries hidden by exclusion
+// rules. They are still counted either way.
+func (p *Pipeline) SetShowExcluded(show bool) {
+	p.showExcluded = show
+}
+
+// Suspended reports whether the filter is suspended.
+func (p *Pipeline) Suspended() bool {
+	return p.suspended
+}
+
+// SetSuspended suspends or resumes every stage except the exclusion rules.
+func (p *Pipeline) SetSuspended(suspended bool) {
+	p.suspended = suspended
+}
+
+// IsFiltering reports whether any stage other than the exclusion rules is set.
+func (p *Pipeline) IsFiltering() bool {
+	return p.level != logentry.Unknown || p.compiled != nil ||
+		!p.since.IsZero() || !p.until.IsZero() || len(p.sources) > 0
+}
+
+// Clear resets the level, expression, time window and source selection.
+// Exclusion rules are a separate layer and are kept.
+func (p *Pipeline) Clear() {
+	p.level = logentry.Unknown
+	p.expr = ""
+	p.compiled = nil
+	p.since = time.Time{}
+	p.until = time.Time{}
+	p.sources = nil
+	p.suspended = false
+}
+
+// Match reports whether entry passes every stage except the exclusion rules.
+func (p *Pipeline) Match(entry logentry.Entry) bool {
+	if p.suspended {
+		return true
+	}
+	if p.level != logentry.Unknown && entry.Level < p.level {
+		return false
+	}
+	if p.compiled != nil {
+		matches, err := p.compiled.Evaluate(entry)
+		if err != nil || !matches {
+			return false
+		}
+	}
+	if !p.since.IsZero() || !p.until.IsZero() {
+		if entry.Timestamp.IsZero() {
+			return false
+		}
+		if !p.since.IsZero() && entry.Timestamp.Before(p.since) {
+			return false
+		}
+		if !p.until.IsZero() && entry.Timestamp.After(p.until) {
+			return false
+		}
+	}
+	if len(p.sources) > 0 && !p.sources[entry.Source] {
+		return false
+	}
+	return true
+}
+
+// Visible reports whether entry passes the whole pipeline, without counting it
+// against the exclusion rules.
+func (p *Pipeline) Visible(entry logentry.Entry) bool {
+	if !p.Match(entry) {
+		return false
+	}
+	return p.showExcluded || p.excludes.Match(entry) < 0
+}
+
+// Clone returns a copy of the pipeline that can be used from another
+// goroutine while p keeps changing. The copy's exclusion counts are separate.
+func (p *Pipeline) Clone() *Pipeline {
+	c := *p
+	if p.sources != nil {
+		c.sources = make(map[string]bool, len(p.sources))
+		for s := range p.sources {
+			c.sources[s] = true
+		}
+	}
+	c.excludes = &Excludes{
+		rules:  append([]*ExcludeRule(nil), p.excludes.Rules()...),
+		counts: make([]int, p.excludes.Len()),
+	}
+	return &c
+}
+
+// Apply returns the entries that pass the pipeline, recounting the entries
+// suppressed by each exclusion rule.
+func (p *Pipeline) Apply(entries []logentry.Entry) []logentry.Entry {
+	p.excludes.Reset()
+	return p.Append(entries)
+}
+
+// Append is like Apply but adds to the existing exclusion counts. It is used
+// for entries arriving while tailing.
+func (p *Pipeline) Append(entries []logentry.Entry) []logentry.Entry {
+	kept := make([]logentry.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if !p.Match(entry) {
+			continue
+		}
+		if p.excludes.hide(entry) && !p.showExcluded {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// String describes the active stages, e.g.
+// `level>=WARN .service == "auth" since 10:00:00 excluded:12`.
+func (p *Pipeline) String() string {
+	var parts []string
+	if p.level != logentry.Unknown {
+		parts = append(parts, "level>="+p.level.String())
+	}
+	if p.expr != "" {
+		parts = append(parts, p.expr)
+	}
+	if !p.since.IsZero() {
+		parts = append(parts, "since "+p.since.Format(time.DateTime))
+	}
+	if !p.until.IsZero() {
+		parts = append(parts, "until "+p.until.Format(time.DateTime))
+	}
+	if len(p.sources) > 0 {
+		parts = append(parts, "sources:"+strings.Join(p.Sources(), ","))
+	}
+	if p.suspended && len(parts) > 0 {
+		parts = append(parts, "(suspended)")
+	}
+	if p.excludes.Len() > 0 {
+		state := "excluded"
+		if p.showExcluded {
+			state = "revealed"
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d", state, p.excludes.Suppressed()))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseTime parses a time bound for a time window. It accepts durations
+// relative to now ("15m", "1h ago"), RFC 3339 and common date-time layouts,
+// and a bare clock time ("10:42" or "10:42:05"), which means today.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(strings.TrimSpace(strings.TrimSuffix(s, "ago"))); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	for _, layout := range []string{time.TimeOnly, "15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			y, mo, d := now.Date()
+			return time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
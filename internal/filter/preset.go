@@ -7,14 +7,16 @@ import (
 )
 
 // Preset represents a predefined filter configuration.
+// Level, when set, is a minimum level applied on top of Expression.
 type Preset struct {
 	Name        string
 	Description string
 	Expression  string
+	Level       logentry.Level
 }
 
-// Presets is a collection of common filter presets.
-var Presets = []Preset{
+// builtinPresets are the common filter presets every registry starts with.
+var builtinPresets = []Preset{
 	{
 		Name:        "errors",
 		Description: "Show only error and fatal logs",
@@ -37,28 +39,61 @@ var Presets = []Preset{
 	},
 }
 
-// GetPreset retrieves a preset by name.
-func GetPreset(name string) (*Preset, bool) {
-	for _, p := range Presets {
+// PresetRegistry holds the presets that can be applied: the built-in ones
+// plus those registered from the config file or saved at runtime.
+type PresetRegistry struct {
+	presets []Preset
+}
+
+// NewPresetRegistry creates a registry holding the built-in presets.
+func NewPresetRegistry() *PresetRegistry {
+	return &PresetRegistry{presets: append([]Preset(nil), builtinPresets...)}
+}
+
+// All returns the presets in the order they were registered.
+func (r *PresetRegistry) All() []Preset {
+	return append([]Preset(nil), r.presets...)
+}
+
+// Get retrieves a preset by name.
+func (r *PresetRegistry) Get(name string) (Preset, bool) {
+	for _, p := range r.presets {
 		if p.Name == name {
-			return &p, true
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Register adds a preset, replacing any existing preset with the same name.
+func (r *PresetRegistry) Register(preset Preset) {
+	for i, p := range r.presets {
+		if p.Name == preset.Name {
+			r.presets[i] = preset
+			return
 		}
 	}
-	return nil, false
+	r.presets = append(r.presets, preset)
 }
 
 // PresetFilter represents a compiled preset filter.
 type PresetFilter struct {
 	compiled *CompiledFilter
+	level    logentry.Level
 }
 
-// NewPresetFilter creates a new filter from a preset name.
-func NewPresetFilter(name string) (*PresetFilter, error) {
-	preset, ok := GetPreset(name)
+// NewFilter creates a new filter from a preset name.
+func (r *PresetRegistry) NewFilter(name string) (*PresetFilter, error) {
+	preset, ok := r.Get(name)
 	if !ok {
 		return nil, fmt.Errorf("preset not found: %s", name)
 	}
 
+	pf := &PresetFilter{level: preset.Level}
+	if preset.Expression == "" {
+		return pf, nil
+	}
+
 	expr, err := Parse(preset.Expression)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse preset expression: %w", err)
@@ -69,14 +104,27 @@ func NewPresetFilter(name string) (*PresetFilter, error) {
 		return nil, fmt.Errorf("failed to compile preset: %w", err)
 	}
 
-	return &PresetFilter{compiled: compiled}, nil
+	pf.compiled = compiled
+	return pf, nil
 }
 
 // Evaluate evaluates the preset filter against an entry.
 func (pf *PresetFilter) Evaluate(entry logentry.Entry) (bool, error) {
+	if pf.level != logentry.Unknown && entry.Level < pf.level {
+		return false, nil
+	}
+	if pf.compiled == nil {
+		return true, nil
+	}
 	return pf.compiled.Evaluate(entry)
 }
 
+// Compiled returns the compiled expression of the preset, or nil if the
+// preset only sets a level.
+func (pf *PresetFilter) Compiled() *CompiledFilter {
+	return pf.compiled
+}
+
 // LevelToPreset returns the preset name for a given minimum level.
 func LevelToPreset(level logentry.Level) string {
 	switch {
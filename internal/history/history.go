@@ -0,0 +1,180 @@
+package history
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultLimit is the default number of entries kept per history.
+const DefaultLimit = 500
+
+// History is a deduplicated, size-limited list of previously submitted
+// inputs, persisted one entry per line.
+type History struct {
+	path    string
+	limit   int
+	entries []string // oldest first
+	mu      sync.Mutex
+	// unread is set when the file exists but could not be loaded, so Save
+	// doesn't replace the entries it holds.
+	unread bool
+}
+
+// New creates an empty History backed by the file at path.
+// An empty path keeps the history in memory only.
+func New(path string, limit int) *History {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &History{path: path, limit: limit}
+}
+
+// Open creates a History and loads any entries saved at path.
+func Open(path string, limit int) (*History, error) {
+	h := New(path, limit)
+	if err := h.Load(); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// Load reads the history file, replacing the in-memory entries.
+// A missing file is not an error. After any other error, Save leaves the
+// file alone.
+func (h *History) Load() error {
+	if h.path == "" {
+		return nil
+	}
+
+	lines, err := readLines(h.path)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.unread = true
+		return err
+	}
+
+	h.unread = false
+	h.entries = h.entries[:0]
+	for _, line := range lines {
+		h.add(line)
+	}
+	return nil
+}
+
+// readLines reads the lines of the file at path, however long they are. A
+// missing file has none.
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var lines []string
+	r := bufio.NewReader(file)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+	}
+}
+
+// Add records an entry as the most recent one. Blank entries are ignored and
+// an existing identical entry is moved to the end instead of duplicated.
+func (h *History) Add(entry string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.add(entry)
+}
+
+func (h *History) add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return
+	}
+	for i, e := range h.entries {
+		if e == entry {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+}
+
+// Entries returns a copy of the entries, oldest first.
+func (h *History) Entries() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.entries...)
+}
+
+// Recent returns up to n entries, most recent first.
+func (h *History) Recent(n int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n <= 0 || n > len(h.entries) {
+		n = len(h.entries)
+	}
+	recent := make([]string, 0, n)
+	for i := len(h.entries) - 1; i >= len(h.entries)-n; i-- {
+		recent = append(recent, h.entries[i])
+	}
+	return recent
+}
+
+// Save writes the history file, creating its directory if needed. A file
+// that failed to load is not overwritten.
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	unread := h.unread
+	h.mu.Unlock()
+	if unread {
+		return fmt.Errorf("history %s could not be loaded, not overwriting it", h.path)
+	}
+
+	entries := h.Entries()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e)
+		b.WriteString("\n")
+	}
+	if err := os.WriteFile(h.path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Path returns the file the history is persisted to.
+func (h *History) Path() string {
+	return h.path
+}
@@ -0,0 +1,99 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistory_AddDeduplicates(t *testing.T) {
+	h := New("", 10)
+	h.Add("error")
+	h.Add(".level >= 40")
+	h.Add("  ")
+	h.Add("error")
+
+	want := []string{".level >= 40", "error"}
+	if got := h.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %v, want %v", got, want)
+	}
+	if got := h.Recent(1); !reflect.DeepEqual(got, []string{"error"}) {
+		t.Errorf("Recent(1) = %v, want [error]", got)
+	}
+}
+
+func TestHistory_Limit(t *testing.T) {
+	h := New("", 2)
+	h.Add("a")
+	h.Add("b")
+	h.Add("c")
+
+	want := []string{"b", "c"}
+	if got := h.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %v, want %v", got, want)
+	}
+}
+
+func TestHistory_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "search_history")
+
+	h := New(path, 10)
+	h.Add("timeout")
+	h.Add(`.service == "auth"`)
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got, want := loaded.Entries(), h.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() after load = %v, want %v", got, want)
+	}
+}
+
+func TestHistory_OpenMissingFile(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), "nope"), 0)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(h.Entries()) != 0 {
+		t.Errorf("Entries() = %v, want empty", h.Entries())
+	}
+}
+
+func TestHistory_LongEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search_history")
+	long := "re:" + strings.Repeat("a", 100*1024)
+
+	h := New(path, 10)
+	h.Add("timeout")
+	h.Add(long)
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := loaded.Entries(); len(got) != 2 || got[1] != long {
+		t.Errorf("Entries() after load = %d entries, want timeout and the long entry", len(got))
+	}
+}
+
+func TestHistory_SaveKeepsUnreadableFile(t *testing.T) {
+	// A directory opens but can't be read as a file.
+	path := t.TempDir()
+
+	h, err := Open(path, 10)
+	if err == nil {
+		t.Fatal("Open() of an unreadable file should fail")
+	}
+	h.Add("timeout")
+	if err := h.Save(); err == nil || !strings.Contains(err.Error(), "not overwriting") {
+		t.Errorf("Save() error = %v, want a refusal to overwrite", err)
+	}
+}
@@ -54,7 +54,8 @@ func (p *Parser) ParseLine(raw string, lineNum int) logentry.Entry {
 	return entry
 }
 
-// ParseLines reads from a reader and parses all lines into Entries.
+// ParseLines reads from a reader and parses all lines into Entries. Each
+// entry's Offset is the byte offset of its line from the start of r.
 func (p *Parser) ParseLines(r io.Reader) ([]logentry.Entry, error) {
 	var entries []logentry.Entry
 
@@ -64,11 +65,15 @@ func (p *Parser) ParseLines(r io.Reader) ([]logentry.Entry, error) {
 	}
 
 	lines := strings.Split(string(data), "\n")
+	var offset int64
 	for i, line := range lines {
 		if i == len(lines)-1 && line == "" {
 			continue
 		}
-		entries = append(entries, p.ParseLine(line, i+1))
+		entry := p.ParseLine(line, i+1)
+		entry.Offset = offset
+		entries = append(entries, entry)
+		offset += int64(len(line)) + 1
 	}
 
 	return entries, nil
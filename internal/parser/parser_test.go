@@ -324,6 +324,12 @@ func TestParseLines(t *testing.T) {
 	if entries[2].Level != logentry.Error || entries[2].Message != "line3" {
 		t.Errorf("ParseLines()[2] = %+v, want Level=Error, Message=line3", entries[2])
 	}
+
+	for i, want := range []int64{0, 31, 63} {
+		if entries[i].Offset != want {
+			t.Errorf("ParseLines()[%d].Offset = %d, want %d", i, entries[i].Offset, want)
+		}
+	}
 }
 
 func TestParseLines_EmptyInput(t *testing.T) {
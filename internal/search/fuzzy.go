@@ -12,9 +12,12 @@ import (
 // SearchResult represents a search match in a log entry.
 type SearchResult struct {
 	Entry    logentry.Entry
-	Position int
-	Matched  []string // matched field values
-	Score    float64  // match score (0-1, higher is better)
+	ID       logentry.ID // stable identity of the entry
+	Position int         // index of the entry in the searched list
+	Matched  []string    // matched field values
+	Field    int         // index in Matched of the field Span is in
+	Span     [2]int      // byte range of the first match in that field's value
+	Score    float64     // match score (0-1, higher is better)
 }
 
 // FuzzyMatch performs fuzzy matching against log entries.
@@ -43,6 +46,18 @@ func FuzzyMatch(entries []logentry.Entry, query string) []SearchResult {
 	return sortByScore(results)
 }
 
+// FuzzyScore scores text against query the way FuzzyMatch scores entries:
+// 1 when text contains query, less when the query's letters only appear in
+// order from its start, and 0 when they don't match.
+func FuzzyScore(text, query string) float64 {
+	if query == "" {
+		return 0
+	}
+	query = strings.ToLower(query)
+	score, _ := fuzzyStringMatch(text, query, []rune(query))
+	return score
+}
+
 func fuzzySearchEntry(entry logentry.Entry, query string, queryRunes []rune) (float64, []string) {
 	var maxScore float64
 	var bestMatched []string
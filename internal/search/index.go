@@ -0,0 +1,312 @@
+package search
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// indexChunk is how many entries are indexed per lock acquisition, so
+// searches can run while a large file is still being indexed.
+const indexChunk = 4096
+
+// Index is a token and trigram index over the searchable text of entries:
+// message, caller and field values. Entries are identified by their position
+// in the list the index is built over. Substring and regex queries use it to
+// narrow down which entries need to be scanned.
+//
+// Index is safe for concurrent use. Entries are added in the background while
+// loading and tailing; entries not indexed yet are always candidates.
+type Index struct {
+	mu       sync.RWMutex
+	trigrams map[string][]uint32
+	tokens   map[string][]uint32
+	n        int
+	pending  map[int][]logentry.Entry
+}
+
+// NewIndex creates an empty index.
+func NewIndex() *Index {
+	return &Index{
+		trigrams: make(map[string][]uint32),
+		tokens:   make(map[string][]uint32),
+		pending:  make(map[int][]logentry.Entry),
+	}
+}
+
+// Len returns the number of indexed entries.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.n
+}
+
+// Add indexes entries whose ids start at base. Batches may arrive out of
+// order; a batch is held back until all entries before it are indexed.
+func (ix *Index) Add(base int, entries []logentry.Entry) {
+	ix.mu.Lock()
+	if base != ix.n {
+		if base > ix.n {
+			ix.pending[base] = entries
+		}
+		ix.mu.Unlock()
+		return
+	}
+	ix.mu.Unlock()
+
+	for {
+		for start := 0; start < len(entries); start += indexChunk {
+			end := start + indexChunk
+			if end > len(entries) {
+				end = len(entries)
+			}
+			ix.mu.Lock()
+			for _, entry := range entries[start:end] {
+				ix.addLocked(entry)
+			}
+			ix.mu.Unlock()
+		}
+
+		ix.mu.Lock()
+		next, ok := ix.pending[ix.n]
+		delete(ix.pending, ix.n)
+		ix.mu.Unlock()
+		if !ok {
+			return
+		}
+		entries = next
+	}
+}
+
+func (ix *Index) addLocked(entry logentry.Entry) {
+	id := uint32(ix.n)
+	ix.n++
+
+	seenTrigrams := make(map[string]bool)
+	seenTokens := make(map[string]bool)
+	for _, text := range searchableText(entry) {
+		text = strings.ToLower(text)
+		for i := 0; i+3 <= len(text); i++ {
+			seenTrigrams[text[i:i+3]] = true
+		}
+		for _, tok := range tokenize(text) {
+			seenTokens[tok] = true
+		}
+	}
+	for t := range seenTrigrams {
+		ix.trigrams[t] = append(ix.trigrams[t], id)
+	}
+	for t := range seenTokens {
+		ix.tokens[t] = append(ix.tokens[t], id)
+	}
+}
+
+// searchableText returns the texts SmartMatch and RegexMatch look at.
+func searchableText(entry logentry.Entry) []string {
+	texts := make([]string, 0, 2+len(entry.Fields))
+	texts = append(texts, entry.Message)
+	if entry.Caller != "" {
+		texts = append(texts, entry.Caller)
+	}
+	for _, value := range entry.Fields {
+		if s, ok := valueToString(value); ok {
+			texts = append(texts, s)
+		}
+	}
+	return texts
+}
+
+// tokenize splits lower-cased text into runs of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Candidates returns the ids, in ascending order, of the entries among the
+// first total that may match q. It returns false when the index cannot
+// narrow the search, e.g. for fuzzy queries, and every entry must be scanned.
+func (ix *Index) Candidates(q Query, total int) ([]int, bool) {
+	literals, ok := q.requiredLiterals()
+	if !ok {
+		return nil, false
+	}
+
+	ix.mu.RLock()
+	indexed := ix.n
+	if indexed > total {
+		indexed = total
+	}
+	var ids []uint32
+	first := true
+	for _, lit := range literals {
+		var matched []uint32
+		if len(lit) >= 3 {
+			matched = ix.trigramMatchesLocked(lit)
+		} else {
+			matched = ix.tokenMatchesLocked(lit)
+		}
+		if first {
+			ids = matched
+			first = false
+		} else {
+			ids = intersect(ids, matched)
+		}
+	}
+	ix.mu.RUnlock()
+
+	result := make([]int, 0, len(ids)+total-indexed)
+	for _, id := range ids {
+		if int(id) < indexed {
+			result = append(result, int(id))
+		}
+	}
+	// Entries not indexed yet have to be scanned.
+	for id := indexed; id < total; id++ {
+		result = append(result, id)
+	}
+	return result, true
+}
+
+// trigramMatchesLocked returns the entries containing every trigram of lit.
+func (ix *Index) trigramMatchesLocked(lit string) []uint32 {
+	var ids []uint32
+	for i := 0; i+3 <= len(lit); i++ {
+		postings := ix.trigrams[lit[i:i+3]]
+		if i == 0 {
+			ids = postings
+		} else {
+			ids = intersect(ids, postings)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+	}
+	return ids
+}
+
+// tokenMatchesLocked returns the entries with a token containing lit, for
+// literals too short to have trigrams.
+func (ix *Index) tokenMatchesLocked(lit string) []uint32 {
+	seen := make(map[uint32]bool)
+	for tok, postings := range ix.tokens {
+		if strings.Contains(tok, lit) {
+			for _, id := range postings {
+				seen[id] = true
+			}
+		}
+	}
+	ids := make([]uint32, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// intersect returns the ids present in both sorted lists.
+func intersect(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// requiredLiterals returns lower-cased strings every match of q contains.
+// It returns false if q can't be narrowed down that way.
+func (q Query) requiredLiterals() ([]string, bool) {
+	if q.Text == "" || q.Invert {
+		return nil, false
+	}
+
+	var literals []string
+	switch q.Mode {
+	case ModeSmart:
+		literals = []string{strings.ToLower(q.Text)}
+	case ModeRegex:
+		for _, p := range q.patterns() {
+			lits, ok := regexLiterals(p)
+			if !ok {
+				return nil, false
+			}
+			literals = append(literals, lits...)
+		}
+	case ModeField:
+		lits, ok := regexLiterals("(?i)" + q.Text)
+		if !ok {
+			return nil, false
+		}
+		literals = lits
+	default:
+		return nil, false
+	}
+
+	// Tokens only help when the literal has no separators in it.
+	var usable []string
+	for _, lit := range literals {
+		if len(lit) >= 3 || len(tokenize(lit)) == 1 && tokenize(lit)[0] == lit {
+			usable = append(usable, lit)
+		}
+	}
+	if len(usable) == 0 {
+		return nil, false
+	}
+	return usable, true
+}
+
+// regexLiterals extracts literal strings that every match of pattern contains.
+func regexLiterals(pattern string) ([]string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	lits := literalsOf(re.Simplify())
+	return lits, len(lits) > 0
+}
+
+func literalsOf(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{strings.ToLower(string(re.Rune))}
+	case syntax.OpCapture, syntax.OpPlus:
+		return literalsOf(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return literalsOf(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var lits []string
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				lits = append(lits, run.String())
+				run.Reset()
+			}
+		}
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run.WriteString(strings.ToLower(string(sub.Rune)))
+				continue
+			}
+			flush()
+			lits = append(lits, literalsOf(sub)...)
+		}
+		flush()
+		return lits
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// cancelCheck is how many entries are scanned between checks for
+// cancellation.
+const cancelCheck = 1024
+
+// entryMatcher matches a single entry, returning its score and matched fields.
+type entryMatcher func(entry logentry.Entry) (float64, []string, bool)
+
+// matcher compiles the query into a per-entry matcher using the same rules as
+// Run.
+func (q Query) matcher() (entryMatcher, error) {
+	switch q.Mode {
+	case ModeFuzzy:
+		query := strings.ToLower(q.Text)
+		queryRunes := []rune(query)
+		return func(entry logentry.Entry) (float64, []string, bool) {
+			score, matched := fuzzySearchEntry(entry, query, queryRunes)
+			return score, matched, score > 0
+		}, nil
+	case ModeRegex:
+		if q.Invert {
+			re, err := regexp.Compile(strings.Join(q.patterns(), "|"))
+			if err != nil {
+				return nil, err
+			}
+			return func(entry logentry.Entry) (float64, []string, bool) {
+				return 1.0, []string{}, len(regexSearchEntry(entry, re)) == 0
+			}, nil
+		}
+		var regexes []*regexp.Regexp
+		for _, p := range q.patterns() {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, err
+			}
+			regexes = append(regexes, re)
+		}
+		return func(entry logentry.Entry) (float64, []string, bool) {
+			var matched []string
+			for _, re := range regexes {
+				fields := regexSearchEntry(entry, re)
+				if len(fields) == 0 {
+					return 0, nil, false
+				}
+				matched = append(matched, fields...)
+			}
+			score := float64(len(matched)) / 5.0
+			if score > 1.0 {
+				score = 1.0
+			}
+			return score, matched, true
+		}, nil
+	case ModeField:
+		re, err := regexp.Compile("(?i)" + q.Text)
+		if err != nil {
+			return nil, err
+		}
+		return func(entry logentry.Entry) (float64, []string, bool) {
+			value, found := fieldMatch(entry, q.Field, re)
+			if !found {
+				return 0, nil, false
+			}
+			return 1.0, []string{q.Field + ": " + highlightMatch(value, re)}, true
+		}, nil
+	default:
+		query := strings.ToLower(q.Text)
+		return func(entry logentry.Entry) (float64, []string, bool) {
+			matched := smartSearchEntry(entry, query)
+			if len(matched) == 0 {
+				return 0, nil, false
+			}
+			return calculateRelevanceScore(entry, matched), matched, true
+		}, nil
+	}
+}
+
+// spanner compiles the query into a function returning its spans in a text,
+// like Spans but for any field. Queries that don't compile have no spans.
+func (q Query) spanner() func(text string) [][2]int {
+	none := func(string) [][2]int { return nil }
+	switch {
+	case q.Invert:
+		return none
+	case q.Mode == ModeFuzzy:
+		substring := regexp.MustCompile("(?i)" + regexp.QuoteMeta(q.Text))
+		return func(text string) [][2]int { return fuzzySpans(text, q.Text, substring) }
+	case q.Mode == ModeRegex:
+		var regexes []*regexp.Regexp
+		for _, p := range q.patterns() {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return none
+			}
+			regexes = append(regexes, re)
+		}
+		return func(text string) [][2]int {
+			var spans [][2]int
+			for _, re := range regexes {
+				spans = append(spans, regexSpans(text, re)...)
+			}
+			return mergeSpans(spans)
+		}
+	case q.Mode == ModeField:
+		re, err := regexp.Compile("(?i)" + q.Text)
+		if err != nil {
+			return none
+		}
+		return func(text string) [][2]int { return regexSpans(text, re) }
+	default:
+		re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(q.Text))
+		return func(text string) [][2]int { return regexSpans(text, re) }
+	}
+}
+
+// matchedText returns the value of a "key: value" Matched field of entry.
+func matchedText(entry logentry.Entry, matched string) string {
+	name, _, _ := strings.Cut(matched, ": ")
+	switch name {
+	case "message", "msg":
+		return entry.Message
+	case "caller", "source":
+		return entry.Caller
+	}
+	s, _ := valueToString(entry.Fields[name])
+	return s
+}
+
+// Job runs a query over a list of entries a chunk at a time, so results can
+// be shown while a search over a large file is still running. Results come
+// back in list order and their Position is the entry's index in the list.
+//
+// A Job is not safe for concurrent use, but the entries it searches may be
+// appended to while it runs; it only looks at the entries it was created with.
+type Job struct {
+	ID    int
+	Query Query
+	// Keep, if set, drops entries it returns false for, e.g. entries hidden
+	// by the filter pipeline. It is called from the goroutine running Next.
+	Keep func(entry logentry.Entry) bool
+
+	entries []logentry.Entry
+	ids     []int // candidate positions from the index; nil scans every entry
+	match   entryMatcher
+	spans   func(text string) [][2]int
+	pos     int
+}
+
+// NewJob prepares a search of entries. If ix is not nil and covers exactly
+// entries it is used to skip entries that can't match; an index still being
+// filled, or built over another list, is ignored and every entry is scanned.
+func NewJob(id int, q Query, entries []logentry.Entry, ix *Index) (*Job, error) {
+	j := &Job{ID: id, Query: q, entries: entries}
+	if q.Text == "" {
+		j.pos = len(entries)
+		return j, nil
+	}
+
+	match, err := q.matcher()
+	if err != nil {
+		return nil, err
+	}
+	j.match = match
+	j.spans = q.spanner()
+
+	if ix != nil && ix.Len() == len(entries) {
+		if ids, ok := ix.Candidates(q, len(entries)); ok {
+			j.ids = ids
+		}
+	}
+	return j, nil
+}
+
+// Total returns the number of entries the job scans.
+func (j *Job) Total() int {
+	if j.ids != nil {
+		return len(j.ids)
+	}
+	return len(j.entries)
+}
+
+// Scanned returns the number of entries scanned so far.
+func (j *Job) Scanned() int {
+	return min(j.pos, j.Total())
+}
+
+// Done reports whether every entry has been scanned.
+func (j *Job) Done() bool {
+	return j.pos >= j.Total()
+}
+
+// Next scans up to n more entries and returns the matches among them. It stops
+// early, without being done, when ctx is cancelled.
+func (j *Job) Next(ctx context.Context, n int) []SearchResult {
+	var results []SearchResult
+	end := min(j.pos+n, j.Total())
+	for ; j.pos < end; j.pos++ {
+		if j.pos%cancelCheck == 0 && ctx.Err() != nil {
+			break
+		}
+		i := j.pos
+		if j.ids != nil {
+			i = j.ids[j.pos]
+		}
+		entry := j.entries[i]
+		if j.Keep != nil && !j.Keep(entry) {
+			continue
+		}
+		score, matched, ok := j.match(entry)
+		if !ok {
+			continue
+		}
+		result := SearchResult{
+			Entry:    entry,
+			ID:       entry.ID(),
+			Position: i,
+			Matched:  matched,
+			Score:    score,
+		}
+		for f, m := range matched {
+			if spans := j.spans(matchedText(entry, m)); len(spans) > 0 {
+				result.Field = f
+				result.Span = spans[0]
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
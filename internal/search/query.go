@@ -0,0 +1,287 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// Mode selects how a search query is matched against entries.
+type Mode int
+
+const (
+	// ModeSmart matches case-insensitive substrings (SmartMatch).
+	ModeSmart Mode = iota
+	// ModeFuzzy matches query characters in order with gaps (FuzzyMatch).
+	ModeFuzzy
+	// ModeRegex matches regular expressions. Patterns joined with "&&" must
+	// all match.
+	ModeRegex
+	// ModeField matches a regular expression against a single field.
+	ModeField
+)
+
+// String returns the mode name shown in the search bar.
+func (m Mode) String() string {
+	switch m {
+	case ModeFuzzy:
+		return "fuzzy"
+	case ModeRegex:
+		return "regex"
+	case ModeField:
+		return "field"
+	default:
+		return "smart"
+	}
+}
+
+// Prefix returns the query prefix that selects the mode.
+func (m Mode) Prefix() string {
+	switch m {
+	case ModeFuzzy:
+		return "f:"
+	case ModeRegex:
+		return "re:"
+	default:
+		return ""
+	}
+}
+
+// Next returns the mode after m when cycling smart, fuzzy and regex.
+func (m Mode) Next() Mode {
+	switch m {
+	case ModeSmart:
+		return ModeFuzzy
+	case ModeFuzzy:
+		return ModeRegex
+	default:
+		return ModeSmart
+	}
+}
+
+// fieldQueryPattern matches field-scoped queries such as "service:auth".
+var fieldQueryPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*):(.+)$`)
+
+// Query is a parsed search query.
+type Query struct {
+	Mode Mode
+	// Text is the query without its mode prefix.
+	Text string
+	// Field is the field searched in ModeField.
+	Field string
+	// Invert selects entries that don't match, for "!re:" queries.
+	Invert bool
+}
+
+// ParseQuery parses a search bar query. "re:" selects regex mode and "!re:"
+// its inverse, "f:" selects fuzzy mode, and "field:value" searches a single
+// field. Anything else is a smart search.
+//
+// A colon is common in plain text ("error: connection refused", URLs), so
+// "field:value" only searches a field when known, if not nil, reports that
+// the entries have it and no space follows the colon; otherwise the whole
+// query is a smart search.
+func ParseQuery(s string, known func(field string) bool) Query {
+	switch {
+	case strings.HasPrefix(s, "!re:"):
+		return Query{Mode: ModeRegex, Text: s[len("!re:"):], Invert: true}
+	case strings.HasPrefix(s, "re:"):
+		return Query{Mode: ModeRegex, Text: s[len("re:"):]}
+	case strings.HasPrefix(s, "f:"):
+		return Query{Mode: ModeFuzzy, Text: s[len("f:"):]}
+	}
+	if m := fieldQueryPattern.FindStringSubmatch(s); m != nil && isFieldQuery(m[1], m[2], known) {
+		return Query{Mode: ModeField, Field: m[1], Text: m[2]}
+	}
+	return Query{Mode: ModeSmart, Text: s}
+}
+
+// isFieldQuery reports whether "field:text" searches field: text doesn't
+// read as prose or a URL, and the field is one of the entry's own, such as
+// message, or one known reports. Nested fields are known by their first
+// part.
+func isFieldQuery(field, text string, known func(field string) bool) bool {
+	if strings.HasPrefix(text, "//") || unicode.IsSpace(rune(text[0])) {
+		return false
+	}
+	switch field {
+	case "message", "msg", "caller", "source":
+		return true
+	}
+	if known == nil {
+		return true
+	}
+	head, _, _ := strings.Cut(field, ".")
+	return known(head)
+}
+
+// String returns the query as typed in the search bar.
+func (q Query) String() string {
+	switch {
+	case q.Mode == ModeField:
+		return q.Field + ":" + q.Text
+	case q.Invert:
+		return "!" + q.Mode.Prefix() + q.Text
+	default:
+		return q.Mode.Prefix() + q.Text
+	}
+}
+
+// Label describes the mode for display, e.g. "regex" or "field:service".
+func (q Query) Label() string {
+	label := q.Mode.String()
+	if q.Mode == ModeField {
+		label += ":" + q.Field
+	}
+	if q.Invert {
+		label = "!" + label
+	}
+	return label
+}
+
+// Run searches entries with the query's mode.
+func (q Query) Run(entries []logentry.Entry) ([]SearchResult, error) {
+	if q.Text == "" {
+		return nil, nil
+	}
+
+	switch q.Mode {
+	case ModeFuzzy:
+		return FuzzyMatch(entries, q.Text), nil
+	case ModeRegex:
+		patterns := q.patterns()
+		if q.Invert {
+			return RegexExcludeMatch(entries, strings.Join(patterns, "|"))
+		}
+		if len(patterns) > 1 {
+			return RegexAndMatch(entries, patterns)
+		}
+		return RegexMatch(entries, patterns[0])
+	case ModeField:
+		return RegexFieldMatch(entries, q.Field, "(?i)"+q.Text)
+	default:
+		return SmartMatch(entries, q.Text), nil
+	}
+}
+
+// patterns splits a regex query on "&&", applying smart case: patterns
+// without upper case letters match case-insensitively.
+func (q Query) patterns() []string {
+	parts := strings.Split(q.Text, "&&")
+	patterns := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !hasUpper(p) {
+			p = "(?i)" + p
+		}
+		patterns = append(patterns, p)
+	}
+	if len(patterns) == 0 {
+		patterns = append(patterns, q.Text)
+	}
+	return patterns
+}
+
+// Spans returns the byte ranges of text matched by the query, for
+// highlighting. Field queries only highlight when they search the message.
+func (q Query) Spans(text string) [][2]int {
+	return q.Spanner()(text)
+}
+
+// Spanner compiles the query once into a function returning Spans of a text,
+// for highlighting many texts with the same query.
+func (q Query) Spanner() func(text string) [][2]int {
+	if q.Text == "" || q.Mode == ModeField && q.Field != "message" && q.Field != "msg" {
+		return func(string) [][2]int { return nil }
+	}
+	return q.spanner()
+}
+
+func regexSpans(text string, re *regexp.Regexp) [][2]int {
+	var spans [][2]int
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		if m[1] > m[0] {
+			spans = append(spans, [2]int{m[0], m[1]})
+		}
+	}
+	return spans
+}
+
+// fuzzySpans returns the characters of text matched by a fuzzy query, using
+// the same rules as FuzzyMatch: a substring match wins, otherwise the query
+// characters are matched in order starting at the first character. substring
+// is the query compiled as a case-insensitive literal.
+func fuzzySpans(text, query string, substring *regexp.Regexp) [][2]int {
+	if spans := regexSpans(text, substring); len(spans) > 0 {
+		return spans
+	}
+
+	queryRunes := []rune(strings.ToLower(query))
+	first, _ := utf8.DecodeRuneInString(text)
+	if unicode.ToLower(first) != queryRunes[0] {
+		return nil
+	}
+
+	var spans [][2]int
+	qi := 0
+	for i, r := range text {
+		if qi == len(queryRunes) {
+			break
+		}
+		if unicode.ToLower(r) == queryRunes[qi] {
+			spans = append(spans, [2]int{i, i + utf8.RuneLen(r)})
+			qi++
+		}
+	}
+	if qi < len(queryRunes) {
+		return nil
+	}
+	return mergeSpans(spans)
+}
+
+// mergeSpans sorts spans and joins overlapping or adjacent ones.
+func mergeSpans(spans [][2]int) [][2]int {
+	if len(spans) < 2 {
+		return spans
+	}
+	for i := 1; i < len(spans); i++ {
+		for j := i; j > 0 && spans[j][0] < spans[j-1][0]; j-- {
+			spans[j], spans[j-1] = spans[j-1], spans[j]
+		}
+	}
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s[0] <= last[1] {
+			if s[1] > last[1] {
+				last[1] = s[1]
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// hasUpper reports whether a pattern has upper case letters, skipping
+// escapes such as \S and \W, which are character classes, not letters.
+func hasUpper(s string) bool {
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case unicode.IsUpper(r):
+			return true
+		}
+	}
+	return false
+}
@@ -112,26 +112,7 @@ func RegexFieldMatch(entries []logentry.Entry, fieldName, pattern string) ([]Sea
 	var results []SearchResult
 
 	for _, entry := range entries {
-		var value string
-		var found bool
-
-		switch fieldName {
-		case "message", "msg":
-			value = entry.Message
-			found = re.MatchString(value)
-		case "caller", "source":
-			value = entry.Caller
-			found = re.MatchString(value)
-		default:
-			if val, ok := entry.GetField(fieldName); ok {
-				value, ok = valueToString(val)
-				if ok {
-					found = re.MatchString(value)
-				}
-			}
-		}
-
-		if found {
+		if value, found := fieldMatch(entry, fieldName, re); found {
 			results = append(results, SearchResult{
 				Entry:   entry,
 				Score:   1.0,
@@ -143,6 +124,22 @@ func RegexFieldMatch(entries []logentry.Entry, fieldName, pattern string) ([]Sea
 	return results, nil
 }
 
+// fieldMatch returns the value of fieldName in entry and whether re matches it.
+func fieldMatch(entry logentry.Entry, fieldName string, re *regexp.Regexp) (string, bool) {
+	switch fieldName {
+	case "message", "msg":
+		return entry.Message, re.MatchString(entry.Message)
+	case "caller", "source":
+		return entry.Caller, re.MatchString(entry.Caller)
+	}
+	val, ok := entry.GetField(fieldName)
+	if !ok {
+		return "", false
+	}
+	value, ok := valueToString(val)
+	return value, ok && re.MatchString(value)
+}
+
 // RegexMultiMatch performs regex matching with multiple patterns (OR logic).
 func RegexMultiMatch(entries []logentry.Entry, patterns []string) ([]SearchResult, error) {
 	if len(patterns) == 0 {
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ersanisk/sieve/pkg/logentry"
@@ -63,6 +64,22 @@ func TestFuzzyMatch(t *testing.T) {
 	}
 }
 
+func TestFuzzyScore(t *testing.T) {
+	if got := FuzzyScore("Toggle line wrap", "WRAP"); got != 1 {
+		t.Errorf("FuzzyScore(substring) = %v, want 1", got)
+	}
+	in := FuzzyScore("Toggle line wrap", "tlw")
+	if in <= 0 || in >= 1 {
+		t.Errorf("FuzzyScore(letters in order) = %v, want between 0 and 1", in)
+	}
+	if got := FuzzyScore("Toggle line wrap", "wlt"); got != 0 {
+		t.Errorf("FuzzyScore(letters out of order) = %v, want 0", got)
+	}
+	if got := FuzzyScore("Toggle line wrap", ""); got != 0 {
+		t.Errorf("FuzzyScore(empty query) = %v, want 0", got)
+	}
+}
+
 func TestSmartMatch(t *testing.T) {
 	entries := []logentry.Entry{
 		{
@@ -344,3 +361,338 @@ func TestValueToString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Query
+	}{
+		{input: "timeout", want: Query{Mode: ModeSmart, Text: "timeout"}},
+		{input: "re:time.*out", want: Query{Mode: ModeRegex, Text: "time.*out"}},
+		{input: "!re:health", want: Query{Mode: ModeRegex, Text: "health", Invert: true}},
+		{input: "f:tmout", want: Query{Mode: ModeFuzzy, Text: "tmout"}},
+		{input: "service:auth", want: Query{Mode: ModeField, Field: "service", Text: "auth"}},
+		{input: "service.name:auth", want: Query{Mode: ModeField, Field: "service.name", Text: "auth"}},
+		{input: "msg:login", want: Query{Mode: ModeField, Field: "msg", Text: "login"}},
+		{input: "http://example.com", want: Query{Mode: ModeSmart, Text: "http://example.com"}},
+		{input: "error:disk", want: Query{Mode: ModeField, Field: "error", Text: "disk"}},
+		{input: "error: connection refused", want: Query{Mode: ModeSmart, Text: "error: connection refused"}},
+		{input: "user:bob", want: Query{Mode: ModeSmart, Text: "user:bob"}},
+		{input: "no colon here:", want: Query{Mode: ModeSmart, Text: "no colon here:"}},
+	}
+	known := func(field string) bool { return field == "service" || field == "error" }
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ParseQuery(tt.input, known)
+			if got != tt.want {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if got.String() != tt.input {
+				t.Errorf("String() = %q, want round trip to %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestQuery_Run(t *testing.T) {
+	entries := []logentry.Entry{
+		{Message: "Connection timeout", Fields: map[string]any{"service": "auth"}},
+		{Message: "request served", Fields: map[string]any{"service": "api"}},
+		{Message: "healthz ok", Fields: map[string]any{"service": "api"}},
+	}
+
+	tests := []struct {
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{query: "timeout", want: 1},
+		{query: "f:cnntmt", want: 1},
+		{query: "re:^(request|healthz)", want: 2},
+		{query: "re:REQUEST", want: 0},
+		{query: "re:served && request", want: 1},
+		{query: "!re:healthz", want: 2},
+		{query: "service:^api$", want: 2},
+		{query: "re:[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := ParseQuery(tt.query, nil).Run(entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(results) != tt.want {
+				t.Errorf("Run() got %d results, want %d", len(results), tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_Spans(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  [][2]int
+	}{
+		{query: "OUT", text: "timeout out", want: [][2]int{{4, 7}, {8, 11}}},
+		{query: "re:t.me", text: "Time time", want: [][2]int{{0, 4}, {5, 9}}},
+		{query: "re:ab && bc", text: "abc", want: [][2]int{{0, 3}}},
+		{query: `re:\Wout`, text: "time OUT", want: [][2]int{{4, 8}}},
+		{query: `re:\WOUT`, text: "time out", want: nil},
+		{query: "f:cto", text: "Connection timeout", want: [][2]int{{0, 1}, {6, 7}, {8, 9}}},
+		{query: "f:xyz", text: "Connection", want: nil},
+		{query: "message:serv", text: "request served", want: [][2]int{{8, 12}}},
+		{query: "service:serv", text: "request served", want: nil},
+		{query: "!re:serv", text: "request served", want: nil},
+		{query: "é", text: "ÉÉ", want: [][2]int{{0, 2}, {2, 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := ParseQuery(tt.query, nil).Spans(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Spans(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Spans(%q) = %v, want %v", tt.text, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestQuery_Spanner(t *testing.T) {
+	spanner := ParseQuery("re:t.me", nil).Spanner()
+	for text, want := range map[string]int{"Time time": 2, "status ok": 0, "a tame": 1} {
+		if got := spanner(text); len(got) != want {
+			t.Errorf("spanner(%q) = %v, want %d spans", text, got, want)
+		}
+	}
+
+	if got := ParseQuery("service:auth", nil).Spanner()("auth"); got != nil {
+		t.Errorf("field spanner = %v, want no spans outside the message", got)
+	}
+}
+
+func indexTestEntries() []logentry.Entry {
+	return []logentry.Entry{
+		{Message: "Connection timeout", Caller: "db.go:12", Fields: map[string]any{"service": "auth"}},
+		{Message: "request served", Fields: map[string]any{"service": "api", "status": 200}},
+		{Message: "healthz ok", Fields: map[string]any{"service": "api"}},
+		{Message: "user login failed", Fields: map[string]any{"user": "Alice", "service": "auth"}},
+		{Message: "ok", Fields: map[string]any{"status": 500}},
+	}
+}
+
+func TestIndex_Candidates(t *testing.T) {
+	entries := indexTestEntries()
+	ix := NewIndex()
+	ix.Add(0, entries)
+
+	tests := []struct {
+		query      string
+		wantNarrow bool
+		maxIDs     int
+	}{
+		{query: "timeout", wantNarrow: true, maxIDs: 1},
+		{query: "ok", wantNarrow: true, maxIDs: 2},
+		{query: "alice", wantNarrow: true, maxIDs: 1},
+		{query: "re:serv(ed|ice)", wantNarrow: true, maxIDs: 5},
+		{query: "re:log.n && fail", wantNarrow: true, maxIDs: 1},
+		{query: "re:^h.*z", wantNarrow: true, maxIDs: 5},
+		{query: "re:.*", wantNarrow: false},
+		{query: "status:50", wantNarrow: true, maxIDs: 1},
+		{query: "f:cnt", wantNarrow: false},
+		{query: "!re:ok", wantNarrow: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := ParseQuery(tt.query, nil)
+			ids, ok := ix.Candidates(q, len(entries))
+			if ok != tt.wantNarrow {
+				t.Fatalf("Candidates() ok = %v, want %v", ok, tt.wantNarrow)
+			}
+			if !ok {
+				return
+			}
+			if len(ids) > tt.maxIDs {
+				t.Errorf("Candidates() = %v, want at most %d ids", ids, tt.maxIDs)
+			}
+
+			// Every matching entry must be a candidate.
+			isCandidate := make(map[int]bool)
+			for _, id := range ids {
+				isCandidate[id] = true
+			}
+			for i, entry := range entries {
+				results, err := q.Run([]logentry.Entry{entry})
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				if len(results) > 0 && !isCandidate[i] {
+					t.Errorf("Candidates() = %v, missing matching entry %d", ids, i)
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_AddOutOfOrder(t *testing.T) {
+	entries := indexTestEntries()
+	ix := NewIndex()
+
+	ix.Add(3, entries[3:])
+	if ix.Len() != 0 {
+		t.Fatalf("Len() = %d, want 0 while earlier entries are missing", ix.Len())
+	}
+
+	// Unindexed entries are always candidates.
+	ids, _ := ix.Candidates(ParseQuery("timeout", nil), len(entries))
+	if len(ids) != len(entries) {
+		t.Errorf("Candidates() = %v, want every entry before indexing", ids)
+	}
+
+	ix.Add(0, entries[:3])
+	if ix.Len() != len(entries) {
+		t.Fatalf("Len() = %d, want %d", ix.Len(), len(entries))
+	}
+	ids, _ = ix.Candidates(ParseQuery("login", nil), len(entries))
+	if len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("Candidates() = %v, want [3]", ids)
+	}
+
+	// Entries beyond total are ignored.
+	ids, _ = ix.Candidates(ParseQuery("login", nil), 2)
+	if len(ids) != 0 {
+		t.Errorf("Candidates() = %v, want none", ids)
+	}
+}
+
+func TestRegexLiterals(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+		wantOK  bool
+	}{
+		{pattern: "timeout", want: []string{"timeout"}, wantOK: true},
+		{pattern: "(?i)Conn.*refused", want: []string{"conn", "refused"}, wantOK: true},
+		{pattern: "^err(or)?$", want: []string{"err"}, wantOK: true},
+		{pattern: "a|b", wantOK: false},
+		{pattern: "[0-9]+", wantOK: false},
+		{pattern: "(", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, ok := regexLiterals(tt.pattern)
+			if ok != tt.wantOK {
+				t.Fatalf("regexLiterals() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("regexLiterals() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("regexLiterals() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestJob(t *testing.T) {
+	entries := indexTestEntries()
+	ix := NewIndex()
+	ix.Add(0, entries)
+
+	for _, query := range []string{"ok", "re:serv", "f:cnt", "!re:ok", "service:auth"} {
+		t.Run(query, func(t *testing.T) {
+			q := ParseQuery(query, nil)
+			want, err := q.Run(entries)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			job, err := NewJob(1, q, entries, ix)
+			if err != nil {
+				t.Fatalf("NewJob() error = %v", err)
+			}
+			var got []SearchResult
+			for !job.Done() {
+				got = append(got, job.Next(context.Background(), 2)...)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Next() got %d results, want %d", len(got), len(want))
+			}
+			for i, r := range got {
+				if entries[r.Position].Message != r.Entry.Message {
+					t.Errorf("result %d Position = %d, entry %q", i, r.Position, r.Entry.Message)
+				}
+				if i > 0 && r.Position <= got[i-1].Position {
+					t.Errorf("results not in list order: %d after %d", r.Position, got[i-1].Position)
+				}
+			}
+			if job.Scanned() != job.Total() {
+				t.Errorf("Scanned() = %d, want %d", job.Scanned(), job.Total())
+			}
+		})
+	}
+
+	// An index over another list of entries doesn't narrow the search.
+	stale := NewIndex()
+	stale.Add(0, []logentry.Entry{{Message: "a"}, {Message: "b"}, {Message: "c"}, {Message: "d"}, {Message: "e"}})
+	job, err := NewJob(4, ParseQuery("ok", nil), entries[:4], stale)
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	want, _ := ParseQuery("ok", nil).Run(entries[:4])
+	if got := job.Next(context.Background(), len(entries)); len(got) != len(want) || len(want) == 0 {
+		t.Errorf("Next() with a stale index got %d results, want %d", len(got), len(want))
+	}
+
+	job, err = NewJob(2, ParseQuery("service:.*", nil), entries, nil)
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	job.Keep = func(e logentry.Entry) bool { return e.Fields["service"] == "api" }
+	if got := job.Next(context.Background(), len(entries)); len(got) != 2 {
+		t.Errorf("Next() with Keep got %d results, want 2", len(got))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job, err = NewJob(3, ParseQuery("ok", nil), entries, nil)
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	if got := job.Next(ctx, len(entries)); len(got) != 0 || job.Done() {
+		t.Errorf("Next() after cancel = %d results, Done() = %v, want none and not done", len(got), job.Done())
+	}
+
+	job, err = NewJob(5, ParseQuery("user:ali", nil), []logentry.Entry{entries[3]}, nil)
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	if got := job.Next(context.Background(), 1); len(got) != 1 || got[0].Field != 0 || got[0].Span != [2]int{0, 3} {
+		t.Errorf("Next() = %+v, want span [0 3] in the user field", got)
+	}
+
+	entry := logentry.Entry{Message: "Connection timeout", Source: "app.log", Offset: 42}
+	job, err = NewJob(6, ParseQuery("timeout", nil), []logentry.Entry{entry}, nil)
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	got := job.Next(context.Background(), 1)
+	if len(got) != 1 || got[0].ID != entry.ID() || got[0].Span != [2]int{11, 18} {
+		t.Errorf("Next() = %+v, want ID %v and span [11 18]", got, entry.ID())
+	}
+
+	if _, err := NewJob(4, ParseQuery("re:[", nil), entries, ix); err == nil {
+		t.Error("NewJob() error = nil, want invalid regex error")
+	}
+}
@@ -0,0 +1,403 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultGlob is the file name pattern a directory is watched for when none
+// is given.
+const DefaultGlob = "*.log"
+
+// DirWatcher follows every file under a directory whose name matches a glob,
+// like a Watcher per file. It attaches to matching files as they are created
+// and detaches from files that are deleted.
+type DirWatcher struct {
+	root  string
+	glob  string
+	depth int
+
+	watcher *fsnotify.Watcher
+	files   map[string]*Watcher
+	// merged receives what the file watchers send, in order; it is passed on
+	// to every subscriber.
+	merged chan any
+	events map[context.Context]chan<- any
+	mu     sync.RWMutex
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	isRunning bool
+}
+
+// NewDirWatcher creates a DirWatcher for the files under root whose names
+// match glob, descending at most depth directories below root. Files in
+// offsets are followed from the given offset, as when they were read before;
+// other files are read from the beginning.
+func NewDirWatcher(root, glob string, depth int, offsets map[string]int64) (*DirWatcher, error) {
+	if glob == "" {
+		glob = DefaultGlob
+	}
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &DirWatcher{
+		root:    filepath.Clean(root),
+		glob:    glob,
+		depth:   depth,
+		watcher: watcher,
+		files:   make(map[string]*Watcher),
+		merged:  make(chan any, 100),
+		events:  make(map[context.Context]chan<- any),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	err = walkDir(d.root, depth, func(path string, dir bool) {
+		if dir {
+			_ = d.watcher.Add(path)
+			return
+		}
+		if d.matches(path) {
+			d.attach(path, offsets[path], false)
+		}
+	})
+	if err != nil {
+		_ = d.Stop()
+		return nil, fmt.Errorf("failed to watch directory: %w", err)
+	}
+
+	return d, nil
+}
+
+// MatchFiles returns the files under root whose names match glob, at most
+// depth directories below root, in sorted order.
+func MatchFiles(root, glob string, depth int) ([]string, error) {
+	if glob == "" {
+		glob = DefaultGlob
+	}
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+
+	var files []string
+	err := walkDir(filepath.Clean(root), depth, func(path string, dir bool) {
+		if ok, _ := filepath.Match(glob, filepath.Base(path)); ok && !dir {
+			files = append(files, path)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// walkDir calls fn for root, and for every directory and file below it at
+// most depth directories down. Hidden directories are skipped.
+func walkDir(root string, depth int, fn func(path string, dir bool)) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Skip errors and continue
+		}
+		if !entry.IsDir() {
+			if entry.Type().IsRegular() {
+				fn(path, false)
+			}
+			return nil
+		}
+		if path != root && (strings.HasPrefix(entry.Name(), ".") || dirDepth(root, path) > depth) {
+			return filepath.SkipDir
+		}
+		fn(path, true)
+		return nil
+	})
+}
+
+// dirDepth returns how many directories below root dir is.
+func dirDepth(root, dir string) int {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// matches reports whether path is a file the watcher follows.
+func (d *DirWatcher) matches(path string) bool {
+	if dirDepth(d.root, filepath.Dir(path)) > d.depth {
+		return false
+	}
+	ok, _ := filepath.Match(d.glob, filepath.Base(path))
+	return ok
+}
+
+// attach starts following a file from offset. announce sends a
+// FileCreatedMsg first.
+func (d *DirWatcher) attach(path string, offset int64, announce bool) {
+	reader, err := NewReader(path)
+	if err != nil {
+		return
+	}
+	if err := reader.SetPosition(offset); err != nil {
+		_ = reader.Close()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	w := &Watcher{
+		path:     path,
+		reader:   reader,
+		channels: make(map[context.Context]chan<- []string),
+		events:   map[context.Context]chan<- any{d.ctx: d.merged},
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	d.mu.Lock()
+	d.files[path] = w
+	d.mu.Unlock()
+
+	if announce {
+		d.sendMerged(FileCreatedMsg{Path: path})
+	}
+}
+
+// detach stops following a file.
+func (d *DirWatcher) detach(path string) {
+	d.mu.Lock()
+	w, ok := d.files[path]
+	delete(d.files, path)
+	d.mu.Unlock()
+
+	if ok {
+		_ = w.Stop()
+	}
+}
+
+// sendMerged queues msg for the subscribers, after what the file watchers
+// sent before it.
+func (d *DirWatcher) sendMerged(msg any) {
+	select {
+	case d.merged <- msg:
+	case <-d.ctx.Done():
+	}
+}
+
+// Subscribe starts the watcher and returns a channel that receives what the
+// file watchers send, as Watcher.Subscribe does, and a FileCreatedMsg when a
+// new file is attached. A FileDeletedMsg means the file was detached.
+func (d *DirWatcher) Subscribe(ctx context.Context) <-chan any {
+	eventsCh := make(chan any, 100)
+
+	d.mu.Lock()
+	d.events[ctx] = eventsCh
+	if !d.isRunning {
+		d.isRunning = true
+		d.wg.Add(2)
+		go d.watchLoop()
+		go d.forwardLoop()
+	}
+	d.mu.Unlock()
+
+	return eventsCh
+}
+
+// forwardLoop passes what the file watchers send on to the subscribers.
+func (d *DirWatcher) forwardLoop() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case msg := <-d.merged:
+			// A send blocks while a subscriber doesn't read, e.g. a paused
+			// manager, so it must not hold the lock attaching new files takes.
+			d.mu.RLock()
+			events := maps.Clone(d.events)
+			d.mu.RUnlock()
+			for ctx, ch := range events {
+				select {
+				case ch <- msg:
+				case <-ctx.Done():
+				case <-d.ctx.Done():
+				}
+			}
+		}
+	}
+}
+
+// watchLoop is the main watcher loop.
+func (d *DirWatcher) watchLoop() {
+	defer d.wg.Done()
+
+	pollInterval := 250 * time.Millisecond
+	lastCheck := time.Now()
+
+	// Read what the files got between being attached and the loop starting.
+	d.checkAll()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+
+		case event, ok := <-d.watcher.Events:
+			if !ok {
+				return
+			}
+			d.handle(event)
+
+		case _, ok := <-d.watcher.Errors:
+			if !ok {
+				return
+			}
+
+		case <-time.After(pollInterval):
+			if time.Since(lastCheck) > time.Second {
+				d.rescan()
+				d.checkAll()
+				lastCheck = time.Now()
+			}
+		}
+	}
+}
+
+// handle reacts to a change in a watched directory.
+func (d *DirWatcher) handle(event fsnotify.Event) {
+	path := filepath.Clean(event.Name)
+
+	d.mu.RLock()
+	w, ok := d.files[path]
+	d.mu.RUnlock()
+	if ok {
+		d.checkFile(w)
+		return
+	}
+
+	if !event.Has(fsnotify.Create) {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if info.IsDir() {
+		if dirDepth(d.root, path) <= d.depth {
+			d.rescan()
+		}
+		return
+	}
+	if info.Mode().IsRegular() && d.matches(path) {
+		d.attach(path, 0, true)
+		d.checkFile(d.file(path))
+	}
+}
+
+// rescan watches directories and attaches to files it missed events for.
+func (d *DirWatcher) rescan() {
+	_ = walkDir(d.root, d.depth, func(path string, dir bool) {
+		if dir {
+			_ = d.watcher.Add(path)
+			return
+		}
+		if d.matches(path) && d.file(path) == nil {
+			d.attach(path, 0, true)
+		}
+	})
+}
+
+// checkAll reads what was appended to every followed file.
+func (d *DirWatcher) checkAll() {
+	d.mu.RLock()
+	files := make([]*Watcher, 0, len(d.files))
+	for _, w := range d.files {
+		files = append(files, w)
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	for _, w := range files {
+		d.checkFile(w)
+	}
+}
+
+// checkFile reads what was appended to a file, following it like a Watcher
+// does, and detaches from it once it is deleted.
+func (d *DirWatcher) checkFile(w *Watcher) {
+	if w == nil {
+		return
+	}
+	w.check()
+	if w.missing {
+		d.detach(w.path)
+	}
+}
+
+// file returns the watcher of a followed file, or nil.
+func (d *DirWatcher) file(path string) *Watcher {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.files[path]
+}
+
+// Files returns the followed files in sorted order.
+func (d *DirWatcher) Files() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	files := make([]string, 0, len(d.files))
+	for path := range d.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Root returns the directory being watched.
+func (d *DirWatcher) Root() string {
+	return d.root
+}
+
+// Stop stops the watcher and every file watcher.
+func (d *DirWatcher) Stop() error {
+	d.cancel()
+	d.wg.Wait()
+
+	d.mu.Lock()
+	files := d.files
+	d.files = make(map[string]*Watcher)
+	d.mu.Unlock()
+
+	for _, w := range files {
+		_ = w.Stop()
+	}
+
+	if err := d.watcher.Close(); err != nil {
+		return fmt.Errorf("failed to close fsnotify watcher: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default Manager settings.
+const (
+	DefaultMaxBatchRate = 20
+	DefaultMaxPending   = 100000
+)
+
+// ManagerOptions configures a Manager.
+type ManagerOptions struct {
+	// MaxBatchRate is the most batches sent per second.
+	MaxBatchRate int
+	// MaxPending is the most lines held while the consumer is busy. Beyond
+	// it the oldest lines are dropped.
+	MaxPending int
+}
+
+// Batch is what a Manager sends: what its sources sent since the last batch,
+// in order.
+type Batch struct {
+	// Events holds NewLinesMsg, FileRotatedMsg, FileTruncatedMsg,
+	// FileCreatedMsg and FileDeletedMsg values. Lines a file sent one after
+	// the other are coalesced into one NewLinesMsg.
+	Events []any
+	// Dropped is the number of lines dropped so far because the consumer
+	// fell behind.
+	Dropped int
+}
+
+// SourceStatus is the state of a file a Manager follows.
+type SourceStatus struct {
+	Path    string
+	Status  TailStatus
+	Lines   int
+	Dropped int
+	Error   error
+	// Since is when the status last changed.
+	Since time.Time
+}
+
+// source is a Watcher or a DirWatcher.
+type source interface {
+	Subscribe(ctx context.Context) <-chan any
+	Stop() error
+}
+
+// Manager follows several files and directories and merges what their
+// watchers send into one stream of batches. Batches are sent at most
+// MaxBatchRate times a second; while the consumer is busy, what arrives is
+// held, and past MaxPending lines the oldest are dropped and counted rather
+// than letting memory grow or the watchers block.
+type Manager struct {
+	opts    ManagerOptions
+	sources []source
+	out     chan Batch
+
+	mu       sync.Mutex
+	pending  []any
+	lines    int
+	skipped  map[string]int // dropped lines not yet reported in a NewLinesMsg
+	dropped  int
+	statuses map[string]*SourceStatus
+	resume   chan struct{} // closed on Resume; nil when not paused
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a Manager and starts sending batches. Zero options take
+// their defaults.
+func NewManager(opts ManagerOptions) *Manager {
+	if opts.MaxBatchRate <= 0 {
+		opts.MaxBatchRate = DefaultMaxBatchRate
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = DefaultMaxPending
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		opts:     opts,
+		out:      make(chan Batch),
+		skipped:  make(map[string]int),
+		statuses: make(map[string]*SourceStatus),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	m.wg.Add(1)
+	go m.sendLoop()
+
+	return m
+}
+
+// AddFile follows a file from offset.
+func (m *Manager) AddFile(path string, offset int64) error {
+	m.setStatus(path, TailStatusStarting, nil)
+
+	w, err := NewWatcher(path)
+	if err == nil {
+		if err = w.GetReader().SetPosition(offset); err != nil {
+			_ = w.Stop()
+		}
+	}
+	if err != nil {
+		m.setStatus(path, TailStatusError, err)
+		return fmt.Errorf("failed to follow %s: %w", path, err)
+	}
+
+	m.add(w)
+	m.setStatus(path, TailStatusRunning, nil)
+	return nil
+}
+
+// AddDir follows the files under root matching glob, as NewDirWatcher does.
+func (m *Manager) AddDir(root, glob string, depth int, offsets map[string]int64) error {
+	d, err := NewDirWatcher(root, glob, depth, offsets)
+	if err != nil {
+		return fmt.Errorf("failed to follow %s: %w", root, err)
+	}
+
+	m.add(d)
+	for _, path := range d.Files() {
+		m.setStatus(path, TailStatusRunning, nil)
+	}
+	return nil
+}
+
+// add starts collecting what a source sends.
+func (m *Manager) add(s source) {
+	m.mu.Lock()
+	m.sources = append(m.sources, s)
+	m.mu.Unlock()
+
+	events := s.Subscribe(m.ctx)
+	m.wg.Add(1)
+	go m.collect(events)
+}
+
+// Batches returns the channel batches are sent on.
+func (m *Manager) Batches() <-chan Batch {
+	return m.out
+}
+
+// Pause stops reading the sources until Resume. Nothing is dropped while
+// paused; the watchers hold back what they read.
+func (m *Manager) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.resume == nil {
+		m.resume = make(chan struct{})
+	}
+}
+
+// Resume continues reading the sources after Pause.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.resume != nil {
+		close(m.resume)
+		m.resume = nil
+	}
+}
+
+// Dropped returns the number of lines dropped so far.
+func (m *Manager) Dropped() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dropped
+}
+
+// Statuses returns the state of every followed file, sorted by path.
+func (m *Manager) Statuses() []SourceStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]SourceStatus, 0, len(m.statuses))
+	for _, s := range m.statuses {
+		status := *s
+		if m.resume != nil && status.Status == TailStatusRunning {
+			status.Status = TailStatusPaused
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Path < statuses[j].Path })
+	return statuses
+}
+
+// Stop stops every source.
+func (m *Manager) Stop() error {
+	m.cancel()
+
+	m.mu.Lock()
+	sources := m.sources
+	m.sources = nil
+	m.mu.Unlock()
+
+	var firstErr error
+	for _, s := range sources {
+		if err := s.Stop(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	m.wg.Wait()
+	return firstErr
+}
+
+// collect queues what a source sends.
+func (m *Manager) collect(events <-chan any) {
+	defer m.wg.Done()
+
+	for {
+		m.mu.Lock()
+		resume := m.resume
+		m.mu.Unlock()
+		if resume != nil {
+			select {
+			case <-resume:
+			case <-m.ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case event := <-events:
+			m.queue(event)
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// queue adds an event to the next batch and updates the state of its file.
+func (m *Manager) queue(event any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch event := event.(type) {
+	case NewLinesMsg:
+		m.status(event.Path, TailStatusRunning, nil).Lines += len(event.Lines)
+		event.Skipped += m.skipped[event.Path]
+		delete(m.skipped, event.Path)
+
+		// Coalesce with the lines the file sent just before.
+		if n := len(m.pending); n > 0 && event.Skipped == 0 {
+			if last, ok := m.pending[n-1].(NewLinesMsg); ok && last.Path == event.Path && (last.Sizes == nil) == (event.Sizes == nil) {
+				last.Lines = append(last.Lines, event.Lines...)
+				last.Sizes = append(last.Sizes, event.Sizes...)
+				m.pending[n-1] = last
+				m.lines += len(event.Lines)
+				m.drop()
+				return
+			}
+		}
+		m.lines += len(event.Lines)
+	case FileDeletedMsg:
+		m.status(event.Path, TailStatusStopped, nil)
+	case FileCreatedMsg:
+		m.status(event.Path, TailStatusRunning, nil)
+	}
+
+	m.pending = append(m.pending, event)
+	m.drop()
+}
+
+// drop drops the oldest queued lines beyond MaxPending. The next lines of
+// their file report how many were skipped. The caller holds the lock.
+func (m *Manager) drop() {
+	for i := 0; m.lines > m.opts.MaxPending && i < len(m.pending); i++ {
+		msg, ok := m.pending[i].(NewLinesMsg)
+		if !ok {
+			continue
+		}
+		n := min(m.lines-m.opts.MaxPending, len(msg.Lines))
+		for j := range n {
+			msg.Offset += msg.LineSize(j)
+		}
+		msg.Lines = msg.Lines[n:]
+		if msg.Sizes != nil {
+			msg.Sizes = msg.Sizes[n:]
+		}
+		msg.Skipped += n
+		m.pending[i] = msg
+		m.lines -= n
+		m.dropped += n
+		m.status(msg.Path, TailStatusRunning, nil).Dropped += n
+	}
+
+	// Lines events left empty pass their count on to the next lines of the
+	// same file.
+	kept := m.pending[:0]
+	for _, event := range m.pending {
+		if msg, ok := event.(NewLinesMsg); ok && len(msg.Lines) == 0 {
+			m.skipped[msg.Path] += msg.Skipped
+			continue
+		}
+		if msg, ok := event.(NewLinesMsg); ok && m.skipped[msg.Path] > 0 {
+			msg.Skipped += m.skipped[msg.Path]
+			delete(m.skipped, msg.Path)
+			event = msg
+		}
+		kept = append(kept, event)
+	}
+	m.pending = kept
+}
+
+// status returns the state of a file, setting it to status unless it is
+// already. The caller holds the lock.
+func (m *Manager) status(path string, status TailStatus, err error) *SourceStatus {
+	s, ok := m.statuses[path]
+	if !ok {
+		s = &SourceStatus{Path: path}
+		m.statuses[path] = s
+	}
+	if s.Status != status || !ok || err != nil {
+		s.Status = status
+		s.Error = err
+		s.Since = time.Now()
+	}
+	return s
+}
+
+// setStatus sets the state of a file.
+func (m *Manager) setStatus(path string, status TailStatus, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status(path, status, err)
+}
+
+// sendLoop sends what was queued, at most MaxBatchRate times a second.
+func (m *Manager) sendLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(time.Second / time.Duration(m.opts.MaxBatchRate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		batch := Batch{Events: m.pending, Dropped: m.dropped}
+		m.pending = nil
+		m.lines = 0
+		m.mu.Unlock()
+
+		if len(batch.Events) == 0 {
+			continue
+		}
+		select {
+		case m.out <- batch:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
@@ -4,10 +4,31 @@ import "time"
 
 // NewLinesMsg is sent when new lines are read from the file.
 type NewLinesMsg struct {
-	Lines     []string
+	Path  string
+	Lines []string
+	// Offset is where the first line starts in the file. The lines follow
+	// each other.
+	Offset int64
+	// Sizes holds the bytes each line takes in the file with its line
+	// ending, which may be "\r\n" or, on the last line of a file read before
+	// it is rotated or deleted, missing. Without Sizes, each line is
+	// followed by "\n".
+	Sizes []int
+	// Skipped is the number of lines of the file before these that were
+	// dropped because the consumer fell behind.
+	Skipped   int
 	Timestamp time.Time
 }
 
+// LineSize returns how many bytes line i takes in the file, including its
+// line ending.
+func (m NewLinesMsg) LineSize(i int) int64 {
+	if m.Sizes != nil {
+		return int64(m.Sizes[i])
+	}
+	return int64(len(m.Lines[i])) + 1
+}
+
 // TailErrorMsg is sent when an error occurs during tailing.
 type TailErrorMsg struct {
 	Error error
@@ -2,8 +2,11 @@ package tail
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
 )
 
@@ -12,6 +15,7 @@ type Reader struct {
 	path   string
 	file   *os.File
 	pos    int64
+	info   os.FileInfo
 	mu     sync.Mutex
 	isOpen bool
 }
@@ -23,43 +27,73 @@ func NewReader(path string) (*Reader, error) {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
 	return &Reader{
 		path:   path,
 		file:   file,
 		pos:    0,
+		info:   info,
 		isOpen: true,
 	}, nil
 }
 
-// ReadNew reads all new lines since the last read.
-// Updates the internal position to the end of the file.
+// ReadNew reads the lines written since the last read and advances the
+// position past them. A final line without a newline is left for a later
+// read, since it may still be being written.
 func (r *Reader) ReadNew() ([]string, error) {
+	lines, _, err := r.ReadLines()
+	return lines, err
+}
+
+// ReadLines is like ReadNew, but also returns the offset of the first line.
+// Each line takes its length plus the newline after it.
+func (r *Reader) ReadLines() ([]string, int64, error) {
+	lines, offset, _, err := r.readLines(false)
+	return lines, offset, err
+}
+
+// readLines reads the lines written since the last read, without their line
+// endings, and the bytes each took in the file. With flush set, a final line
+// without a newline is read too.
+func (r *Reader) readLines(flush bool) (lines []string, offset int64, sizes []int, err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if !r.isOpen {
-		return nil, fmt.Errorf("reader is closed")
+		return nil, 0, nil, fmt.Errorf("reader is closed")
 	}
 
-	if _, err := r.file.Seek(r.pos, 0); err != nil {
-		return nil, fmt.Errorf("failed to seek to position %d: %w", r.pos, err)
-	}
-
-	var lines []string
-	scanner := bufio.NewScanner(r.file)
-
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	if _, err := r.file.Seek(r.pos, io.SeekStart); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to seek to position %d: %w", r.pos, err)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error scanning file: %w", err)
+	start := r.pos
+	br := bufio.NewReader(r.file)
+
+	for {
+		line, err := br.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			if flush && line != "" {
+				lines = append(lines, line)
+				sizes = append(sizes, len(line))
+				r.pos += int64(len(line))
+			}
+			break
+		}
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("error reading file: %w", err)
+		}
+		lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		sizes = append(sizes, len(line))
+		r.pos += int64(len(line))
 	}
 
-	newPos, _ := r.file.Seek(0, 2)
-	r.pos = newPos
-
-	return lines, nil
+	return lines, start, sizes, nil
 }
 
 // ReadAll reads the entire file from the beginning.
@@ -161,6 +195,45 @@ func (r *Reader) Reopen() error {
 	r.file = file
 	r.isOpen = true
 	r.pos = 0
+	if info, err := file.Stat(); err == nil {
+		r.info = info
+	}
+
+	return nil
+}
+
+// SameFile reports whether info describes the file the reader has open. It
+// doesn't when the path was renamed away and a new file created in its place,
+// as log rotation does.
+func (r *Reader) SameFile(info os.FileInfo) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.info == nil || os.SameFile(r.info, info)
+}
+
+// Reset closes the open file and opens the path again, reading it from the
+// beginning. It is used to follow a path that now names a new file.
+func (r *Reader) Reset() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to reopen file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if r.isOpen {
+		_ = r.file.Close()
+	}
+	r.file = file
+	r.info = info
+	r.isOpen = true
+	r.pos = 0
 
 	return nil
 }
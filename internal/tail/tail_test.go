@@ -708,3 +708,544 @@ func TestWatcher_CheckFileExists(t *testing.T) {
 		t.Error("CheckFileExists() returned true for deleted file")
 	}
 }
+
+// waitFor reads events until one of type T arrives, collecting the lines of
+// the NewLinesMsg events before it.
+func waitFor[T any](t *testing.T, eventsCh <-chan any) (T, []string) {
+	t.Helper()
+
+	var lines []string
+	timeout := time.After(4 * time.Second)
+	for {
+		select {
+		case event := <-eventsCh:
+			if msg, ok := event.(T); ok {
+				return msg, lines
+			}
+			if msg, ok := event.(NewLinesMsg); ok {
+				lines = append(lines, msg.Lines...)
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("Timeout waiting for %T (got lines %v)", zero, lines)
+			return zero, nil
+		}
+	}
+}
+
+func TestWatcher_FollowsRotation(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "test.log")
+
+	if err := os.WriteFile(tmpFile, []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher, err := NewWatcher(tmpFile)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %v", err)
+	}
+	defer func() {
+		_ = watcher.Stop()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	eventsCh := watcher.Subscribe(ctx)
+
+	f, err := os.OpenFile(tmpFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	_, _ = f.WriteString("old 3\n")
+	_ = f.Close()
+
+	if err := os.Rename(tmpFile, tmpFile+".1"); err != nil {
+		t.Fatalf("Failed to rotate file: %v", err)
+	}
+	if err := os.WriteFile(tmpFile, []byte("new 1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	rotated, lines := waitFor[FileRotatedMsg](t, eventsCh)
+	if rotated.NewPath != tmpFile {
+		t.Errorf("FileRotatedMsg.NewPath = %q, want %q", rotated.NewPath, tmpFile)
+	}
+	if len(lines) != 3 || lines[2] != "old 3" {
+		t.Errorf("lines before rotation = %v, want the 3 old lines", lines)
+	}
+
+	lines = nil
+	timeout := time.After(4 * time.Second)
+	for len(lines) == 0 {
+		select {
+		case event := <-eventsCh:
+			if msg, ok := event.(NewLinesMsg); ok {
+				lines = msg.Lines
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for lines of the new file")
+		}
+	}
+	if len(lines) != 1 || lines[0] != "new 1" {
+		t.Errorf("lines after rotation = %v, want [new 1]", lines)
+	}
+}
+
+func TestWatcher_FollowsTruncation(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "test.log")
+
+	if err := os.WriteFile(tmpFile, []byte("line 1\nline 2\nline 3\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher, err := NewWatcher(tmpFile)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %v", err)
+	}
+	defer func() {
+		_ = watcher.Stop()
+	}()
+	if _, err := watcher.PollImmediately(); err != nil {
+		t.Fatalf("PollImmediately() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	eventsCh := watcher.Subscribe(ctx)
+
+	if err := os.WriteFile(tmpFile, []byte("again\n"), 0644); err != nil {
+		t.Fatalf("Failed to truncate file: %v", err)
+	}
+
+	truncated, _ := waitFor[FileTruncatedMsg](t, eventsCh)
+	if truncated.OldSize != 21 {
+		t.Errorf("FileTruncatedMsg.OldSize = %d, want 21", truncated.OldSize)
+	}
+
+	lines := []string{}
+	timeout := time.After(4 * time.Second)
+	for len(lines) == 0 {
+		select {
+		case event := <-eventsCh:
+			if msg, ok := event.(NewLinesMsg); ok {
+				lines = msg.Lines
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for lines after truncation")
+		}
+	}
+	if lines[0] != "again" {
+		t.Errorf("lines after truncation = %v, want [again]", lines)
+	}
+}
+
+func TestWatcher_FollowsDeleteAndCreate(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "test.log")
+
+	if err := os.WriteFile(tmpFile, []byte("first\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher, err := NewWatcher(tmpFile)
+	if err != nil {
+		t.Fatalf("NewWatcher() failed: %v", err)
+	}
+	defer func() {
+		_ = watcher.Stop()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	eventsCh := watcher.Subscribe(ctx)
+
+	if err := os.Remove(tmpFile); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	waitFor[FileDeletedMsg](t, eventsCh)
+
+	if err := os.WriteFile(tmpFile, []byte("second\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	created, _ := waitFor[FileCreatedMsg](t, eventsCh)
+	if created.Path != tmpFile {
+		t.Errorf("FileCreatedMsg.Path = %q, want %q", created.Path, tmpFile)
+	}
+}
+
+func TestReader_ReadLinesHoldsBackPartialLine(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(tmpFile, []byte("line 1\nline 2\npart"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reader, err := NewReader(tmpFile)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	lines, offset, err := reader.ReadLines()
+	if err != nil {
+		t.Fatalf("ReadLines() failed: %v", err)
+	}
+	if len(lines) != 2 || offset != 0 || reader.Position() != 14 {
+		t.Errorf("ReadLines() = %v at %d, position %d; want 2 lines at 0, position 14", lines, offset, reader.Position())
+	}
+
+	f, err := os.OpenFile(tmpFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	_, _ = f.WriteString("ial\n")
+	_ = f.Close()
+
+	lines, offset, err = reader.ReadLines()
+	if err != nil {
+		t.Fatalf("ReadLines() failed: %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "partial" || offset != 14 {
+		t.Errorf("ReadLines() = %v at %d, want [partial] at 14", lines, offset)
+	}
+}
+
+func TestReader_ReadNewCRLF(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(tmpFile, []byte("{\"msg\":\"a\"}\r\nline 2\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reader, err := NewReader(tmpFile)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	lines, err := reader.ReadNew()
+	if err != nil {
+		t.Fatalf("ReadNew() failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != `{"msg":"a"}` || lines[1] != "line 2" || reader.Position() != 21 {
+		t.Errorf("ReadNew() = %q, position %d; want the lines without \\r\\n, position 21", lines, reader.Position())
+	}
+}
+
+func TestMatchFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.log", "b.txt", filepath.Join("sub", "c.log"), filepath.Join("sub", "deep", "d.log"), filepath.Join(".hidden", "e.log")} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		glob  string
+		depth int
+		want  []string
+	}{
+		{"", 0, []string{"a.log"}},
+		{"*.log", 1, []string{"a.log", filepath.Join("sub", "c.log")}},
+		{"*.log", 2, []string{"a.log", filepath.Join("sub", "c.log"), filepath.Join("sub", "deep", "d.log")}},
+		{"*.txt", 5, []string{"b.txt"}},
+	}
+
+	for _, tt := range tests {
+		files, err := MatchFiles(root, tt.glob, tt.depth)
+		if err != nil {
+			t.Fatalf("MatchFiles(%q, %d) failed: %v", tt.glob, tt.depth, err)
+		}
+		var got []string
+		for _, f := range files {
+			rel, _ := filepath.Rel(root, f)
+			got = append(got, rel)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("MatchFiles(%q, %d) = %v, want %v", tt.glob, tt.depth, got, tt.want)
+		}
+	}
+
+	if _, err := MatchFiles(root, "[", 0); err == nil {
+		t.Error("MatchFiles() with a bad glob returned no error")
+	}
+}
+
+func TestDirWatcher(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "old.log")
+	if err := os.WriteFile(old, []byte("read before\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher, err := NewDirWatcher(root, "*.log", 0, map[string]int64{old: 12})
+	if err != nil {
+		t.Fatalf("NewDirWatcher() failed: %v", err)
+	}
+	defer func() {
+		_ = watcher.Stop()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	eventsCh := watcher.Subscribe(ctx)
+
+	// A new matching file is attached and read from the beginning; others
+	// are ignored.
+	if err := os.WriteFile(filepath.Join(root, "skip.txt"), []byte("no\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	created := filepath.Join(root, "new.log")
+	if err := os.WriteFile(created, []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	msg, _ := waitFor[FileCreatedMsg](t, eventsCh)
+	if msg.Path != created {
+		t.Errorf("FileCreatedMsg.Path = %q, want %q", msg.Path, created)
+	}
+	lines, _ := waitFor[NewLinesMsg](t, eventsCh)
+	if lines.Path != created || len(lines.Lines) != 1 || lines.Lines[0] != "hello" {
+		t.Errorf("NewLinesMsg = %+v, want hello from %s", lines, created)
+	}
+
+	// Files read before continue from their offset.
+	f, err := os.OpenFile(old, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	_, _ = f.WriteString("appended\n")
+	_ = f.Close()
+	lines, _ = waitFor[NewLinesMsg](t, eventsCh)
+	if lines.Path != old || len(lines.Lines) != 1 || lines.Lines[0] != "appended" || lines.Offset != 12 {
+		t.Errorf("NewLinesMsg = %+v, want appended at offset 12 of %s", lines, old)
+	}
+
+	// Deleted files are detached.
+	if err := os.Remove(old); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	deleted, _ := waitFor[FileDeletedMsg](t, eventsCh)
+	if deleted.Path != old {
+		t.Errorf("FileDeletedMsg.Path = %q, want %q", deleted.Path, old)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(watcher.Files()) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if files := watcher.Files(); len(files) != 1 || files[0] != created {
+		t.Errorf("Files() = %v, want only %s", files, created)
+	}
+}
+
+func TestDirWatcher_AttachWhileSubscriberBlocked(t *testing.T) {
+	root := t.TempDir()
+	watcher, err := NewDirWatcher(root, "*.log", 0, nil)
+	if err != nil {
+		t.Fatalf("NewDirWatcher() failed: %v", err)
+	}
+	defer func() {
+		_ = watcher.Stop()
+	}()
+
+	// A subscriber that doesn't read, like a paused manager, fills up and
+	// blocks the forwarding.
+	eventsCh := watcher.Subscribe(context.Background())
+	for i := 0; i < 150; i++ {
+		watcher.sendMerged(FileCreatedMsg{Path: fmt.Sprint(i)})
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(eventsCh) < cap(eventsCh) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	path := filepath.Join(root, "new.log")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		watcher.attach(path, 0, false)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("attach() blocked while a subscriber wasn't reading")
+	}
+}
+
+func TestManager(t *testing.T) {
+	tmpDir := t.TempDir()
+	a, b := filepath.Join(tmpDir, "a.log"), filepath.Join(tmpDir, "b.log")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	m := NewManager(ManagerOptions{MaxBatchRate: 50})
+	defer func() {
+		_ = m.Stop()
+	}()
+	for _, path := range []string{a, b} {
+		if err := m.AddFile(path, 4); err != nil {
+			t.Fatalf("AddFile() failed: %v", err)
+		}
+	}
+	if err := m.AddFile(filepath.Join(tmpDir, "missing.log"), 0); err == nil {
+		t.Error("AddFile() of a missing file returned no error")
+	}
+
+	for _, path := range []string{a, b} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open file: %v", err)
+		}
+		_, _ = f.WriteString(filepath.Base(path) + " 1\n")
+		_ = f.Close()
+	}
+
+	got := map[string][]string{}
+	timeout := time.After(4 * time.Second)
+	for len(got[a]) == 0 || len(got[b]) == 0 {
+		select {
+		case batch := <-m.Batches():
+			for _, event := range batch.Events {
+				if msg, ok := event.(NewLinesMsg); ok {
+					got[msg.Path] = append(got[msg.Path], msg.Lines...)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("Timeout waiting for batches (got %v)", got)
+		}
+	}
+	if got[a][0] != "a.log 1" || got[b][0] != "b.log 1" {
+		t.Errorf("lines = %v, want the new line of each file", got)
+	}
+
+	statuses := m.Statuses()
+	if len(statuses) != 3 {
+		t.Fatalf("Statuses() = %+v, want 3 files", statuses)
+	}
+	if statuses[0].Path != a || statuses[0].Status != TailStatusRunning || statuses[0].Lines != 1 {
+		t.Errorf("Statuses()[0] = %+v, want a.log running with 1 line", statuses[0])
+	}
+	if statuses[2].Status != TailStatusError || statuses[2].Error == nil {
+		t.Errorf("Statuses()[2] = %+v, want missing.log with an error", statuses[2])
+	}
+
+	m.Pause()
+	if status := m.Statuses()[0].Status; status != TailStatusPaused {
+		t.Errorf("status after Pause() = %v, want paused", status)
+	}
+	m.Resume()
+	if status := m.Statuses()[0].Status; status != TailStatusRunning {
+		t.Errorf("status after Resume() = %v, want running", status)
+	}
+}
+
+func TestWatcher_FlushedLineOffsets(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(tmpFile, []byte("line 1\r\nline 2\npart"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reader, err := NewReader(tmpFile)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	lines, offset, sizes, err := reader.readLines(true)
+	if err != nil {
+		t.Fatalf("readLines() failed: %v", err)
+	}
+	if fmt.Sprint(lines) != "[line 1 line 2 part]" || offset != 0 || reader.Position() != 19 {
+		t.Fatalf("readLines() = %q at %d, position %d; want 3 lines at 0, position 19", lines, offset, reader.Position())
+	}
+
+	// A CRLF line takes 2 bytes more than its text, a final line without a
+	// newline none.
+	msg := NewLinesMsg{Lines: lines, Offset: offset, Sizes: sizes}
+	end := msg.Offset
+	for i, want := range []int64{8, 7, 4} {
+		if got := msg.LineSize(i); got != want {
+			t.Errorf("LineSize(%d) = %d, want %d", i, got, want)
+		}
+		end += msg.LineSize(i)
+	}
+	if end != reader.Position() {
+		t.Errorf("lines end at %d, want %d", end, reader.Position())
+	}
+}
+
+func TestManager_CoalesceAndDrop(t *testing.T) {
+	// Built by hand so no batch is sent while queueing.
+	m := &Manager{
+		opts:     ManagerOptions{MaxBatchRate: 1, MaxPending: 3},
+		skipped:  make(map[string]int),
+		statuses: make(map[string]*SourceStatus),
+	}
+
+	m.queue(NewLinesMsg{Path: "a", Lines: []string{"a1"}})
+	m.queue(NewLinesMsg{Path: "a", Lines: []string{"a2"}, Offset: 3})
+	if len(m.pending) != 1 {
+		t.Fatalf("pending = %+v, want consecutive lines coalesced", m.pending)
+	}
+
+	m.queue(NewLinesMsg{Path: "b", Lines: []string{"b1"}})
+	m.queue(FileRotatedMsg{OldPath: "b", NewPath: "b"})
+	m.queue(NewLinesMsg{Path: "a", Lines: []string{"a3", "a4"}, Offset: 6})
+
+	// 5 lines queued, 3 allowed: a1 and a2 are dropped.
+	if m.dropped != 2 || m.lines != 3 {
+		t.Errorf("dropped = %d, lines = %d; want 2 and 3", m.dropped, m.lines)
+	}
+	if len(m.pending) != 3 {
+		t.Fatalf("pending = %+v, want b1, the rotation and a3 a4", m.pending)
+	}
+	last, _ := m.pending[2].(NewLinesMsg)
+	if last.Path != "a" || last.Skipped != 2 || last.Offset != 6 {
+		t.Errorf("last = %+v, want a3 a4 reporting 2 skipped lines", last)
+	}
+
+	// Dropping part of a message moves its offset past the dropped lines.
+	m.queue(NewLinesMsg{Path: "b", Lines: []string{"b2", "b3"}, Offset: 3})
+	if _, ok := m.pending[0].(FileRotatedMsg); !ok {
+		t.Errorf("pending[0] = %+v, want b1 dropped", m.pending[0])
+	}
+	b, _ := m.pending[2].(NewLinesMsg)
+	if b.Skipped != 1 {
+		t.Errorf("b = %+v, want 1 skipped line", b)
+	}
+	a, _ := m.pending[1].(NewLinesMsg)
+	if fmt.Sprint(a.Lines) != "[a4]" || a.Offset != 9 || a.Skipped != 3 {
+		t.Errorf("a = %+v, want a4 at offset 9 after 3 skipped lines", a)
+	}
+	if m.statuses["a"].Dropped != 3 || m.statuses["b"].Dropped != 1 {
+		t.Errorf("dropped per file = %d, %d; want 3 and 1", m.statuses["a"].Dropped, m.statuses["b"].Dropped)
+	}
+
+	// Line sizes stay with their lines when lines are dropped.
+	m = &Manager{
+		opts:     ManagerOptions{MaxBatchRate: 1, MaxPending: 2},
+		skipped:  make(map[string]int),
+		statuses: make(map[string]*SourceStatus),
+	}
+	m.queue(NewLinesMsg{Path: "c", Lines: []string{"c1", "c2"}, Offset: 0, Sizes: []int{4, 2}})
+	m.queue(NewLinesMsg{Path: "c", Lines: []string{"c3"}, Offset: 6, Sizes: []int{4}})
+	c, _ := m.pending[0].(NewLinesMsg)
+	if fmt.Sprint(c.Lines) != "[c2 c3]" || fmt.Sprint(c.Sizes) != "[2 4]" || c.Offset != 4 {
+		t.Errorf("c = %+v, want c2 c3 with their sizes at offset 4", c)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -11,11 +12,17 @@ import (
 )
 
 // Watcher watches a file for changes and sends new lines through a channel.
+//
+// It follows the file by name, like tail -F: when the file is truncated, or
+// renamed away and replaced by a new one as log rotation does, the watcher
+// reopens the path and continues from the beginning of the new file.
 type Watcher struct {
 	path      string
 	reader    *Reader
 	watcher   *fsnotify.Watcher
 	channels  map[context.Context]chan<- []string
+	events    map[context.Context]chan<- any
+	missing   bool
 	mu        sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -43,11 +50,14 @@ func NewWatcher(path string) (*Watcher, error) {
 		reader:   reader,
 		watcher:  watcher,
 		channels: make(map[context.Context]chan<- []string),
+		events:   make(map[context.Context]chan<- any),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
 
-	if err := w.watcher.Add(path); err != nil {
+	// The directory is watched rather than the file, so the watch survives
+	// the file being renamed, removed or created again.
+	if err := w.watcher.Add(filepath.Dir(path)); err != nil {
 		_ = reader.Close()
 		_ = watcher.Close()
 		return nil, fmt.Errorf("failed to add file to watcher: %w", err)
@@ -65,13 +75,35 @@ func (w *Watcher) Start(ctx context.Context) <-chan []string {
 	w.channels[ctx] = linesCh
 	w.mu.Unlock()
 
+	w.run()
+	return linesCh
+}
+
+// Subscribe starts the watcher and returns a channel that receives, in the
+// order they happen, a NewLinesMsg for new lines and a FileRotatedMsg,
+// FileTruncatedMsg, FileCreatedMsg or FileDeletedMsg when the file changes
+// under its name.
+func (w *Watcher) Subscribe(ctx context.Context) <-chan any {
+	eventsCh := make(chan any, 100)
+
+	w.mu.Lock()
+	w.events[ctx] = eventsCh
+	w.mu.Unlock()
+
+	w.run()
+	return eventsCh
+}
+
+// run starts the watch loop if it isn't running yet.
+func (w *Watcher) run() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	if !w.isRunning {
 		w.isRunning = true
 		w.wg.Add(1)
 		go w.watchLoop()
 	}
-
-	return linesCh
 }
 
 // watchLoop is the main watcher loop.
@@ -91,8 +123,8 @@ func (w *Watcher) watchLoop() {
 				return
 			}
 
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				w.sendNewLines()
+			if filepath.Clean(event.Name) == filepath.Clean(w.path) {
+				w.check()
 				lastCheck = time.Now()
 			}
 
@@ -103,16 +135,57 @@ func (w *Watcher) watchLoop() {
 
 		case <-time.After(pollInterval):
 			if time.Since(lastCheck) > time.Second {
-				w.sendNewLines()
+				w.check()
 				lastCheck = time.Now()
 			}
 		}
 	}
 }
 
+// check reads what was appended to the file. When the path is gone or names
+// a new file, the rest of the old file, including a final line without a
+// newline, is read first. The change is then reported, and when the path
+// names a new file or the file got shorter than what was read, the file at
+// the path is read from the beginning.
+func (w *Watcher) check() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		if !w.missing {
+			w.missing = true
+			w.sendNewLines(true)
+			w.sendEvent(FileDeletedMsg{Path: w.path})
+		}
+		return
+	}
+
+	switch {
+	case w.missing:
+		if err := w.reader.Reset(); err != nil {
+			return
+		}
+		w.missing = false
+		w.sendEvent(FileCreatedMsg{Path: w.path})
+	case !w.reader.SameFile(info):
+		w.sendNewLines(true)
+		if err := w.reader.Reset(); err != nil {
+			return
+		}
+		w.sendEvent(FileRotatedMsg{OldPath: w.path, NewPath: w.path})
+	case info.Size() < w.reader.Position():
+		oldSize := w.reader.Position()
+		if err := w.reader.ResetPosition(); err != nil {
+			return
+		}
+		w.sendEvent(FileTruncatedMsg{Path: w.path, OldSize: oldSize, NewSize: info.Size()})
+	}
+
+	w.sendNewLines(false)
+}
+
 // sendNewLines reads new lines from the file and sends them to all channels.
-func (w *Watcher) sendNewLines() {
-	lines, err := w.reader.ReadNew()
+// A final line without a newline is only read with flush set.
+func (w *Watcher) sendNewLines(flush bool) {
+	lines, offset, sizes, err := w.reader.readLines(flush)
 	if err != nil {
 		return
 	}
@@ -132,6 +205,28 @@ func (w *Watcher) sendNewLines() {
 			return
 		}
 	}
+
+	w.broadcast(NewLinesMsg{Path: w.path, Lines: lines, Offset: offset, Sizes: sizes, Timestamp: time.Now()})
+}
+
+// sendEvent sends a file change message to all subscribers.
+func (w *Watcher) sendEvent(msg any) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	w.broadcast(msg)
+}
+
+// broadcast sends msg to all subscribers. The caller holds the read lock.
+func (w *Watcher) broadcast(msg any) {
+	for ctx, ch := range w.events {
+		select {
+		case ch <- msg:
+		case <-ctx.Done():
+		case <-w.ctx.Done():
+			return
+		}
+	}
 }
 
 // Stop stops the watcher.
@@ -139,8 +234,10 @@ func (w *Watcher) Stop() error {
 	w.cancel()
 	w.wg.Wait()
 
-	if err := w.watcher.Close(); err != nil {
-		return fmt.Errorf("failed to close fsnotify watcher: %w", err)
+	if w.watcher != nil {
+		if err := w.watcher.Close(); err != nil {
+			return fmt.Errorf("failed to close fsnotify watcher: %w", err)
+		}
 	}
 
 	if err := w.reader.Close(); err != nil {
@@ -177,6 +274,7 @@ func (w *Watcher) RemoveChannel(ctx context.Context) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	delete(w.channels, ctx)
+	delete(w.events, ctx)
 }
 
 // Watch reads a file and continuously sends new lines through the channel.
@@ -236,17 +334,13 @@ func WatchFromBeginning(ctx context.Context, path string) (<-chan []string, erro
 	return linesCh, nil
 }
 
-// Reopen attempts to reopen the file if it was closed or moved.
+// Reopen attempts to reopen the file if it was closed. Rotation and
+// truncation are followed without it.
 func (w *Watcher) Reopen() error {
 	if err := w.reader.Reopen(); err != nil {
 		return fmt.Errorf("failed to reopen reader: %w", err)
 	}
 
-	_ = w.watcher.Remove(w.path)
-	if err := w.watcher.Add(w.path); err != nil {
-		return fmt.Errorf("failed to re-add file to watcher: %w", err)
-	}
-
 	return nil
 }
 
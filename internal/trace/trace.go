@@ -0,0 +1,145 @@
+// Package trace correlates log entries that share a request or trace ID and
+// arranges OpenTelemetry-style spans into a call tree.
+package trace
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// DefaultKeys are the fields entries are correlated by, in order of
+// preference.
+var DefaultKeys = []string{"trace_id", "request_id", "span_id"}
+
+// Fields of OpenTelemetry-style entries that make up the call tree.
+const (
+	SpanKey   = "span_id"
+	ParentKey = "parent_span_id"
+	NameKey   = "name"
+)
+
+// KeyOf returns the first of keys that entry has a value for, and the value.
+func KeyOf(entry logentry.Entry, keys []string) (string, any, bool) {
+	for _, key := range keys {
+		value, ok := entry.GetField(key)
+		if ok && value != nil && value != "" {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// Span is the entries of one span, as a node of a call tree.
+type Span struct {
+	ID       string
+	ParentID string
+	// Name is the span's name field, or the message of its first entry.
+	Name string
+	// Start and End are the times of its first and last entries.
+	Start time.Time
+	End   time.Time
+	// Depth is how many ancestors of the span are in the tree.
+	Depth int
+	// Entries is how many entries belong to the span and First is the
+	// earliest of them.
+	Entries int
+	First   logentry.ID
+}
+
+// Duration returns how long the span took, from its first entry to its
+// last.
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Tree groups entries by span ID and returns the spans in call-tree order:
+// each span is followed by its children, siblings ordered by start time.
+// Spans whose parent has no entries are roots. Entries without a span ID
+// are left out.
+func Tree(entries []logentry.Entry) []Span {
+	spans := make(map[string]*Span)
+	var order []string
+	for _, entry := range entries {
+		id := fieldString(entry, SpanKey)
+		if id == "" {
+			continue
+		}
+		span, ok := spans[id]
+		if !ok {
+			span = &Span{ID: id, Name: entry.Message, First: entry.ID(), Start: entry.Timestamp, End: entry.Timestamp}
+			spans[id] = span
+			order = append(order, id)
+		}
+		span.Entries++
+		if parent := fieldString(entry, ParentKey); parent != "" && parent != id {
+			span.ParentID = parent
+		}
+		if name := fieldString(entry, NameKey); name != "" {
+			span.Name = name
+		}
+		if entry.Timestamp.IsZero() {
+			continue
+		}
+		if span.Start.IsZero() || entry.Timestamp.Before(span.Start) {
+			span.Start = entry.Timestamp
+			span.First = entry.ID()
+		}
+		if entry.Timestamp.After(span.End) {
+			span.End = entry.Timestamp
+		}
+	}
+
+	children := make(map[string][]*Span)
+	var roots []*Span
+	for _, id := range order {
+		span := spans[id]
+		if _, ok := spans[span.ParentID]; ok && !cyclic(spans, span) {
+			children[span.ParentID] = append(children[span.ParentID], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	tree := make([]Span, 0, len(order))
+	var walk func(level []*Span, depth int)
+	walk = func(level []*Span, depth int) {
+		sort.SliceStable(level, func(i, j int) bool {
+			return level[i].Start.Before(level[j].Start)
+		})
+		for _, span := range level {
+			span.Depth = depth
+			tree = append(tree, *span)
+			walk(children[span.ID], depth+1)
+		}
+	}
+	walk(roots, 0)
+	return tree
+}
+
+// cyclic reports whether following the parents of span leads back to it.
+func cyclic(spans map[string]*Span, span *Span) bool {
+	seen := map[string]bool{span.ID: true}
+	for parent := spans[span.ParentID]; parent != nil; parent = spans[parent.ParentID] {
+		if seen[parent.ID] {
+			return true
+		}
+		seen[parent.ID] = true
+	}
+	return false
+}
+
+// fieldString returns the value of a field as text, or "" if entry doesn't
+// have it.
+func fieldString(entry logentry.Entry, key string) string {
+	value, ok := entry.GetField(key)
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
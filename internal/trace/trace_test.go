@@ -0,0 +1,62 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+func TestKeyOf(t *testing.T) {
+	entry := logentry.Entry{Fields: map[string]any{"request_id": "r1", "span_id": "s1", "trace_id": ""}}
+
+	key, value, ok := KeyOf(entry, DefaultKeys)
+	if !ok || key != "request_id" || value != "r1" {
+		t.Errorf("KeyOf() = %q, %v, %v; want request_id r1, skipping the empty trace_id", key, value, ok)
+	}
+	if _, _, ok := KeyOf(entry, []string{"user_id"}); ok {
+		t.Error("KeyOf() found a key the entry doesn't have")
+	}
+}
+
+func TestTree(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	span := func(offset int64, ms int, id, parent, msg string) logentry.Entry {
+		fields := map[string]any{"span_id": id}
+		if parent != "" {
+			fields["parent_span_id"] = parent
+		}
+		return logentry.Entry{Message: msg, Timestamp: base.Add(time.Duration(ms) * time.Millisecond), Fields: fields, Offset: offset}
+	}
+	entries := []logentry.Entry{
+		span(0, 0, "root", "", "GET /checkout"),
+		span(1, 40, "db", "root", "query orders"),
+		span(2, 10, "auth", "root", "check token"),
+		span(3, 25, "auth", "root", "token ok"),
+		span(4, 90, "db", "root", "query done"),
+		span(5, 95, "cache", "db", "cache miss"),
+		span(6, 120, "root", "", "200 OK"),
+		span(7, 50, "orphan", "missing", "background job"),
+		{Message: "no span", Offset: 8},
+	}
+
+	var got []string
+	for _, s := range Tree(entries) {
+		got = append(got, strings.Repeat(" ", s.Depth)+s.ID+" "+s.Duration().String())
+	}
+	want := []string{"root 120ms", " auth 15ms", " db 50ms", "  cache 0s", "orphan 0s"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Tree() = %q, want %q", got, want)
+	}
+
+	tree := Tree(entries)
+	if tree[1].Entries != 2 || tree[1].First != entries[2].ID() || tree[1].Name != "check token" {
+		t.Errorf("auth span = %+v, want 2 entries starting at check token", tree[1])
+	}
+
+	cycle := []logentry.Entry{span(0, 0, "a", "b", "a"), span(1, 1, "b", "a", "b")}
+	if got := len(Tree(cycle)); got != 2 {
+		t.Errorf("Tree() of a cycle = %d spans, want both as roots", got)
+	}
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/theme"
+)
+
+// BookmarkItem is a bookmarked entry shown in the bookmarks panel.
+type BookmarkItem struct {
+	// Name is the letter of a named mark, or empty.
+	Name    string
+	Source  string
+	Line    int
+	Message string
+}
+
+// BookmarksPanel lists the bookmarked entries of the open file.
+type BookmarksPanel struct {
+	theme     theme.Theme
+	bookmarks []BookmarkItem
+	selected  int
+	offset    int
+	visible   bool
+	width     int
+	height    int
+}
+
+// NewBookmarksPanel creates a new bookmarks panel.
+func NewBookmarksPanel(theme theme.Theme) BookmarksPanel {
+	return BookmarksPanel{
+		theme:  theme,
+		width:  80,
+		height: 24,
+	}
+}
+
+// SetBookmarks sets the bookmarks to list.
+func (p *BookmarksPanel) SetBookmarks(bookmarks []BookmarkItem) {
+	p.bookmarks = bookmarks
+	if p.selected >= len(bookmarks) {
+		p.selected = max(len(bookmarks)-1, 0)
+	}
+}
+
+// Show shows the panel.
+func (p *BookmarksPanel) Show() {
+	p.visible = true
+}
+
+// Hide hides the panel.
+func (p *BookmarksPanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible returns whether the panel is visible.
+func (p BookmarksPanel) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the dimensions of the panel.
+func (p *BookmarksPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetTheme sets the theme.
+func (p *BookmarksPanel) SetTheme(theme theme.Theme) {
+	p.theme = theme
+}
+
+// Update handles key input.
+func (p BookmarksPanel) Update(msg tea.Msg) (BookmarksPanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "B":
+		p.Hide()
+	case "up", "k":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j":
+		if p.selected < len(p.bookmarks)-1 {
+			p.selected++
+		}
+	case "g":
+		p.selected = 0
+	case "G":
+		if len(p.bookmarks) > 0 {
+			p.selected = len(p.bookmarks) - 1
+		}
+	case "d", "x":
+		if len(p.bookmarks) == 0 {
+			return p, nil
+		}
+		index := p.selected
+		return p, func() tea.Msg {
+			return DeleteBookmarkMsg{Index: index}
+		}
+	case "enter":
+		if len(p.bookmarks) == 0 {
+			return p, nil
+		}
+		index := p.selected
+		p.Hide()
+		return p, func() tea.Msg {
+			return GoToBookmarkMsg{Index: index}
+		}
+	}
+	return p, nil
+}
+
+// View renders the panel.
+func (p *BookmarksPanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	colors := p.theme.Colors()
+
+	containerWidth := p.width - 8
+	if containerWidth < 40 {
+		containerWidth = 40
+	}
+	if containerWidth > 90 {
+		containerWidth = 90
+	}
+	contentWidth := containerWidth - 6
+
+	var content strings.Builder
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Bold(true)
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Faint(true)
+	footerStyle := lipgloss.NewStyle().
+		Foreground(colors.Foreground).
+		Faint(true)
+
+	title := "Bookmarks"
+	if len(p.bookmarks) > 0 {
+		title = fmt.Sprintf("Bookmarks (%d/%d)", p.selected+1, len(p.bookmarks))
+	}
+	content.WriteString(headerStyle.Render(title))
+	content.WriteString("\n")
+	content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+	content.WriteString("\n")
+	content.WriteString(p.renderList(contentWidth))
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("j/k navigate  enter jump  d delete  esc close"))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Padding(1, 2).
+		Width(containerWidth)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content.String()),
+	)
+}
+
+// renderList renders the visible slice of bookmarks.
+func (p *BookmarksPanel) renderList(width int) string {
+	colors := p.theme.Colors()
+
+	if len(p.bookmarks) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(colors.Warn).
+			Italic(true).
+			Padding(1, 0)
+		return emptyStyle.Render("No bookmarks; b bookmarks an entry, m{a-z} sets a mark") + "\n"
+	}
+
+	visibleHeight := p.height - 12
+	if visibleHeight < 3 {
+		visibleHeight = 3
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+visibleHeight {
+		p.offset = p.selected - visibleHeight + 1
+	}
+	end := p.offset + visibleHeight
+	if end > len(p.bookmarks) {
+		end = len(p.bookmarks)
+	}
+
+	var b strings.Builder
+	for i := p.offset; i < end; i++ {
+		bookmark := p.bookmarks[i]
+		name := bookmark.Name
+		if name == "" {
+			name = "●"
+		}
+		line := fmt.Sprintf("%s %6d  ", name, bookmark.Line)
+		if bookmark.Source != "" {
+			line += filepath.Base(bookmark.Source) + "  "
+		}
+		line = truncateText(line+bookmark.Message, width-2)
+
+		itemStyle := lipgloss.NewStyle().
+			Foreground(colors.Foreground).
+			Width(width).
+			Padding(0, 1)
+		if i == p.selected {
+			itemStyle = itemStyle.
+				Foreground(colors.Background).
+				Background(colors.Info).
+				Bold(true)
+		}
+		b.WriteString(itemStyle.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -57,6 +57,11 @@ func (f *FilePicker) SetFiles(files []string) {
 	f.searchMode = false
 }
 
+// Files returns the files found for the picker.
+func (f FilePicker) Files() []string {
+	return f.files
+}
+
 // Show shows the file picker.
 func (f *FilePicker) Show() {
 	f.visible = true
@@ -90,6 +95,13 @@ func (f *FilePicker) Update(msg tea.Msg) (*FilePicker, tea.Cmd) {
 			return f.handleSearchInput(msg)
 		}
 		return f.handleNormalInput(msg)
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			f.selectPrev()
+		case tea.MouseButtonWheelDown:
+			f.selectNext()
+		}
 	}
 
 	return f, nil
@@ -3,13 +3,19 @@ package ui
 import (
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/ersanisk/sieve/internal/filter"
 	"github.com/ersanisk/sieve/internal/theme"
 )
 
+// maxCompletions is the number of completions shown in the popup.
+const maxCompletions = 8
+
 // FilterBar handles filter input.
 type FilterBar struct {
 	textInput textinput.Model
@@ -18,6 +24,13 @@ type FilterBar struct {
 	width     int
 	height    int
 	theme     theme.Theme
+	history   inputHistory
+
+	stats         *filter.FieldStats
+	completion    filter.Completion
+	completions   []string
+	completionSel int
+	parseErr      string
 }
 
 // NewFilterBar creates a new FilterBar.
@@ -26,6 +39,8 @@ func NewFilterBar(theme theme.Theme) FilterBar {
 	ti.Placeholder = "Filter expression (e.g., .level >= 30)"
 	ti.Prompt = "F: "
 	ti.CharLimit = 200
+	ti.ShowSuggestions = true
+	ti.KeyMap = historyKeyMap()
 	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
 	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
 	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
@@ -45,13 +60,17 @@ func (m *FilterBar) Show() {
 	m.visible = true
 	m.focused = true
 	m.textInput.Focus()
+	m.history.reset()
+	m.refresh()
 }
 
 // Hide hides the filter bar.
 func (m *FilterBar) Hide() {
 	m.visible = false
 	m.focused = false
+	m.history.cancel(&m.textInput)
 	m.textInput.Blur()
+	m.completions = nil
 }
 
 // IsVisible returns true if the filter bar is visible.
@@ -67,6 +86,7 @@ func (m *FilterBar) IsFocused() bool {
 // SetValue sets the filter value.
 func (m *FilterBar) SetValue(value string) {
 	m.textInput.SetValue(value)
+	m.refresh()
 }
 
 // GetValue returns the current filter value.
@@ -79,6 +99,35 @@ func (m *FilterBar) Clear() {
 	m.textInput.Reset()
 }
 
+// SetHistory sets previously submitted filters for recall and autocompletion,
+// oldest first.
+func (m *FilterBar) SetHistory(entries []string) {
+	m.history.setEntries(entries)
+	m.textInput.SetSuggestions(m.history.suggestions())
+}
+
+// IsSearchingHistory returns true while a reverse history search is active.
+func (m *FilterBar) IsSearchingHistory() bool {
+	return m.history.searching
+}
+
+// SetFieldStats sets the field statistics used for field and value completion.
+func (m *FilterBar) SetFieldStats(stats *filter.FieldStats) {
+	m.stats = stats
+	m.refresh()
+}
+
+// Completions returns the completions currently offered for the input.
+func (m *FilterBar) Completions() []string {
+	return m.completions
+}
+
+// ParseError returns the parse error for the current input, or an empty
+// string if it is valid or empty.
+func (m *FilterBar) ParseError() string {
+	return m.parseErr
+}
+
 // SetSize sets the dimensions of the filter bar.
 func (m *FilterBar) SetSize(width, height int) {
 	m.width = width
@@ -111,19 +160,152 @@ func (m FilterBar) Update(msg tea.Msg) (FilterBar, tea.Cmd) {
 			// ESC tuşunu consume etme, parent'a geçmesine izin ver
 			return m, nil
 		}
+		if len(m.completions) > 0 && !m.history.searching {
+			switch {
+			case key.Matches(keyMsg, m.textInput.KeyMap.AcceptSuggestion):
+				m.acceptCompletion()
+				return m, nil
+			case key.Matches(keyMsg, m.textInput.KeyMap.NextSuggestion):
+				m.completionSel = (m.completionSel + 1) % len(m.completions)
+				return m, nil
+			case key.Matches(keyMsg, m.textInput.KeyMap.PrevSuggestion):
+				m.completionSel = (m.completionSel - 1 + len(m.completions)) % len(m.completions)
+				return m, nil
+			}
+		}
+		if m.history.handleKey(&m.textInput, keyMsg) {
+			m.refresh()
+			return m, nil
+		}
 	}
 
 	var cmd tea.Cmd
 	m.textInput, cmd = m.textInput.Update(msg)
+	m.refresh()
 	return m, cmd
 }
 
+// refresh revalidates the input and recomputes completions for it.
+func (m *FilterBar) refresh() {
+	value := m.textInput.Value()
+
+	m.parseErr = ""
+	if strings.TrimSpace(value) != "" {
+		if _, err := filter.Parse(value); err != nil {
+			m.parseErr = err.Error()
+		}
+	}
+
+	m.completions = nil
+	m.completionSel = 0
+	m.textInput.ShowSuggestions = true
+	if m.stats == nil || m.history.searching || m.textInput.Position() != len(value) {
+		return
+	}
+
+	m.completion = filter.CompletionAt(value)
+	var items []string
+	switch m.completion.Kind {
+	case filter.CompleteField:
+		items = m.stats.Fields(m.completion.Prefix)
+	case filter.CompleteValue:
+		items = m.stats.Values(m.completion.Field, m.completion.Prefix, maxCompletions)
+	}
+	// Nothing left to complete if the only candidate is already typed.
+	if len(items) == 1 && items[0] == m.completion.Prefix && m.completion.Kind == filter.CompleteField {
+		items = nil
+	}
+	if len(items) > maxCompletions {
+		items = items[:maxCompletions]
+	}
+	m.completions = items
+	// The popup replaces inline history suggestions while it is open.
+	m.textInput.ShowSuggestions = len(items) == 0
+}
+
+// acceptCompletion replaces the token being typed with the selected completion.
+func (m *FilterBar) acceptCompletion() {
+	item := m.completions[m.completionSel]
+	value := m.textInput.Value()[:m.completion.Start]
+	if m.completion.Kind == filter.CompleteValue {
+		quote := string(m.completion.Quote)
+		item = strings.NewReplacer(`\`, `\\`, quote, `\`+quote).Replace(item) + quote
+	}
+	m.textInput.SetValue(value + item)
+	m.textInput.CursorEnd()
+	m.refresh()
+}
+
 // View renders the filter bar.
 func (m FilterBar) View() string {
 	if !m.visible {
 		return ""
 	}
-	return m.textInput.View()
+
+	colors := m.theme.Colors()
+	var b strings.Builder
+	b.WriteString(m.textInput.View())
+
+	if m.parseErr != "" {
+		errStyle := lipgloss.NewStyle().Foreground(colors.Error)
+		errText := m.parseErr
+		if m.width > 4 {
+			errText = truncateText(errText, m.width-4)
+		}
+		b.WriteString("\n")
+		b.WriteString(errStyle.Render("✗ " + errText))
+	}
+
+	if len(m.completions) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.renderCompletions())
+	}
+	return b.String()
+}
+
+// renderCompletions renders the completion popup under the token being typed.
+func (m FilterBar) renderCompletions() string {
+	colors := m.theme.Colors()
+
+	itemWidth := 0
+	for _, item := range m.completions {
+		if w := lipgloss.Width(item); w > itemWidth {
+			itemWidth = w
+		}
+	}
+	if m.width > 10 && itemWidth > m.width/2 {
+		itemWidth = m.width / 2
+	}
+
+	lines := make([]string, len(m.completions))
+	for i, item := range m.completions {
+		style := lipgloss.NewStyle().
+			Foreground(colors.Foreground).
+			Width(itemWidth+2).
+			Padding(0, 1)
+		if i == m.completionSel {
+			style = style.
+				Foreground(colors.Background).
+				Background(colors.Info).
+				Bold(true)
+		}
+		lines[i] = style.Render(truncateText(item, itemWidth))
+	}
+
+	popup := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Render(strings.Join(lines, "\n"))
+
+	// Line the popup up with the token being completed.
+	indent := lipgloss.Width(m.textInput.Prompt) + m.completion.Start - 1
+	if maxIndent := m.width - lipgloss.Width(popup); indent > maxIndent {
+		indent = maxIndent
+	}
+	if indent < 0 {
+		indent = 0
+	}
+	return lipgloss.NewStyle().MarginLeft(indent).Render(popup)
 }
 
 // Focus sets focus to the filter bar.
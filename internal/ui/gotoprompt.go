@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/theme"
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// GotoPreview describes where a goto prompt input would jump.
+type GotoPreview struct {
+	// Row is the index of the target in the view, or -1 if there is none.
+	Row   int
+	Total int
+	Entry logentry.Entry
+	Err   string
+}
+
+// GotoPrompt is the ":" prompt for jumping to a line, a time or a position.
+// It previews the target while typing.
+type GotoPrompt struct {
+	theme   theme.Theme
+	input   textinput.Model
+	preview GotoPreview
+	visible bool
+	width   int
+	height  int
+}
+
+// NewGotoPrompt creates a new goto prompt.
+func NewGotoPrompt(theme theme.Theme) GotoPrompt {
+	ti := textinput.New()
+	ti.Placeholder = "1234, 10:42:05, @2024-01-15T10:42 or 50%"
+	ti.Prompt = ":"
+	ti.CharLimit = 64
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Colors().Highlight).Bold(true)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(theme.Colors().Highlight)
+
+	return GotoPrompt{
+		theme:   theme,
+		input:   ti,
+		preview: GotoPreview{Row: -1},
+		width:   80,
+		height:  24,
+	}
+}
+
+// Show shows an empty prompt.
+func (p *GotoPrompt) Show() {
+	p.visible = true
+	p.input.Reset()
+	p.input.Focus()
+	p.preview = GotoPreview{Row: -1}
+}
+
+// Hide hides the prompt.
+func (p *GotoPrompt) Hide() {
+	p.visible = false
+	p.input.Blur()
+}
+
+// IsVisible returns whether the prompt is visible.
+func (p GotoPrompt) IsVisible() bool {
+	return p.visible
+}
+
+// Value returns the typed target without the leading ':'.
+func (p GotoPrompt) Value() string {
+	return strings.TrimSpace(p.input.Value())
+}
+
+// SetValue sets the typed target.
+func (p *GotoPrompt) SetValue(value string) {
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+}
+
+// SetPreview sets the target shown under the input.
+func (p *GotoPrompt) SetPreview(preview GotoPreview) {
+	p.preview = preview
+}
+
+// SetSize sets the dimensions of the prompt.
+func (p *GotoPrompt) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetTheme sets the theme.
+func (p *GotoPrompt) SetTheme(theme theme.Theme) {
+	p.theme = theme
+}
+
+// Update handles key input. Enter sends a GotoSubmitMsg.
+func (p GotoPrompt) Update(msg tea.Msg) (GotoPrompt, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyEsc:
+		p.Hide()
+		return p, nil
+	case tea.KeyEnter:
+		input := p.Value()
+		if input == "" || p.preview.Err != "" {
+			return p, nil
+		}
+		p.Hide()
+		return p, func() tea.Msg {
+			return GotoSubmitMsg{Input: input}
+		}
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
+
+// View renders the prompt.
+func (p *GotoPrompt) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	colors := p.theme.Colors()
+
+	containerWidth := p.width - 8
+	if containerWidth < 40 {
+		containerWidth = 40
+	}
+	if containerWidth > 90 {
+		containerWidth = 90
+	}
+	contentWidth := containerWidth - 6
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Bold(true)
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Faint(true)
+	footerStyle := lipgloss.NewStyle().
+		Foreground(colors.Foreground).
+		Faint(true)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("Go to Line, Time or Position"))
+	content.WriteString("\n")
+	content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+	content.WriteString("\n\n")
+	content.WriteString(p.input.View())
+	content.WriteString("\n\n")
+	content.WriteString(p.renderPreview(contentWidth))
+	content.WriteString("\n\n")
+	content.WriteString(footerStyle.Render("enter jump  esc cancel"))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Padding(1, 2).
+		Width(containerWidth)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content.String()),
+	)
+}
+
+// renderPreview renders the target entry, or why there is none.
+func (p *GotoPrompt) renderPreview(width int) string {
+	colors := p.theme.Colors()
+	faint := lipgloss.NewStyle().Foreground(colors.Foreground).Faint(true)
+
+	switch {
+	case p.preview.Err != "":
+		return lipgloss.NewStyle().Foreground(colors.Error).Render(truncateText("✗ "+p.preview.Err, width))
+	case p.preview.Row < 0:
+		return faint.Render("line number, clock time, @date-time or percentage")
+	}
+
+	entry := p.preview.Entry
+	position := fmt.Sprintf("→ row %d/%d", p.preview.Row+1, p.preview.Total)
+	if entry.Line > 0 {
+		position += fmt.Sprintf("  line %d", entry.Line)
+	}
+	if !entry.Timestamp.IsZero() {
+		position += "  " + entry.Timestamp.Format(time.DateTime)
+	}
+
+	line := entry.Message
+	if line == "" {
+		line = entry.Raw
+	}
+	if entry.Level != logentry.Unknown {
+		line = fmt.Sprintf("[%s] %s", entry.Level.String(), line)
+	}
+	return faint.Render(position) + "\n" +
+		p.theme.LevelStyle(entry.Level).Render(truncateText(line, width))
+}
@@ -17,6 +17,19 @@ type Help struct {
 	height       int
 	scrollOffset int
 	theme        theme.Theme
+	sections     []HelpSection
+}
+
+// HelpSection is a titled group of key bindings in the help overlay.
+type HelpSection struct {
+	Title    string
+	Bindings []HelpBinding
+}
+
+// HelpBinding is a key and what it does.
+type HelpBinding struct {
+	Key         string
+	Description string
 }
 
 // NewHelp creates a new Help overlay.
@@ -54,6 +67,12 @@ func (m *Help) GetSize() (int, int) {
 	return m.width, m.height
 }
 
+// SetSections sets the sections of key bindings listed before the keys of
+// inputs and panes.
+func (m *Help) SetSections(sections []HelpSection) {
+	m.sections = sections
+}
+
 // SetTheme sets the theme.
 func (m *Help) SetTheme(theme theme.Theme) {
 	m.theme = theme
@@ -70,6 +89,13 @@ func (m Help) Update(msg tea.Msg) (Help, tea.Cmd) {
 		m.scrollToTop()
 	case ScrollToBottomMsg:
 		m.scrollToBottom()
+	case tea.MouseMsg:
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scrollUp(WheelStep)
+		case tea.MouseButtonWheelDown:
+			m.scrollDown(WheelStep)
+		}
 	}
 	return m, nil
 }
@@ -199,59 +225,41 @@ func (m Help) View() string {
 // renderContent renders the help content.
 func (m Help) renderContent() string {
 	var builder strings.Builder
-
-	builder.WriteString(m.renderSection("Navigation", []keyBinding{
-		{"j / ↓", "Scroll down"},
-		{"k / ↑", "Scroll up"},
-		{"g", "Go to top"},
-		{"G", "Go to bottom"},
-		{"PgDn / Space", "Page down"},
-		{"PgUp", "Page up"},
-	}))
-
-	builder.WriteString("\n")
-	builder.WriteString(m.renderSection("Search & Filter", []keyBinding{
-		{"/", "Search"},
-		{"n", "Next search result"},
-		{"N", "Previous search result"},
-		{"f", "Filter expression"},
-		{"Esc", "Clear filter/Cancel"},
-	}))
-
-	builder.WriteString("\n")
-	builder.WriteString(m.renderSection("Level Filter", []keyBinding{
-		{"1", "Debug"},
-		{"2", "Info"},
-		{"3", "Warn"},
-		{"4", "Error"},
-		{"5", "Fatal"},
-		{"0", "No filter"},
-	}))
-
-	builder.WriteString("\n")
-	builder.WriteString(m.renderSection("View & Actions", []keyBinding{
-		{"Enter", "View log details"},
-		{"Tab", "Toggle sidebar"},
-		{"d", "Toggle dashboard"},
-		{"?", "Toggle help"},
-		{"Esc", "Close overlay / exit mode"},
-	}))
-
-	builder.WriteString("\n")
-	builder.WriteString(m.renderSection("File & Program", []keyBinding{
-		{"r", "Toggle sort order"},
-		{"R", "Refresh file"},
-		{"F", "Toggle follow mode"},
-		{"Ctrl+C", "Force quit"},
-		{"q", "Quit"},
-	}))
-
-	builder.WriteString("\n")
+	for _, section := range m.sections {
+		builder.WriteString(m.renderSection(section))
+		builder.WriteString("\n")
+	}
+	for _, section := range componentSections {
+		builder.WriteString(m.renderSection(section))
+		builder.WriteString("\n")
+	}
 	builder.WriteString(m.renderFilterExamples())
 
 	return builder.String()
 }
 
+// componentSections are the keys handled inside inputs and panes, and the
+// mouse, listed after the sections of actions.
+var componentSections = []HelpSection{
+	{Title: "Inputs & Inspector", Bindings: []HelpBinding{
+		{"↑/↓", "Recall history (in input)"},
+		{"Ctrl+R", "Search history (in input)"},
+		{"Tab", "Complete field/value (in filter)"},
+		{"Ctrl+T", "Switch search mode (in search)"},
+		{"j / k, Enter", "Move, expand field (in inspector)"},
+		{"c", "Pin field as column (in inspector)"},
+		{"y / Y", "Copy field / path (in inspector)"},
+		{"Esc", "Close overlay / exit mode"},
+	}},
+	{Title: "Mouse", Bindings: []HelpBinding{
+		{"Wheel", "Scroll log, inspector or overlay"},
+		{"Click", "Select entry"},
+		{"Double-click", "View log details"},
+		{"Click field", "Add field to filter"},
+		{"Drag border", "Resize inspector"},
+	}},
+}
+
 func (m Help) renderFilterExamples() string {
 	exampleStyle := lipgloss.NewStyle().
 		Foreground(m.theme.Colors().Value).
@@ -272,7 +280,7 @@ Operators:
 }
 
 // renderSection renders a help section.
-func (m Help) renderSection(title string, bindings []keyBinding) string {
+func (m Help) renderSection(section HelpSection) string {
 	headerStyle := lipgloss.NewStyle().
 		Foreground(m.theme.Colors().Foreground).
 		Bold(true)
@@ -282,20 +290,14 @@ func (m Help) renderSection(title string, bindings []keyBinding) string {
 		Foreground(m.theme.Colors().Foreground)
 
 	var builder strings.Builder
-	builder.WriteString(headerStyle.Render(title))
+	builder.WriteString(headerStyle.Render(section.Title))
 	builder.WriteString(":\n")
 
-	for _, binding := range bindings {
-		keyText := keyStyle.Render(fmt.Sprintf("%-10s", binding.key))
-		descText := descStyle.Render(binding.description)
+	for _, binding := range section.Bindings {
+		keyText := keyStyle.Render(fmt.Sprintf("%-10s", binding.Key))
+		descText := descStyle.Render(binding.Description)
 		builder.WriteString(fmt.Sprintf("  %s %s\n", keyText, descText))
 	}
 
 	return builder.String()
 }
-
-// keyBinding represents a keyboard binding.
-type keyBinding struct {
-	key         string
-	description string
-}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxHistorySuggestions caps how many recent entries are offered as
+// autocomplete suggestions.
+const maxHistorySuggestions = 50
+
+// inputHistory adds shell-style history recall to a text input:
+// up/down walk through previous entries and ctrl+r starts a reverse
+// incremental search.
+type inputHistory struct {
+	entries []string // oldest first
+	index   int      // position while browsing; len(entries) means not browsing
+	draft   string   // input typed before browsing started
+
+	searching   bool
+	searchQuery string
+	searchPos   int // index of the current reverse-search match
+	prompt      string
+}
+
+// setEntries replaces the recalled entries and resets browsing.
+func (h *inputHistory) setEntries(entries []string) {
+	h.entries = entries
+	h.reset()
+}
+
+// reset stops browsing and searching.
+func (h *inputHistory) reset() {
+	h.index = len(h.entries)
+	h.draft = ""
+	h.searching = false
+	h.searchQuery = ""
+}
+
+// suggestions returns recent entries, most recent first, for autocompletion.
+func (h *inputHistory) suggestions() []string {
+	n := len(h.entries)
+	if n > maxHistorySuggestions {
+		n = maxHistorySuggestions
+	}
+	sugs := make([]string, 0, n)
+	for i := len(h.entries) - 1; i >= len(h.entries)-n; i-- {
+		sugs = append(sugs, h.entries[i])
+	}
+	return sugs
+}
+
+// handleKey processes history keys for ti. It returns true if the key was
+// consumed.
+func (h *inputHistory) handleKey(ti *textinput.Model, msg tea.KeyMsg) bool {
+	if h.searching {
+		return h.handleSearchKey(ti, msg)
+	}
+
+	switch msg.Type {
+	case tea.KeyUp:
+		h.prev(ti)
+		return true
+	case tea.KeyDown:
+		h.next(ti)
+		return true
+	case tea.KeyCtrlR:
+		h.startSearch(ti)
+		return true
+	}
+	return false
+}
+
+func (h *inputHistory) prev(ti *textinput.Model) {
+	if h.index == 0 || len(h.entries) == 0 {
+		return
+	}
+	if h.index == len(h.entries) {
+		h.draft = ti.Value()
+	}
+	h.index--
+	ti.SetValue(h.entries[h.index])
+	ti.CursorEnd()
+}
+
+func (h *inputHistory) next(ti *textinput.Model) {
+	if h.index >= len(h.entries) {
+		return
+	}
+	h.index++
+	if h.index == len(h.entries) {
+		ti.SetValue(h.draft)
+	} else {
+		ti.SetValue(h.entries[h.index])
+	}
+	ti.CursorEnd()
+}
+
+func (h *inputHistory) startSearch(ti *textinput.Model) {
+	h.searching = true
+	h.searchQuery = ""
+	h.searchPos = len(h.entries)
+	h.prompt = ti.Prompt
+	h.updateSearchPrompt(ti, true)
+}
+
+// cancel ends any active reverse search, restoring the prompt.
+func (h *inputHistory) cancel(ti *textinput.Model) {
+	if h.searching {
+		h.stopSearch(ti)
+	}
+}
+
+func (h *inputHistory) stopSearch(ti *textinput.Model) {
+	h.searching = false
+	ti.Prompt = h.prompt
+	h.index = len(h.entries)
+}
+
+func (h *inputHistory) handleSearchKey(ti *textinput.Model, msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyCtrlR:
+		// Look for an older match of the same query.
+		h.updateSearchPrompt(ti, h.findMatch(ti, h.searchPos-1))
+		return true
+	case tea.KeyBackspace:
+		if h.searchQuery != "" {
+			runes := []rune(h.searchQuery)
+			h.searchQuery = string(runes[:len(runes)-1])
+			h.updateSearchPrompt(ti, h.findMatch(ti, len(h.entries)-1))
+		}
+		return true
+	case tea.KeyRunes, tea.KeySpace:
+		typed := string(msg.Runes)
+		if typed == "" && msg.Type == tea.KeySpace {
+			typed = " "
+		}
+		h.searchQuery += typed
+		h.updateSearchPrompt(ti, h.findMatch(ti, h.searchPos))
+		return true
+	case tea.KeyCtrlG:
+		h.stopSearch(ti)
+		ti.SetValue("")
+		return true
+	}
+
+	// Any other key accepts the match and is handled normally.
+	h.stopSearch(ti)
+	return false
+}
+
+// findMatch searches backwards from index for an entry containing the query
+// and loads it into the input. It returns false when nothing matches.
+func (h *inputHistory) findMatch(ti *textinput.Model, from int) bool {
+	if from >= len(h.entries) {
+		from = len(h.entries) - 1
+	}
+	query := strings.ToLower(h.searchQuery)
+	for i := from; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(h.entries[i]), query) {
+			h.searchPos = i
+			ti.SetValue(h.entries[i])
+			ti.CursorEnd()
+			return true
+		}
+	}
+	return false
+}
+
+func (h *inputHistory) updateSearchPrompt(ti *textinput.Model, found bool) {
+	label := "reverse-i-search"
+	if !found && h.searchQuery != "" {
+		label = "failing reverse-i-search"
+	}
+	ti.Prompt = fmt.Sprintf("(%s)`%s': ", label, h.searchQuery)
+}
+
+// historyKeyMap returns the text input key map with suggestion cycling moved
+// off up/down so those keys can walk the history.
+func historyKeyMap() textinput.KeyMap {
+	km := textinput.DefaultKeyMap
+	km.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+	km.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
+	return km
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Layout defaults and limits.
+const (
+	// DefaultPaneRatio is the share of the screen the inspector pane gets.
+	DefaultPaneRatio = 0.35
+	// DefaultStackWidth is the terminal width below which the panes are
+	// stacked instead of placed side by side.
+	DefaultStackWidth = 100
+
+	minPaneRatio  = 0.15
+	maxPaneRatio  = 0.75
+	paneRatioStep = 0.05
+)
+
+// Layout splits the screen between the log view and the inspector pane:
+// side by side on wide terminals, the inspector below the log view on
+// narrow ones.
+type Layout struct {
+	ratio      float64
+	stackWidth int
+	width      int
+	height     int
+}
+
+// NewLayout creates a layout giving the inspector pane ratio of the screen.
+// Panes are stacked when the screen is narrower than stackWidth. Zero values
+// take the defaults.
+func NewLayout(ratio float64, stackWidth int) Layout {
+	if ratio <= 0 {
+		ratio = DefaultPaneRatio
+	}
+	if stackWidth <= 0 {
+		stackWidth = DefaultStackWidth
+	}
+	return Layout{
+		ratio:      clampRatio(ratio),
+		stackWidth: stackWidth,
+	}
+}
+
+// clampRatio keeps a pane ratio within the limits.
+func clampRatio(ratio float64) float64 {
+	return math.Max(minPaneRatio, math.Min(ratio, maxPaneRatio))
+}
+
+// SetSize sets the size of the area the panes share.
+func (l *Layout) SetSize(width, height int) {
+	l.width = width
+	l.height = height
+}
+
+// Size returns the size of the area the panes share.
+func (l Layout) Size() (int, int) {
+	return l.width, l.height
+}
+
+// Ratio returns the share of the screen the inspector pane gets.
+func (l Layout) Ratio() float64 {
+	return l.ratio
+}
+
+// Grow gives the inspector pane steps more of the screen, or less when
+// steps is negative. It reports whether the ratio changed.
+func (l *Layout) Grow(steps int) bool {
+	ratio := clampRatio(math.Round((l.ratio+float64(steps)*paneRatioStep)*100) / 100)
+	changed := ratio != l.ratio
+	l.ratio = ratio
+	return changed
+}
+
+// SetRatio gives the inspector pane ratio of the screen, within the limits.
+// It reports whether the ratio changed.
+func (l *Layout) SetRatio(ratio float64) bool {
+	ratio = clampRatio(math.Round(ratio*100) / 100)
+	changed := ratio != l.ratio
+	l.ratio = ratio
+	return changed
+}
+
+// Stacked reports whether the inspector is placed below the log view.
+func (l Layout) Stacked() bool {
+	return l.width < l.stackWidth
+}
+
+// Split returns the sizes of the log view and the inspector pane.
+func (l Layout) Split() (mainWidth, mainHeight, sideWidth, sideHeight int) {
+	if l.Stacked() {
+		sideHeight = max(int(float64(l.height)*l.ratio), 3)
+		return l.width, l.height - sideHeight, l.width, sideHeight
+	}
+	sideWidth = max(int(float64(l.width)*l.ratio), 20)
+	return l.width - sideWidth, l.height, sideWidth, l.height
+}
+
+// Join places the rendered panes next to or below each other. The log view
+// is padded to its size so the inspector lines up.
+func (l Layout) Join(main, side string) string {
+	mainWidth, mainHeight, _, _ := l.Split()
+	main = lipgloss.NewStyle().
+		Width(mainWidth).
+		Height(mainHeight).
+		MaxWidth(mainWidth).
+		MaxHeight(mainHeight).
+		Render(main)
+	if l.Stacked() {
+		return lipgloss.JoinVertical(lipgloss.Left, main, side)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, main, side)
+}
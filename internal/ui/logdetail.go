@@ -24,10 +24,13 @@ type LogDetail struct {
 
 // NewLogDetail creates a new LogDetail modal.
 func NewLogDetail(theme theme.Theme) *LogDetail {
-	return &LogDetail{
+	detail := &LogDetail{
 		visible: false,
 		theme:   theme,
 	}
+	detail.viewport.MouseWheelEnabled = true
+	detail.viewport.MouseWheelDelta = WheelStep
+	return detail
 }
 
 // Show shows the log detail modal for the given entry.
@@ -61,10 +64,16 @@ func (m *LogDetail) SetSize(width, height int) {
 	m.updateContent()
 }
 
+// SetTheme sets the theme.
+func (m *LogDetail) SetTheme(theme theme.Theme) {
+	m.theme = theme
+	m.updateContent()
+}
+
 // Update handles events for the log detail modal.
 func (m *LogDetail) Update(msg any) {
 	// Ideally LogDetail should be a proper tea.Model, but for now we wrap it.
-	// We only care about scrolling keys.
+	// We only care about scrolling keys and the mouse wheel.
 	newViewport, _ := m.viewport.Update(msg)
 	m.viewport = newViewport
 }
@@ -2,39 +2,63 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/clipperhouse/displaywidth"
 
+	"github.com/ersanisk/sieve/internal/columns"
+	"github.com/ersanisk/sieve/internal/search"
 	"github.com/ersanisk/sieve/internal/theme"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
 
-// highlightQuery highlights all occurrences of query in text using a bold+color style.
-func highlightQuery(text, query string, hlStyle lipgloss.Style) string {
-	if query == "" {
-		return text
-	}
-	lower := strings.ToLower(text)
-	lowerQ := strings.ToLower(query)
-	if !strings.Contains(lower, lowerQ) {
+// highlightQuery highlights the spans of text found by spanner using a bold+color style.
+func highlightQuery(text string, spanner func(string) [][2]int, hlStyle lipgloss.Style) string {
+	spans := spanner(text)
+	if len(spans) == 0 {
 		return text
 	}
 
 	var result strings.Builder
-	for len(text) > 0 {
-		idx := strings.Index(strings.ToLower(text), lowerQ)
-		if idx < 0 {
-			result.WriteString(text)
-			break
-		}
-		result.WriteString(text[:idx])
-		result.WriteString(hlStyle.Render(text[idx : idx+len(query)]))
-		text = text[idx+len(query):]
+	last := 0
+	for _, span := range spans {
+		result.WriteString(text[last:span[0]])
+		result.WriteString(hlStyle.Render(text[span[0]:span[1]]))
+		last = span[1]
 	}
+	result.WriteString(text[last:])
 	return result.String()
 }
 
+// Marker is a line shown in the log view above the entry at ID, or above the
+// first entry of the same source after it when that one isn't shown.
+type Marker struct {
+	ID   logentry.ID
+	Text string
+}
+
+// maxSourceWidth is the widest a source label gets.
+const maxSourceWidth = 20
+
+// Column widths of the columns view: cells are sized to their widest value
+// among the first columnSample entries, up to maxColumnWidth.
+const (
+	maxColumnWidth = 40
+	columnSample   = 1000
+	columnGap      = "  "
+)
+
+// relativeTimeWidth is the width of the relative time column, without the
+// space after it.
+const relativeTimeWidth = 10
+
+// minMessageWidth is the narrowest the message column gets, even when the
+// columns before it leave less room.
+const minMessageWidth = 10
+
 // LogView displays log entries with virtual scrolling.
 type LogView struct {
 	entries     []logentry.Entry
@@ -45,7 +69,46 @@ type LogView struct {
 	theme       theme.Theme
 	lineNumbers bool
 	expanded    map[int]bool
-	searchQuery string
+	search      search.Query
+	markers     []Marker
+	// spanner finds the matches of search, compiled once per query.
+	spanner func(text string) [][2]int
+	// sourceWidth is the width of the source label column; 0 hides it.
+	sourceWidth int
+	// bookmarks maps bookmarked entries to their mark, shown in a gutter
+	// column while there are any.
+	bookmarks map[logentry.ID]string
+	// visual is set while a range of entries is selected, from anchor to
+	// the selected entry.
+	visual bool
+	anchor int
+	// wrap wraps long messages over several rows; otherwise they are cut
+	// at the edge and scrolled horizontally by xOffset cells.
+	wrap    bool
+	xOffset int
+	// columns, when set, shows entries as a table of these columns under
+	// a header row instead of timestamp, level and message.
+	columns      []string
+	columnWidths []int
+	sortColumn   string
+	sortDesc     bool
+	// context marks the entries shown around the matches of a filter,
+	// which are dimmed; breaks maps the first entry of each group of them
+	// to how many entries were skipped before it.
+	context map[logentry.ID]bool
+	breaks  map[logentry.ID]int
+	// since, when set, adds a column with how long after it each entry
+	// was logged; sincePrevious instead shows how long after the entry
+	// above it.
+	since         time.Time
+	sincePrevious bool
+	// gap and burst, when set, add separators where the log went quiet for
+	// longer than gap and above runs of at least burst entries logged
+	// within one second; bursts maps the first entry of each run to its
+	// length.
+	gap    time.Duration
+	burst  int
+	bursts map[int]int
 }
 
 // NewLogView creates a new LogView.
@@ -69,6 +132,11 @@ func (m *LogView) SetEntries(entries []logentry.Entry) {
 	if len(entries) == 0 {
 		m.selected = 0
 	}
+	if m.anchor >= len(entries) {
+		m.visual = false
+	}
+	m.sizeColumns()
+	m.bursts = findBursts(entries, m.burst)
 }
 
 // GetEntries returns the current entries.
@@ -143,20 +211,20 @@ func (m *LogView) ScrollDownOne() {
 
 // ScrollPageUp scrolls up by one page.
 func (m *LogView) ScrollPageUp() {
-	m.ScrollUp(m.height)
+	m.ScrollUp(m.bodyHeight())
 }
 
 // ScrollPageDown scrolls down by one page.
 func (m *LogView) ScrollPageDown() {
-	m.ScrollDown(m.height)
+	m.ScrollDown(m.bodyHeight())
 }
 
 // ensureVisible ensures the selected entry is visible.
 func (m *LogView) ensureVisible() {
 	if m.selected < m.offset {
 		m.offset = m.selected
-	} else if m.selected >= m.offset+m.height {
-		m.offset = m.selected - m.height + 1
+	} else if m.selected >= m.offset+m.bodyHeight() {
+		m.offset = m.selected - m.bodyHeight() + 1
 	}
 	if m.offset < 0 {
 		m.offset = 0
@@ -183,9 +251,62 @@ func (m *LogView) SetTheme(theme theme.Theme) {
 	m.theme = theme
 }
 
+// SetMarkers sets the marker lines shown between entries.
+func (m *LogView) SetMarkers(markers []Marker) {
+	m.markers = markers
+}
+
+// SetContext sets the entries shown as context around filter matches and
+// where groups of them start, with the number of entries skipped before
+// each. Nil maps turn the context view off.
+func (m *LogView) SetContext(context map[logentry.ID]bool, breaks map[logentry.ID]int) {
+	m.context = context
+	m.breaks = breaks
+}
+
+// SetRelativeTo shows how long after since each entry was logged, in a
+// column before its timestamp. A zero time hides the column.
+func (m *LogView) SetRelativeTo(since time.Time) {
+	m.since = since
+	m.sincePrevious = false
+}
+
+// SetRelativeToPrevious shows how long after the entry above each entry was
+// logged, in the column of SetRelativeTo.
+func (m *LogView) SetRelativeToPrevious() {
+	m.since = time.Time{}
+	m.sincePrevious = true
+}
+
+// SetTimeMarkers sets how long the log must go quiet for a gap separator to
+// be shown, and how many entries logged within one second are marked as a
+// burst. Zero turns either off.
+func (m *LogView) SetTimeMarkers(gap time.Duration, burst int) {
+	m.gap = gap
+	m.burst = burst
+	m.bursts = findBursts(m.entries, burst)
+}
+
+// SetBookmarks sets the bookmarked entries, each with the letter of its
+// mark or an empty string for an anonymous bookmark.
+func (m *LogView) SetBookmarks(bookmarks map[logentry.ID]string) {
+	m.bookmarks = bookmarks
+}
+
+// SetSourceLabels shows which file each entry comes from, for a view
+// interleaving the given files. No files hide the labels.
+func (m *LogView) SetSourceLabels(sources []string) {
+	m.sourceWidth = 0
+	for _, source := range sources {
+		m.sourceWidth = max(m.sourceWidth, len([]rune(filepath.Base(source))))
+	}
+	m.sourceWidth = min(m.sourceWidth, maxSourceWidth)
+}
+
 // SetSearchQuery sets the current search query for highlighting.
-func (m *LogView) SetSearchQuery(query string) {
-	m.searchQuery = query
+func (m *LogView) SetSearchQuery(query search.Query) {
+	m.search = query
+	m.spanner = query.Spanner()
 }
 
 // ToggleLineNumbers toggles line numbers.
@@ -193,6 +314,113 @@ func (m *LogView) ToggleLineNumbers() {
 	m.lineNumbers = !m.lineNumbers
 }
 
+// SetColumns shows entries as a table of the given columns, or as
+// timestamp, level and message when there are none.
+func (m *LogView) SetColumns(columns []string) {
+	m.columns = append([]string(nil), columns...)
+	m.xOffset = 0
+	m.sizeColumns()
+}
+
+// Columns returns the columns shown, if any.
+func (m *LogView) Columns() []string {
+	return m.columns
+}
+
+// SetSortColumn marks the column entries are sorted by in the header.
+func (m *LogView) SetSortColumn(name string, desc bool) {
+	m.sortColumn = name
+	m.sortDesc = desc
+}
+
+// sizeColumns fits the width of each column to its header and values.
+func (m *LogView) sizeColumns() {
+	m.columnWidths = make([]int, len(m.columns))
+	for i, name := range m.columns {
+		width := displaywidth.String(name) + 2 // room for the sort arrow
+		for _, entry := range m.entries[:min(len(m.entries), columnSample)] {
+			width = max(width, displaywidth.String(columns.Text(entry, name)))
+		}
+		m.columnWidths[i] = min(width, maxColumnWidth)
+	}
+}
+
+// bodyHeight returns the rows left for entries, below the header of the
+// columns view.
+func (m LogView) bodyHeight() int {
+	if len(m.columns) > 0 {
+		return max(m.height-1, 1)
+	}
+	return m.height
+}
+
+// SetWrap sets whether long messages wrap over several rows.
+func (m *LogView) SetWrap(wrap bool) {
+	m.wrap = wrap
+	m.xOffset = 0
+}
+
+// IsWrapped returns whether long messages wrap over several rows.
+func (m *LogView) IsWrapped() bool {
+	return m.wrap
+}
+
+// ScrollLeft scrolls messages amount cells to the left.
+func (m *LogView) ScrollLeft(amount int) {
+	m.xOffset = max(m.xOffset-amount, 0)
+}
+
+// ScrollRight scrolls messages amount cells to the right, up to where the
+// longest shown message ends.
+func (m *LogView) ScrollRight(amount int) {
+	if m.wrap {
+		return
+	}
+	m.xOffset = min(m.xOffset+amount, m.maxXOffset())
+}
+
+// XOffset returns how many cells messages are scrolled to the right.
+func (m *LogView) XOffset() int {
+	return m.xOffset
+}
+
+// maxXOffset returns how far messages scroll right: until the end of the
+// longest message in view shows, or the last column.
+func (m LogView) maxXOffset() int {
+	if len(m.columns) > 0 {
+		return max(m.tableWidth()-m.columnsWidth(), 0)
+	}
+	end := min(max(m.offset, m.selected)+m.height, len(m.entries))
+	furthest := 0
+	for i := min(m.offset, m.selected); i < end; i++ {
+		if i < 0 {
+			continue
+		}
+		furthest = max(furthest, displaywidth.String(m.entries[i].Message)-m.messageWidth(i))
+	}
+	return furthest
+}
+
+// StartVisual starts selecting a range of entries at the selected one.
+func (m *LogView) StartVisual() {
+	m.visual = len(m.entries) > 0
+	m.anchor = m.selected
+}
+
+// StopVisual stops selecting a range of entries.
+func (m *LogView) StopVisual() {
+	m.visual = false
+}
+
+// VisualRange returns the first and last index of the selected range of
+// entries, and whether a range is being selected.
+func (m *LogView) VisualRange() (int, int, bool) {
+	if !m.visual {
+		return 0, 0, false
+	}
+	return min(m.anchor, m.selected), max(m.anchor, m.selected), true
+}
+
 // ToggleExpanded toggles expansion of the selected entry.
 func (m *LogView) ToggleExpanded() {
 	if m.selected >= 0 && m.selected < len(m.entries) {
@@ -226,91 +454,542 @@ func (m LogView) View() string {
 		return m.renderEmpty()
 	}
 
-	visibleEnd := m.offset + m.height
-	if visibleEnd > len(m.entries) {
-		visibleEnd = len(m.entries)
+	height := m.bodyHeight()
+	var rows []string
+	for i := m.firstShown(); i < len(m.entries) && len(rows) < height; i++ {
+		if line, ok := m.lineBefore(i); ok {
+			rows = append(rows, line)
+		}
+		rows = append(rows, m.renderEntry(i)...)
+	}
+	if len(rows) > height {
+		rows = rows[:height]
+	}
+	if len(m.columns) > 0 {
+		rows = append([]string{m.renderHeader()}, rows...)
 	}
 
-	var builder strings.Builder
+	return strings.Join(rows, "\n") + "\n"
+}
+
+// firstShown returns the index of the first entry shown. Wrapped and
+// expanded entries and marker lines take several rows; it starts further
+// down if they would push the selected entry out of view.
+func (m LogView) firstShown() int {
+	start := m.offset
+	for start < m.selected && m.rowsBetween(start, m.selected) > m.bodyHeight() {
+		start++
+	}
+	return start
+}
 
-	for i := m.offset; i < visibleEnd; i++ {
-		if i >= len(m.entries) {
-			break
+// EntryAt returns the index of the entry shown on row y of the view, or -1
+// when the row shows none, such as the column header, a marker line or a
+// separator of the context view.
+func (m LogView) EntryAt(y int) int {
+	if len(m.columns) > 0 {
+		y--
+	}
+	height := m.bodyHeight()
+	if y < 0 || y >= height {
+		return -1
+	}
+	row := 0
+	for i := m.firstShown(); i < len(m.entries) && row < height; i++ {
+		if _, ok := m.lineBefore(i); ok {
+			if y == row {
+				return -1
+			}
+			row++
+		}
+		row += m.entryRows(i)
+		if y < row {
+			return i
 		}
-		builder.WriteString(m.renderEntry(i))
 	}
+	return -1
+}
+
+// rowsBetween returns the rows entries from through to take, with the
+// lines above them.
+func (m LogView) rowsBetween(from, to int) int {
+	rows := 0
+	for i := from; i <= to; i++ {
+		if _, ok := m.lineBefore(i); ok {
+			rows++
+		}
+		rows += m.entryRows(i)
+	}
+	return rows
+}
+
+// entryRows returns the rows the entry at index takes: one per wrapped
+// line of its message, and one per field when it is expanded.
+func (m LogView) entryRows(index int) int {
+	rows := 1
+	if m.wrap && len(m.columns) == 0 {
+		entry := m.entries[index]
+		rows = len(wrapCells(entry.Message, m.messageWidth(index)))
+	}
+	if m.expanded[index] {
+		rows += len(m.entries[index].Fields)
+	}
+	return rows
+}
+
+// lineBefore returns the line shown above the entry at index, if any: a
+// marker, the separator between two groups of the context view, or one
+// marking a gap in time or a burst of entries.
+func (m LogView) lineBefore(index int) (string, bool) {
+	if text, ok := m.markerBefore(index); ok {
+		return m.renderMarker(text), true
+	}
+	if skipped, ok := m.breaks[m.entries[index].ID()]; ok && index > 0 {
+		return m.renderBreak(skipped), true
+	}
+	if text, ok := m.timeMarkerBefore(index); ok {
+		return m.renderSeparator(text), true
+	}
+	return "", false
+}
+
+// timeMarkerBefore returns the text of the separator above the entry at
+// index when the log went quiet for longer than the gap before it, or a
+// burst of entries starts at it. Neither is shown while entries are sorted
+// by a column.
+func (m LogView) timeMarkerBefore(index int) (string, bool) {
+	if m.sortColumn != "" {
+		return "", false
+	}
+	var parts []string
+	if m.gap > 0 && index > 0 {
+		prev, cur := m.entries[index-1].Timestamp, m.entries[index].Timestamp
+		if !prev.IsZero() && !cur.IsZero() {
+			d := cur.Sub(prev)
+			if d < 0 {
+				d = -d
+			}
+			if d > m.gap {
+				parts = append(parts, formatGap(d)+" gap")
+			}
+		}
+	}
+	if n, ok := m.bursts[index]; ok {
+		parts = append(parts, fmt.Sprintf("%d entries in 1s", n))
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, " · "), true
+}
+
+// findBursts returns the first of each run of at least n entries logged
+// within the same second, with the length of the run. It returns nil when n
+// is zero.
+func findBursts(entries []logentry.Entry, n int) map[int]int {
+	if n <= 0 {
+		return nil
+	}
+	bursts := make(map[int]int)
+	start := 0
+	for i := 1; i <= len(entries); i++ {
+		if i < len(entries) && sameSecond(entries[start].Timestamp, entries[i].Timestamp) {
+			continue
+		}
+		if i-start >= n {
+			bursts[start] = i - start
+		}
+		start = i
+	}
+	return bursts
+}
+
+// sameSecond reports whether a and b are set and fall within the same
+// second.
+func sameSecond(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return false
+	}
+	return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+}
+
+// formatGap formats the length of a gap, to the second from a second up.
+func formatGap(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// markerBefore returns the text of a marker that falls between the entry at
+// index and the previous entry of the same source, in either sort order.
+func (m LogView) markerBefore(index int) (string, bool) {
+	if len(m.markers) == 0 || index <= 0 {
+		return "", false
+	}
+	entry := m.entries[index]
+	prev := index - 1
+	for prev >= 0 && m.entries[prev].Source != entry.Source {
+		prev--
+	}
+	if prev < 0 {
+		return "", false
+	}
+	before := m.entries[prev].Offset
+	for _, marker := range m.markers {
+		if marker.ID.Source != entry.Source {
+			continue
+		}
+		if (before < marker.ID.Offset) != (entry.Offset < marker.ID.Offset) {
+			return marker.Text, true
+		}
+	}
+	return "", false
+}
 
-	return builder.String()
+// renderMarker renders a marker line across the view.
+func (m LogView) renderMarker(text string) string {
+	style := lipgloss.NewStyle().Foreground(m.theme.Colors().Warn).Bold(true)
+	return style.Render(m.rule(text))
 }
 
-// renderEntry renders a single log entry.
-func (m LogView) renderEntry(index int) string {
+// renderBreak renders the separator between two groups of the context
+// view, with the number of entries skipped between them.
+func (m LogView) renderBreak(skipped int) string {
+	return m.renderSeparator(fmt.Sprintf("%d hidden", skipped))
+}
+
+// renderSeparator renders a subtle line across the view.
+func (m LogView) renderSeparator(text string) string {
+	style := lipgloss.NewStyle().Foreground(m.theme.Colors().Border).Faint(true)
+	return style.Render(m.rule(text))
+}
+
+// rule returns a line across the view with text in the middle.
+func (m LogView) rule(text string) string {
+	label := " " + text + " "
+	width := m.width - len([]rune(label))
+	if width < 4 {
+		width = 4
+	}
+	left := width / 2
+	return strings.Repeat("─", left) + label + strings.Repeat("─", width-left)
+}
+
+// renderEntry renders the rows of a single log entry.
+func (m LogView) renderEntry(index int) []string {
 	entry := m.entries[index]
 	isSelected := index == m.selected
+	if from, to, ok := m.VisualRange(); ok {
+		isSelected = index >= from && index <= to
+	}
 	isExpanded := m.expanded[index]
+	// Context rows around filter matches are dimmed.
+	dim := m.context[entry.ID()] && !isSelected
 
 	var line strings.Builder
 
+	if len(m.bookmarks) > 0 {
+		line.WriteString(m.renderGutter(entry))
+	}
+
 	if m.lineNumbers {
 		lineNumStyle := m.theme.TimestampStyle()
 		if isSelected {
 			lineNumStyle = lineNumStyle.Background(m.theme.Colors().Highlight).Foreground(m.theme.Colors().Background).Bold(true)
 		}
-		line.WriteString(lineNumStyle.Render(fmt.Sprintf("%5d ", index+1)))
+		lineNumStyle = lineNumStyle.Faint(dim)
+		line.WriteString(lineNumStyle.Render(m.lineNumber(index)))
 	}
 
-	entryStyle := m.theme.LevelStyle(entry.Level)
+	if m.relativeWidth() > 0 {
+		relativeStyle := m.theme.TimestampStyle().Faint(dim)
+		if isSelected {
+			relativeStyle = relativeStyle.Background(m.theme.Colors().Highlight).Foreground(m.theme.Colors().Background)
+		}
+		line.WriteString(relativeStyle.Render(m.relativeTime(index)))
+	}
+
+	entryStyle := m.theme.LevelStyle(entry.Level).Faint(dim)
 	if isSelected {
 		entryStyle = entryStyle.Background(m.theme.Colors().Highlight).Foreground(m.theme.Colors().Background)
 	}
 
 	timestamp := ""
 	if !entry.Timestamp.IsZero() {
-		timestampStyle := m.theme.TimestampStyle()
+		timestampStyle := m.theme.TimestampStyle().Faint(dim)
 		if isSelected {
 			timestampStyle = timestampStyle.Background(m.theme.Colors().Highlight).Foreground(m.theme.Colors().Background)
 		}
-		timestamp = timestampStyle.Render(fmt.Sprintf("[%s] ", entry.Timestamp.Format("15:04:05")))
+		timestamp = timestampStyle.Render(formatTimestamp(entry))
 	}
 
 	level := entryStyle.Render(fmt.Sprintf("%-5s ", entry.Level.String()))
 
-	messageStyle := lipgloss.NewStyle().Foreground(m.theme.Colors().Foreground)
+	source := ""
+	if m.sourceWidth > 0 {
+		sourceStyle := m.theme.KeyStyle().Faint(dim)
+		if isSelected {
+			sourceStyle = sourceStyle.Background(m.theme.Colors().Highlight).Foreground(m.theme.Colors().Background)
+		}
+		label := truncateText(filepath.Base(entry.Source), m.sourceWidth)
+		source = sourceStyle.Render(fmt.Sprintf("%-*s ", m.sourceWidth, label))
+	}
+
+	messageStyle := lipgloss.NewStyle().Foreground(m.theme.Colors().Foreground).Faint(dim)
 	if isSelected {
 		messageStyle = messageStyle.Background(m.theme.Colors().Highlight).Foreground(m.theme.Colors().Background).Bold(true)
 	}
-
-	rawMsg := truncateText(entry.Message, m.width-40)
-	var message string
-	if m.searchQuery != "" && !isSelected {
-		hlStyle := lipgloss.NewStyle().
-			Foreground(m.theme.Colors().Background).
-			Background(m.theme.Colors().Warn).
-			Bold(true)
-		message = messageStyle.Render(highlightQuery(rawMsg, m.searchQuery, hlStyle))
-	} else {
-		message = messageStyle.Render(rawMsg)
+	hlStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Colors().Background).
+		Background(m.theme.Colors().Warn).
+		Bold(true)
+	renderMessage := func(text string) string {
+		if m.search.Text != "" && !isSelected {
+			return messageStyle.Render(highlightQuery(text, m.spanner, hlStyle))
+		}
+		return messageStyle.Render(text)
 	}
 
-	line.WriteString(timestamp)
-	line.WriteString(level)
-	line.WriteString(message)
+	var rows []string
+	switch {
+	case len(m.columns) > 0:
+		line.WriteString(m.renderColumns(entry, isSelected))
+		rows = append(rows, line.String())
+	case m.wrap:
+		line.WriteString(timestamp)
+		line.WriteString(source)
+		line.WriteString(level)
+		width := m.messageWidth(index)
+		// Continuation rows line up under the start of the message.
+		indent := strings.Repeat(" ", m.prefixWidth(index))
+		for i, text := range wrapCells(entry.Message, width) {
+			if i == 0 {
+				rows = append(rows, line.String()+renderMessage(text))
+				continue
+			}
+			rows = append(rows, indent+renderMessage(text))
+		}
+	default:
+		line.WriteString(timestamp)
+		line.WriteString(source)
+		line.WriteString(level)
+		line.WriteString(renderMessage(cutCells(entry.Message, m.xOffset, m.messageWidth(index))))
+		rows = append(rows, line.String())
+	}
 
 	if isExpanded {
-		line.WriteString("\n")
-		line.WriteString(m.renderExpandedFields(entry, isSelected))
+		rows = append(rows, m.renderExpandedFields(entry, isSelected)...)
 	}
 
-	line.WriteString("\n")
-	return line.String()
+	return rows
 }
 
-// renderExpandedFields renders the fields of an expanded entry.
-func (m LogView) renderExpandedFields(entry logentry.Entry, isSelected bool) string {
-	if len(entry.Fields) == 0 {
-		return ""
+// columnsWidth returns the cells left for the columns view, after the
+// gutter and line numbers.
+func (m LogView) columnsWidth() int {
+	width := m.width - m.gutterWidth() - m.relativeWidth()
+	if m.lineNumbers {
+		width -= len(m.lineNumber(len(m.entries) - 1))
+	}
+	return max(width, minMessageWidth)
+}
+
+// cellWidths returns the width of each column. The last one takes what is
+// left of the screen when that is more than it needs.
+func (m LogView) cellWidths() []int {
+	widths := append([]int(nil), m.columnWidths...)
+	if n := len(widths); n > 0 {
+		used := 0
+		for _, w := range widths[:n-1] {
+			used += w + len(columnGap)
+		}
+		widths[n-1] = max(widths[n-1], m.columnsWidth()-used)
+	}
+	return widths
+}
+
+// tableWidth returns the width of a row of the columns view.
+func (m LogView) tableWidth() int {
+	width := 0
+	for i, w := range m.cellWidths() {
+		if i > 0 {
+			width += len(columnGap)
+		}
+		width += w
+	}
+	return width
+}
+
+// segment is a piece of a row rendered in one style.
+type segment struct {
+	text  string
+	style lipgloss.Style
+}
+
+// renderCells lays out cells in their columns, scrolled by xOffset and cut
+// at the edge of the screen.
+func (m LogView) renderCells(cells []segment, gap lipgloss.Style) string {
+	var segments []segment
+	for i, cell := range cells {
+		if i > 0 {
+			segments = append(segments, segment{text: columnGap, style: gap})
+		}
+		segments = append(segments, cell)
+	}
+
+	var b strings.Builder
+	from, to := m.xOffset, m.xOffset+m.columnsWidth()
+	pos := 0
+	for _, seg := range segments {
+		w := displaywidth.String(seg.text)
+		if pos+w > from && pos < to {
+			b.WriteString(seg.style.Render(sliceCells(seg.text, from-pos, to-pos)))
+		}
+		pos += w
+	}
+	return b.String()
+}
+
+// renderColumns renders an entry as a row of the columns view.
+func (m LogView) renderColumns(entry logentry.Entry, isSelected bool) string {
+	colors := m.theme.Colors()
+	dim := m.context[entry.ID()] && !isSelected
+	style := lipgloss.NewStyle().Foreground(colors.Foreground).Faint(dim)
+	levelStyle := m.theme.LevelStyle(entry.Level).Faint(dim)
+	if isSelected {
+		style = style.Background(colors.Highlight).Foreground(colors.Background).Bold(true)
+		levelStyle = style
+	}
+
+	widths := m.cellWidths()
+	cells := make([]segment, len(m.columns))
+	for i, name := range m.columns {
+		cellStyle := style
+		if col, _ := columns.Builtin(name); col == columns.Level {
+			cellStyle = levelStyle
+		}
+		cells[i] = segment{text: padCells(cutCells(columns.Text(entry, name), 0, widths[i]), widths[i]), style: cellStyle}
+	}
+	return m.renderCells(cells, style)
+}
+
+// renderHeader renders the header row of the columns view, marking the
+// column entries are sorted by.
+func (m LogView) renderHeader() string {
+	style := lipgloss.NewStyle().Foreground(m.theme.Colors().Info).Bold(true).Underline(true)
+	widths := m.cellWidths()
+	cells := make([]segment, len(m.columns))
+	for i, name := range m.columns {
+		if name == m.sortColumn {
+			if m.sortDesc {
+				name += " ▼"
+			} else {
+				name += " ▲"
+			}
+		}
+		cells[i] = segment{text: padCells(cutCells(name, 0, widths[i]), widths[i]), style: style}
+	}
+
+	prefix := strings.Repeat(" ", m.width-m.columnsWidth())
+	return prefix + m.renderCells(cells, lipgloss.NewStyle())
+}
+
+// lineNumber returns the line number column of the entry at index.
+func (m LogView) lineNumber(index int) string {
+	return fmt.Sprintf("%5d ", index+1)
+}
+
+// formatTimestamp returns the timestamp column of an entry.
+func formatTimestamp(entry logentry.Entry) string {
+	return fmt.Sprintf("[%s] ", entry.Timestamp.Format("15:04:05"))
+}
+
+// relativeWidth returns the width of the relative time column.
+func (m LogView) relativeWidth() int {
+	if m.since.IsZero() && !m.sincePrevious {
+		return 0
 	}
+	return relativeTimeWidth + 1
+}
+
+// relativeTime returns the relative time column of the entry at index,
+// blank when it or the entry it is measured from has no timestamp.
+func (m LogView) relativeTime(index int) string {
+	since := m.since
+	if m.sincePrevious {
+		since = time.Time{}
+		if index > 0 {
+			since = m.entries[index-1].Timestamp
+		}
+	}
+	text := ""
+	if entry := m.entries[index]; !entry.Timestamp.IsZero() && !since.IsZero() {
+		text = formatOffset(entry.Timestamp.Sub(since))
+	}
+	return fmt.Sprintf("%*s ", relativeTimeWidth, truncateText(text, relativeTimeWidth))
+}
+
+// formatOffset formats a time offset with its sign, to the millisecond
+// under a minute ("+0.120s") and to the second above ("+2m13s").
+func formatOffset(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%s%.3fs", sign, d.Seconds())
+	}
+	return sign + d.Round(time.Second).String()
+}
 
+// prefixWidth returns the width of the columns before the message of the
+// entry at index.
+func (m LogView) prefixWidth(index int) int {
+	entry := m.entries[index]
+	width := m.gutterWidth() + m.relativeWidth() + len(fmt.Sprintf("%-5s ", entry.Level.String()))
+	if m.lineNumbers {
+		width += len(m.lineNumber(index))
+	}
+	if !entry.Timestamp.IsZero() {
+		width += len(formatTimestamp(entry))
+	}
+	if m.sourceWidth > 0 {
+		width += m.sourceWidth + 1
+	}
+	return width
+}
+
+// messageWidth returns the cells left for the message of the entry at
+// index.
+func (m LogView) messageWidth(index int) int {
+	return max(m.width-m.prefixWidth(index), minMessageWidth)
+}
+
+// gutterWidth returns the width of the bookmark gutter.
+func (m LogView) gutterWidth() int {
+	if len(m.bookmarks) == 0 {
+		return 0
+	}
+	return 2
+}
+
+// renderGutter renders the bookmark gutter of an entry: the letter of its
+// mark, a dot for an anonymous bookmark, or blank.
+func (m LogView) renderGutter(entry logentry.Entry) string {
+	name, ok := m.bookmarks[entry.ID()]
+	if !ok {
+		return "  "
+	}
+	if name == "" {
+		name = "●"
+	}
+	return lipgloss.NewStyle().Foreground(m.theme.Colors().Info).Bold(true).Render(name) + " "
+}
+
+// renderExpandedFields renders the rows of the fields of an expanded
+// entry.
+func (m LogView) renderExpandedFields(entry logentry.Entry, isSelected bool) []string {
 	keyStyle := m.theme.KeyStyle()
 	valueStyle := m.theme.ValueStyle()
 	if isSelected {
@@ -318,15 +997,13 @@ func (m LogView) renderExpandedFields(entry logentry.Entry, isSelected bool) str
 		valueStyle = valueStyle.Bold(true)
 	}
 
-	var builder strings.Builder
+	rows := make([]string, 0, len(entry.Fields))
 	for key, value := range entry.Fields {
 		valueStr := formatValue(value)
-		line := fmt.Sprintf("  %s: %s", keyStyle.Render(key), valueStyle.Render(valueStr))
-		builder.WriteString(line)
-		builder.WriteString("\n")
+		rows = append(rows, fmt.Sprintf("  %s: %s", keyStyle.Render(key), valueStyle.Render(valueStr)))
 	}
 
-	return builder.String()
+	return rows
 }
 
 // renderEmpty renders the empty state.
@@ -359,6 +1036,101 @@ func formatValue(value any) string {
 	}
 }
 
+// cutCells returns the part of text that shows in width terminal cells after
+// skipping skip cells, ending in "..." when text goes on past the edge.
+func cutCells(text string, skip, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	pos, used := 0, 0
+	g := displaywidth.StringGraphemes(text)
+	for g.Next() {
+		w := g.Width()
+		if pos < skip {
+			pos += w
+			continue
+		}
+		if used+w > width {
+			return ellipsize(b.String(), width)
+		}
+		b.WriteString(g.Value())
+		used += w
+	}
+	return b.String()
+}
+
+// sliceCells returns the part of text between cells from and to. Wide runes
+// cut in half are left out.
+func sliceCells(text string, from, to int) string {
+	var b strings.Builder
+	pos := 0
+	g := displaywidth.StringGraphemes(text)
+	for g.Next() {
+		w := g.Width()
+		if pos >= from && pos+w <= to {
+			b.WriteString(g.Value())
+		}
+		pos += w
+	}
+	return b.String()
+}
+
+// padCells pads text with spaces to width cells.
+func padCells(text string, width int) string {
+	return text + strings.Repeat(" ", max(width-displaywidth.String(text), 0))
+}
+
+// ellipsize makes room for "..." at the end of text cut at width cells.
+func ellipsize(text string, width int) string {
+	if width <= 3 {
+		return text
+	}
+	return displaywidth.TruncateString(text, width-3, "") + "..."
+}
+
+// wrapCells splits text into rows of at most width terminal cells, breaking
+// at spaces where it can and at newlines in the text. Wide runes are never
+// split across rows.
+func wrapCells(text string, width int) []string {
+	if width <= 0 {
+		return []string{""}
+	}
+	var rows []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var row strings.Builder
+		used := 0
+		// lastSpace is where row last had a space, to break there instead
+		// of inside a word.
+		lastSpace := -1
+		g := displaywidth.StringGraphemes(paragraph)
+		for g.Next() {
+			w := g.Width()
+			for used > 0 && used+w > width {
+				line := row.String()
+				if lastSpace > 0 {
+					rows = append(rows, line[:lastSpace])
+					line = strings.TrimLeft(line[lastSpace:], " ")
+				} else {
+					rows = append(rows, line)
+					line = ""
+				}
+				row.Reset()
+				row.WriteString(line)
+				used = displaywidth.String(line)
+				lastSpace = -1
+			}
+			if g.Value() == " " {
+				lastSpace = row.Len()
+			}
+			row.WriteString(g.Value())
+			used += w
+		}
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
 // truncateText truncates text to fit within width.
 func truncateText(text string, width int) string {
 	if width <= 0 {
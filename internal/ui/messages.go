@@ -12,6 +12,9 @@ type LogSelectedMsg struct {
 	Index int
 }
 
+// WheelStep is how many rows a notch of the mouse wheel scrolls.
+const WheelStep = 3
+
 // ScrollUpMsg is sent to scroll up.
 type ScrollUpMsg struct {
 	Amount int
@@ -33,6 +36,16 @@ type ScrollToLineMsg struct {
 	Line int
 }
 
+// GotoSubmitMsg is sent when a ":" goto prompt input is confirmed.
+type GotoSubmitMsg struct {
+	Input string
+}
+
+// PaletteRunMsg is sent when an item of the command palette is chosen.
+type PaletteRunMsg struct {
+	ID string
+}
+
 // SearchInputMsg is sent when search input changes.
 type SearchInputMsg struct {
 	Query string
@@ -107,6 +120,11 @@ type RefreshMsg struct{}
 type FileLoadedMsg struct {
 	Path    string
 	Entries []logentry.Entry
+	// Size is the number of bytes read.
+	Size int64
+	// Files is, when Path is a directory, the number of bytes read of each
+	// file in it.
+	Files map[string]int64
 }
 
 // EntryFocusedMsg is sent when an entry is focused.
@@ -134,6 +152,15 @@ type CopyEntryMsg struct {
 	Entry logentry.Entry
 }
 
+// CopiedMsg is sent when text has been copied, or copying failed.
+type CopiedMsg struct {
+	// What describes what was copied, e.g. "entry" or "3 entries".
+	What string
+	// Method is how it was copied: to the clipboard or over OSC52.
+	Method string
+	Err    error
+}
+
 // BookmarkMsg is sent to bookmark an entry.
 type BookmarkMsg struct {
 	Index int
@@ -144,6 +171,17 @@ type GoToBookmarkMsg struct {
 	Index int
 }
 
+// DeleteBookmarkMsg is sent to remove a bookmark.
+type DeleteBookmarkMsg struct {
+	Index int
+}
+
+// GoToSpanMsg is sent to go to the first entry of a span in the trace
+// panel.
+type GoToSpanMsg struct {
+	Index int
+}
+
 // TickMsg is sent periodically for animations.
 type TickMsg struct {
 	Time time.Time
@@ -181,3 +219,17 @@ type LogFilesFoundMsg struct {
 type ShowFilePickerMsg struct {
 	Directory string
 }
+
+// ShowPresetPickerMsg is sent to open the filter preset picker.
+type ShowPresetPickerMsg struct{}
+
+// PresetSaveMsg is sent to save the current filter as a named preset.
+type PresetSaveMsg struct {
+	Name string
+}
+
+// PresetSavedMsg is sent when a preset has been written to the config file.
+type PresetSavedMsg struct {
+	Name string
+	Err  error
+}
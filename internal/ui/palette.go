@@ -0,0 +1,291 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/search"
+	"github.com/ersanisk/sieve/internal/theme"
+)
+
+// PaletteItem is a command listed in the command palette.
+type PaletteItem struct {
+	// ID names what the item runs, e.g. "action:toggle-wrap" or
+	// "theme:nord".
+	ID    string
+	Title string
+	// Key is the key binding that runs the item too, if any.
+	Key string
+}
+
+// Palette is the command palette: it lists actions, presets, themes, files
+// and recent filters, ranked by a fuzzy match of their titles against the
+// typed query.
+type Palette struct {
+	theme    theme.Theme
+	input    textinput.Model
+	items    []PaletteItem
+	matches  []int
+	selected int
+	offset   int
+	visible  bool
+	width    int
+	height   int
+}
+
+// NewPalette creates a new command palette.
+func NewPalette(theme theme.Theme) Palette {
+	ti := textinput.New()
+	ti.Placeholder = "type a command, or : to go to a line"
+	ti.Prompt = "> "
+	ti.CharLimit = 128
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Colors().Highlight).Bold(true)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(theme.Colors().Highlight)
+
+	return Palette{
+		theme:  theme,
+		input:  ti,
+		width:  80,
+		height: 24,
+	}
+}
+
+// Show shows the palette listing items, with an empty query.
+func (p *Palette) Show(items []PaletteItem) {
+	p.items = items
+	p.visible = true
+	p.input.Reset()
+	p.input.Focus()
+	p.match()
+}
+
+// Hide hides the palette.
+func (p *Palette) Hide() {
+	p.visible = false
+	p.input.Blur()
+}
+
+// IsVisible returns whether the palette is visible.
+func (p Palette) IsVisible() bool {
+	return p.visible
+}
+
+// Value returns the typed query.
+func (p Palette) Value() string {
+	return p.input.Value()
+}
+
+// SetValue sets the typed query.
+func (p *Palette) SetValue(value string) {
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	p.match()
+}
+
+// Matches returns the items matching the query, best first.
+func (p Palette) Matches() []PaletteItem {
+	items := make([]PaletteItem, len(p.matches))
+	for i, index := range p.matches {
+		items[i] = p.items[index]
+	}
+	return items
+}
+
+// SetSize sets the dimensions of the palette.
+func (p *Palette) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetTheme sets the theme.
+func (p *Palette) SetTheme(theme theme.Theme) {
+	p.theme = theme
+}
+
+// match ranks the items against the query. Without a query every item is
+// listed in order; with one, items score as search.FuzzyMatch scores
+// entries and ties keep their order.
+func (p *Palette) match() {
+	p.selected = 0
+	p.offset = 0
+	p.matches = nil
+
+	query := strings.TrimSpace(p.input.Value())
+	scores := make(map[int]float64)
+	for i, item := range p.items {
+		if query == "" {
+			p.matches = append(p.matches, i)
+			continue
+		}
+		if score := search.FuzzyScore(item.Title, query); score > 0 {
+			scores[i] = score
+			p.matches = append(p.matches, i)
+		}
+	}
+	sort.SliceStable(p.matches, func(a, b int) bool {
+		return scores[p.matches[a]] > scores[p.matches[b]]
+	})
+}
+
+// Update handles key input. Enter sends a PaletteRunMsg for the selected
+// item.
+func (p Palette) Update(msg tea.Msg) (Palette, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		p.Hide()
+		return p, nil
+	case "up", "ctrl+p":
+		if p.selected > 0 {
+			p.selected--
+		}
+		return p, nil
+	case "down", "ctrl+n":
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+		return p, nil
+	case "enter":
+		if len(p.matches) == 0 {
+			return p, nil
+		}
+		id := p.items[p.matches[p.selected]].ID
+		p.Hide()
+		return p, func() tea.Msg {
+			return PaletteRunMsg{ID: id}
+		}
+	}
+
+	query := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != query {
+		p.match()
+	}
+	return p, cmd
+}
+
+// View renders the palette.
+func (p *Palette) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	colors := p.theme.Colors()
+
+	containerWidth := p.width - 8
+	if containerWidth < 40 {
+		containerWidth = 40
+	}
+	if containerWidth > 90 {
+		containerWidth = 90
+	}
+	contentWidth := containerWidth - 6
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Bold(true)
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Faint(true)
+	footerStyle := lipgloss.NewStyle().
+		Foreground(colors.Foreground).
+		Faint(true)
+
+	title := "Command Palette"
+	if len(p.matches) > 0 {
+		title = fmt.Sprintf("Command Palette (%d/%d)", p.selected+1, len(p.matches))
+	}
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render(title))
+	content.WriteString("\n")
+	content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+	content.WriteString("\n\n")
+	content.WriteString(p.input.View())
+	content.WriteString("\n\n")
+	content.WriteString(p.renderList(contentWidth))
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("↑/↓ navigate  enter run  esc close"))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Padding(1, 2).
+		Width(containerWidth)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content.String()),
+	)
+}
+
+// renderList renders the visible slice of matching items, with their keys
+// aligned to the right.
+func (p *Palette) renderList(width int) string {
+	colors := p.theme.Colors()
+
+	if len(p.matches) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(colors.Warn).
+			Italic(true)
+		return emptyStyle.Render("No matching commands") + "\n"
+	}
+
+	visibleHeight := p.height - 14
+	if visibleHeight < 3 {
+		visibleHeight = 3
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+visibleHeight {
+		p.offset = p.selected - visibleHeight + 1
+	}
+	end := p.offset + visibleHeight
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+
+	keyStyle := p.theme.KeyStyle()
+	var b strings.Builder
+	for i := p.offset; i < end; i++ {
+		item := p.items[p.matches[i]]
+		key := truncateText(item.Key, 16)
+		title := truncateText(item.Title, width-2-len([]rune(key))-2)
+		gap := max(width-2-len([]rune(title))-len([]rune(key)), 1)
+
+		itemStyle := lipgloss.NewStyle().
+			Foreground(colors.Foreground).
+			Width(width).
+			Padding(0, 1)
+		line := title + strings.Repeat(" ", gap) + keyStyle.Render(key)
+		if i == p.selected {
+			itemStyle = itemStyle.
+				Foreground(colors.Background).
+				Background(colors.Info).
+				Bold(true)
+			line = title + strings.Repeat(" ", gap) + key
+		}
+		b.WriteString(itemStyle.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,300 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/theme"
+	"github.com/ersanisk/sieve/pkg/logentry"
+)
+
+// PresetItem is a filter preset shown in the preset picker.
+type PresetItem struct {
+	Name        string
+	Description string
+	Expression  string
+	Level       logentry.Level
+}
+
+// PresetPicker lists filter presets and prompts for a name when saving one.
+type PresetPicker struct {
+	theme     theme.Theme
+	presets   []PresetItem
+	selected  int
+	offset    int
+	visible   bool
+	saveMode  bool
+	nameInput textinput.Model
+	width     int
+	height    int
+}
+
+// NewPresetPicker creates a new preset picker.
+func NewPresetPicker(theme theme.Theme) PresetPicker {
+	ti := textinput.New()
+	ti.Placeholder = "preset name"
+	ti.Prompt = "Name: "
+	ti.CharLimit = 64
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(theme.Colors().Highlight)
+
+	return PresetPicker{
+		theme:     theme,
+		nameInput: ti,
+		width:     80,
+		height:    24,
+	}
+}
+
+// SetPresets sets the presets to list.
+func (p *PresetPicker) SetPresets(presets []PresetItem) {
+	p.presets = presets
+	if p.selected >= len(presets) {
+		p.selected = 0
+		p.offset = 0
+	}
+}
+
+// Show shows the picker in selection mode.
+func (p *PresetPicker) Show() {
+	p.visible = true
+	p.saveMode = false
+	p.nameInput.Blur()
+}
+
+// ShowSave shows the picker prompting for a name to save the current filter under.
+func (p *PresetPicker) ShowSave() {
+	p.visible = true
+	p.saveMode = true
+	p.nameInput.Reset()
+	p.nameInput.Focus()
+}
+
+// Hide hides the picker.
+func (p *PresetPicker) Hide() {
+	p.visible = false
+	p.saveMode = false
+	p.nameInput.Blur()
+}
+
+// IsVisible returns whether the picker is visible.
+func (p PresetPicker) IsVisible() bool {
+	return p.visible
+}
+
+// IsSaving returns true if the picker is prompting for a preset name.
+func (p PresetPicker) IsSaving() bool {
+	return p.saveMode
+}
+
+// SetSize sets the dimensions of the picker.
+func (p *PresetPicker) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetTheme sets the theme.
+func (p *PresetPicker) SetTheme(theme theme.Theme) {
+	p.theme = theme
+}
+
+// Update handles key input.
+func (p PresetPicker) Update(msg tea.Msg) (PresetPicker, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	if p.saveMode {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			p.Hide()
+			return p, nil
+		case tea.KeyEnter:
+			name := strings.TrimSpace(p.nameInput.Value())
+			if name == "" {
+				return p, nil
+			}
+			p.Hide()
+			return p, func() tea.Msg {
+				return PresetSaveMsg{Name: name}
+			}
+		}
+		var cmd tea.Cmd
+		p.nameInput, cmd = p.nameInput.Update(msg)
+		return p, cmd
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		p.Hide()
+	case "up", "k":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j":
+		if p.selected < len(p.presets)-1 {
+			p.selected++
+		}
+	case "g":
+		p.selected = 0
+	case "G":
+		if len(p.presets) > 0 {
+			p.selected = len(p.presets) - 1
+		}
+	case "s":
+		p.ShowSave()
+	case "enter":
+		if len(p.presets) == 0 {
+			return p, nil
+		}
+		name := p.presets[p.selected].Name
+		p.Hide()
+		return p, func() tea.Msg {
+			return FilterSetPresetMsg{Preset: name}
+		}
+	}
+	return p, nil
+}
+
+// View renders the picker.
+func (p *PresetPicker) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	colors := p.theme.Colors()
+
+	containerWidth := p.width - 8
+	if containerWidth < 40 {
+		containerWidth = 40
+	}
+	if containerWidth > 90 {
+		containerWidth = 90
+	}
+	contentWidth := containerWidth - 6
+
+	var content strings.Builder
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Bold(true)
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Faint(true)
+	footerStyle := lipgloss.NewStyle().
+		Foreground(colors.Foreground).
+		Faint(true)
+
+	if p.saveMode {
+		content.WriteString(headerStyle.Render("Save Current Filter as Preset"))
+		content.WriteString("\n")
+		content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+		content.WriteString("\n\n")
+		content.WriteString(p.nameInput.View())
+		content.WriteString("\n\n")
+		content.WriteString(footerStyle.Render("enter save  esc cancel"))
+	} else {
+		title := "Filter Presets"
+		if len(p.presets) > 0 {
+			title = fmt.Sprintf("Filter Presets (%d/%d)", p.selected+1, len(p.presets))
+		}
+		content.WriteString(headerStyle.Render(title))
+		content.WriteString("\n")
+		content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+		content.WriteString("\n")
+		content.WriteString(p.renderList(contentWidth))
+		content.WriteString("\n")
+		content.WriteString(footerStyle.Render("j/k navigate  enter apply  s save current  esc close"))
+	}
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Padding(1, 2).
+		Width(containerWidth)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content.String()),
+	)
+}
+
+// renderList renders the visible slice of presets.
+func (p *PresetPicker) renderList(width int) string {
+	colors := p.theme.Colors()
+
+	if len(p.presets) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(colors.Warn).
+			Italic(true).
+			Padding(1, 0)
+		return emptyStyle.Render("No presets defined") + "\n"
+	}
+
+	visibleHeight := p.height - 12
+	if visibleHeight < 3 {
+		visibleHeight = 3
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+visibleHeight {
+		p.offset = p.selected - visibleHeight + 1
+	}
+	end := p.offset + visibleHeight
+	if end > len(p.presets) {
+		end = len(p.presets)
+	}
+
+	var b strings.Builder
+	for i := p.offset; i < end; i++ {
+		preset := p.presets[i]
+		line := preset.Name
+		if summary := presetSummary(preset); summary != "" {
+			line += "  " + summary
+		}
+		line = truncateText(line, width-2)
+
+		itemStyle := lipgloss.NewStyle().
+			Foreground(colors.Foreground).
+			Width(width).
+			Padding(0, 1)
+		if i == p.selected {
+			itemStyle = itemStyle.
+				Foreground(colors.Background).
+				Background(colors.Info).
+				Bold(true)
+		}
+		b.WriteString(itemStyle.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// presetSummary describes what a preset filters on.
+func presetSummary(preset PresetItem) string {
+	var parts []string
+	if preset.Level != logentry.Unknown {
+		parts = append(parts, "level>="+strings.ToLower(preset.Level.String()))
+	}
+	if preset.Expression != "" {
+		parts = append(parts, preset.Expression)
+	}
+	if len(parts) == 0 {
+		return preset.Description
+	}
+	return strings.Join(parts, " ")
+}
@@ -7,9 +7,14 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/search"
 	"github.com/ersanisk/sieve/internal/theme"
 )
 
+// modeBadgeWidth is the room reserved for the search mode badge.
+const modeBadgeWidth = 16
+
 // SearchBar handles search input.
 type SearchBar struct {
 	textInput textinput.Model
@@ -17,14 +22,19 @@ type SearchBar struct {
 	focused   bool
 	width     int
 	theme     theme.Theme
+	history   inputHistory
+	// fieldStats tells a field query from text that happens to hold a colon.
+	fieldStats *filter.FieldStats
 }
 
 // NewSearchBar creates a new SearchBar.
 func NewSearchBar(theme theme.Theme) SearchBar {
 	ti := textinput.New()
-	ti.Placeholder = "Search..."
+	ti.Placeholder = "Search... (re: regex, f: fuzzy, field:value, ctrl+t switch mode)"
 	ti.Prompt = "/ "
 	ti.CharLimit = 200
+	ti.ShowSuggestions = true
+	ti.KeyMap = historyKeyMap()
 	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
 	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
 	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(theme.Colors().Foreground)
@@ -44,6 +54,7 @@ func (m *SearchBar) Show() {
 	m.visible = true
 	m.focused = true
 	m.textInput.Focus()
+	m.history.reset()
 	m.textInput.Reset()
 }
 
@@ -51,6 +62,7 @@ func (m *SearchBar) Show() {
 func (m *SearchBar) Hide() {
 	m.visible = false
 	m.focused = false
+	m.history.cancel(&m.textInput)
 	m.textInput.Blur()
 }
 
@@ -79,12 +91,24 @@ func (m *SearchBar) Clear() {
 	m.textInput.Reset()
 }
 
+// SetHistory sets previously submitted searches for recall and autocompletion,
+// oldest first.
+func (m *SearchBar) SetHistory(entries []string) {
+	m.history.setEntries(entries)
+	m.textInput.SetSuggestions(m.history.suggestions())
+}
+
+// IsSearchingHistory returns true while a reverse history search is active.
+func (m *SearchBar) IsSearchingHistory() bool {
+	return m.history.searching
+}
+
 // SetSize sets the dimensions of the search bar.
 func (m *SearchBar) SetSize(width, height int) {
 	m.width = width
-	// Reserve space for prompt (2 chars: "/ ") and some padding
-	if width > 4 {
-		m.textInput.Width = width - 4
+	// Reserve space for prompt (2 chars: "/ "), the mode badge and some padding
+	if width > 4+modeBadgeWidth {
+		m.textInput.Width = width - 4 - modeBadgeWidth
 	}
 }
 
@@ -110,6 +134,13 @@ func (m SearchBar) Update(msg tea.Msg) (SearchBar, tea.Cmd) {
 			// ESC tuşunu consume etme, parent'a geçmesine izin ver
 			return m, nil
 		}
+		if m.history.handleKey(&m.textInput, keyMsg) {
+			return m, nil
+		}
+		if keyMsg.Type == tea.KeyCtrlT {
+			m.cycleMode()
+			return m, nil
+		}
 	}
 
 	var cmd tea.Cmd
@@ -122,7 +153,33 @@ func (m SearchBar) View() string {
 	if !m.visible {
 		return ""
 	}
-	return m.textInput.View()
+
+	badgeStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Colors().Background).
+		Background(m.theme.Colors().Info).
+		Bold(true).
+		Padding(0, 1)
+	label := truncateText(m.Query().Label(), modeBadgeWidth-3)
+	return badgeStyle.Render(label) + " " + m.textInput.View()
+}
+
+// Query returns the parsed query with its search mode.
+func (m *SearchBar) Query() search.Query {
+	return search.ParseQuery(m.textInput.Value(), m.fieldStats.Has)
+}
+
+// SetFieldStats sets the fields of the loaded entries, which decide whether
+// "field:value" searches a field.
+func (m *SearchBar) SetFieldStats(stats *filter.FieldStats) {
+	m.fieldStats = stats
+}
+
+// cycleMode switches the query to the next search mode, keeping its text.
+func (m *SearchBar) cycleMode() {
+	q := m.Query()
+	next := search.Query{Mode: q.Mode.Next(), Text: q.Text}
+	m.textInput.SetValue(next.String())
+	m.textInput.CursorEnd()
 }
 
 // Focus sets focus to the search bar.
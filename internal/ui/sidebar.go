@@ -4,28 +4,30 @@ import (
 	"fmt"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/ersanisk/sieve/internal/theme"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
 
-// Sidebar displays detailed information about a selected entry.
+// Sidebar is the field inspector shown next to the log view: the selected
+// entry's level and timestamp above a tree of its fields.
 type Sidebar struct {
-	visible  bool
-	width    int
-	height   int
-	entry    logentry.Entry
-	theme    theme.Theme
-	expanded map[string]bool
+	visible bool
+	width   int
+	height  int
+	entry   logentry.Entry
+	theme   theme.Theme
+	tree    TreeView
 }
 
 // NewSidebar creates a new Sidebar.
 func NewSidebar(theme theme.Theme) Sidebar {
 	return Sidebar{
-		visible:  false,
-		theme:    theme,
-		expanded: make(map[string]bool),
+		visible: false,
+		theme:   theme,
+		tree:    NewTreeView(theme),
 	}
 }
 
@@ -34,9 +36,10 @@ func (m *Sidebar) Show() {
 	m.visible = true
 }
 
-// Hide hides the sidebar.
+// Hide hides the sidebar and gives up focus.
 func (m *Sidebar) Hide() {
 	m.visible = false
+	m.tree.Blur()
 }
 
 // IsVisible returns true if the sidebar is visible.
@@ -44,10 +47,29 @@ func (m *Sidebar) IsVisible() bool {
 	return m.visible
 }
 
-// SetEntry sets the entry to display.
+// Focus gives the field tree keyboard focus.
+func (m *Sidebar) Focus() {
+	m.tree.Focus()
+}
+
+// Blur gives keyboard focus back to the log view.
+func (m *Sidebar) Blur() {
+	m.tree.Blur()
+}
+
+// IsFocused returns true if the field tree has keyboard focus.
+func (m *Sidebar) IsFocused() bool {
+	return m.tree.IsFocused()
+}
+
+// SetEntry sets the entry to display. Fields expanded in the previous entry
+// stay expanded when the new one has them too.
 func (m *Sidebar) SetEntry(entry logentry.Entry) {
 	m.entry = entry
-	m.expanded = make(map[string]bool)
+	expanded, selected := m.tree.expanded, m.tree.selected
+	m.tree.SetData(entry.Fields)
+	m.tree.expanded = expanded
+	m.tree.SetSelected(selected)
 }
 
 // GetEntry returns the current entry.
@@ -55,10 +77,32 @@ func (m *Sidebar) GetEntry() logentry.Entry {
 	return m.entry
 }
 
-// SetSize sets the dimensions of the sidebar.
+// SelectedField returns the selected field node, or nil.
+func (m *Sidebar) SelectedField() *TreeNode {
+	return m.tree.GetSelected()
+}
+
+// Scroll moves the field selection by delta rows.
+func (m *Sidebar) Scroll(delta int) {
+	m.tree.SetSelected(m.tree.selected + delta)
+}
+
+// SelectAt selects the field shown on row y of the sidebar and returns it,
+// or returns nil when the row shows none.
+func (m *Sidebar) SelectAt(y int) *TreeNode {
+	if m.entry.Raw == "" {
+		return nil
+	}
+	// Border, header and the blank line below it.
+	return m.tree.SelectAt(y - 3)
+}
+
+// SetSize sets the dimensions of the sidebar, border included.
 func (m *Sidebar) SetSize(width, height int) {
 	m.width = width
 	m.height = height
+	// Border, header and the blank line below it.
+	m.tree.SetSize(max(width-2, 0), max(height-4, 1))
 }
 
 // GetSize returns the dimensions of the sidebar.
@@ -69,15 +113,17 @@ func (m *Sidebar) GetSize() (int, int) {
 // SetTheme sets the theme.
 func (m *Sidebar) SetTheme(theme theme.Theme) {
 	m.theme = theme
+	m.tree.SetTheme(theme)
 }
 
-// ToggleField toggles expansion of a field.
+// ToggleField toggles expansion of a top-level field.
 func (m *Sidebar) ToggleField(key string) {
-	if _, ok := m.expanded[key]; ok {
-		delete(m.expanded, key)
-	} else {
-		m.expanded[key] = true
-	}
+	m.tree.ToggleNode("." + key)
+}
+
+// Update handles key input while the field tree is focused.
+func (m *Sidebar) Update(msg tea.Msg) tea.Cmd {
+	return m.tree.Update(msg)
 }
 
 // View renders the sidebar.
@@ -86,11 +132,16 @@ func (m Sidebar) View() string {
 		return ""
 	}
 
+	borderColor := m.theme.Colors().Border
+	if m.tree.IsFocused() {
+		borderColor = m.theme.Colors().Info
+	}
 	containerStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(m.theme.Colors().Border).
-		Width(m.width).
-		Height(m.height)
+		BorderForeground(borderColor).
+		Width(max(m.width-2, 0)).
+		Height(max(m.height-2, 0)).
+		MaxHeight(m.height)
 
 	content := m.renderContent()
 	return containerStyle.Render(content)
@@ -101,15 +152,14 @@ func (m Sidebar) renderContent() string {
 	if m.entry.Raw == "" {
 		style := lipgloss.NewStyle().
 			Foreground(m.theme.Colors().Foreground).
-			Align(lipgloss.Center).
-			Height(m.height - 2)
+			Italic(true)
 		return style.Render("No entry selected")
 	}
 
 	var builder strings.Builder
 	builder.WriteString(m.renderHeader())
 	builder.WriteString("\n\n")
-	builder.WriteString(m.renderFields())
+	builder.WriteString(m.tree.renderContent())
 
 	return builder.String()
 }
@@ -127,100 +177,3 @@ func (m Sidebar) renderHeader() string {
 
 	return level + timestamp
 }
-
-// renderFields renders the entry fields.
-func (m Sidebar) renderFields() string {
-	if len(m.entry.Fields) == 0 {
-		style := lipgloss.NewStyle().
-			Foreground(m.theme.Colors().Foreground).
-			Italic(true)
-		return style.Render("No fields")
-	}
-
-	var builder strings.Builder
-	for key, value := range m.entry.Fields {
-		builder.WriteString(m.renderField(key, value, 0))
-		builder.WriteString("\n")
-	}
-
-	return builder.String()
-}
-
-// renderField renders a single field.
-func (m Sidebar) renderField(key string, value any, indent int) string {
-	keyStyle := m.theme.KeyStyle()
-	keyText := keyStyle.Render(fmt.Sprintf("%*s%s", indent, "", key))
-
-	valueText := m.renderValue(value, indent+2)
-	return fmt.Sprintf("%s: %s", keyText, valueText)
-}
-
-// renderValue renders a field value.
-func (m Sidebar) renderValue(value any, indent int) string {
-	valueStyle := m.theme.ValueStyle()
-
-	switch v := value.(type) {
-	case string:
-		return valueStyle.Render(fmt.Sprintf("\"%s\"", v))
-	case float64:
-		return valueStyle.Render(fmt.Sprintf("%.2f", v))
-	case int:
-		return valueStyle.Render(fmt.Sprintf("%d", v))
-	case bool:
-		return valueStyle.Render(fmt.Sprintf("%t", v))
-	case map[string]any:
-		return m.renderMap(v, indent)
-	case []any:
-		return m.renderArray(v, indent)
-	default:
-		return valueStyle.Render(fmt.Sprintf("%v", v))
-	}
-}
-
-// renderMap renders a map value.
-func (m Sidebar) renderMap(mmap map[string]any, indent int) string {
-	if len(mmap) == 0 {
-		return "{}"
-	}
-
-	var builder strings.Builder
-	builder.WriteString("{")
-
-	i := 0
-	for key, val := range mmap {
-		if i > 0 {
-			builder.WriteString(", ")
-		}
-		builder.WriteString("\n")
-		builder.WriteString(m.renderField(key, val, indent+2))
-		i++
-	}
-
-	builder.WriteString("\n")
-	for j := 0; j < indent; j++ {
-		builder.WriteString(" ")
-	}
-	builder.WriteString("}")
-
-	return builder.String()
-}
-
-// renderArray renders an array value.
-func (m Sidebar) renderArray(arr []any, indent int) string {
-	if len(arr) == 0 {
-		return "[]"
-	}
-
-	var builder strings.Builder
-	builder.WriteString("[")
-
-	for i, val := range arr {
-		if i > 0 {
-			builder.WriteString(", ")
-		}
-		builder.WriteString(m.renderValue(val, indent+2))
-	}
-
-	builder.WriteString("]")
-	return builder.String()
-}
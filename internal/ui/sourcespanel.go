@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/theme"
+)
+
+// SourceItem is a followed file shown in the sources panel.
+type SourceItem struct {
+	Path    string
+	Status  string
+	Lines   int
+	Dropped int
+	Error   string
+}
+
+// SourcesPanel lists the followed files with their tail status.
+type SourcesPanel struct {
+	theme   theme.Theme
+	sources []SourceItem
+	dropped int
+	offset  int
+	visible bool
+	width   int
+	height  int
+}
+
+// NewSourcesPanel creates a new sources panel.
+func NewSourcesPanel(theme theme.Theme) SourcesPanel {
+	return SourcesPanel{
+		theme:  theme,
+		width:  80,
+		height: 24,
+	}
+}
+
+// SetSources sets the files to list and the total number of dropped lines.
+func (p *SourcesPanel) SetSources(sources []SourceItem, dropped int) {
+	p.sources = sources
+	p.dropped = dropped
+	if p.offset >= len(sources) {
+		p.offset = 0
+	}
+}
+
+// Show shows the panel.
+func (p *SourcesPanel) Show() {
+	p.visible = true
+	p.offset = 0
+}
+
+// Hide hides the panel.
+func (p *SourcesPanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible returns whether the panel is visible.
+func (p SourcesPanel) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the dimensions of the panel.
+func (p *SourcesPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetTheme sets the theme.
+func (p *SourcesPanel) SetTheme(theme theme.Theme) {
+	p.theme = theme
+}
+
+// Update handles key input.
+func (p SourcesPanel) Update(msg tea.Msg) (SourcesPanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "S":
+		p.Hide()
+	case "up", "k":
+		if p.offset > 0 {
+			p.offset--
+		}
+	case "down", "j":
+		if p.offset < len(p.sources)-p.visibleHeight() {
+			p.offset++
+		}
+	}
+	return p, nil
+}
+
+// visibleHeight returns how many files fit in the panel.
+func (p SourcesPanel) visibleHeight() int {
+	return max(p.height-12, 3)
+}
+
+// View renders the panel.
+func (p *SourcesPanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	colors := p.theme.Colors()
+
+	containerWidth := p.width - 8
+	if containerWidth < 40 {
+		containerWidth = 40
+	}
+	if containerWidth > 90 {
+		containerWidth = 90
+	}
+	contentWidth := containerWidth - 6
+
+	var content strings.Builder
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Bold(true)
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Faint(true)
+	footerStyle := lipgloss.NewStyle().
+		Foreground(colors.Foreground).
+		Faint(true)
+
+	title := fmt.Sprintf("Sources (%d)", len(p.sources))
+	if p.dropped > 0 {
+		title += fmt.Sprintf("  ⚠ %d lines dropped", p.dropped)
+	}
+	content.WriteString(headerStyle.Render(title))
+	content.WriteString("\n")
+	content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+	content.WriteString("\n")
+	content.WriteString(p.renderList(contentWidth))
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("j/k scroll  esc close"))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Padding(1, 2).
+		Width(containerWidth)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content.String()),
+	)
+}
+
+// renderList renders the visible slice of files.
+func (p *SourcesPanel) renderList(width int) string {
+	colors := p.theme.Colors()
+
+	if len(p.sources) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(colors.Warn).
+			Italic(true).
+			Padding(1, 0)
+		return emptyStyle.Render("Not following any files") + "\n"
+	}
+
+	end := min(p.offset+p.visibleHeight(), len(p.sources))
+
+	var b strings.Builder
+	for _, source := range p.sources[p.offset:end] {
+		line := fmt.Sprintf("%-8s %s  %d lines", source.Status, filepath.Base(source.Path), source.Lines)
+		if source.Dropped > 0 {
+			line += fmt.Sprintf(", %d dropped", source.Dropped)
+		}
+		if source.Error != "" {
+			line += "  " + source.Error
+		}
+		line = truncateText(line, width-2)
+
+		itemStyle := lipgloss.NewStyle().
+			Foreground(colors.Foreground).
+			Width(width).
+			Padding(0, 1)
+		switch source.Status {
+		case "error":
+			itemStyle = itemStyle.Foreground(colors.Error)
+		case "stopped", "paused":
+			itemStyle = itemStyle.Foreground(colors.Warn)
+		}
+		b.WriteString(itemStyle.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -2,12 +2,20 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ersanisk/sieve/internal/theme"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
 
+// ExcludeCount is an exclusion rule and how many entries it currently suppresses.
+type ExcludeCount struct {
+	Rule  string
+	Count int
+}
+
 // StatusBar displays status information at the bottom of the screen.
 type StatusBar struct {
 	filePath    string
@@ -16,12 +24,24 @@ type StatusBar struct {
 	filter      string
 	mode        string
 	following   bool
+	newEntries  int
+	dropped     int
 	levelFilter logentry.Level
+	since       time.Time
+	until       time.Time
+	sources     []string
+	excludes    []ExcludeCount
+	revealed    bool
+	progress    []Progress
 	info        string
 	errorMsg    string
 	width       int
 	height      int
 	theme       theme.Theme
+	// context is how many entries are shown around each match, or 0;
+	// suspended is set while the filter is dropped for a moment
+	context   int
+	suspended bool
 }
 
 // NewStatusBar creates a new StatusBar.
@@ -64,11 +84,75 @@ func (m *StatusBar) SetFollowing(following bool) {
 	m.following = following
 }
 
+// SetNewEntries sets how many entries arrived while following was paused by
+// scrolling up. Zero hides the count.
+func (m *StatusBar) SetNewEntries(count int) {
+	m.newEntries = count
+}
+
+// SetDropped sets how many followed lines were dropped because they arrived
+// faster than they could be shown. Zero hides the count.
+func (m *StatusBar) SetDropped(count int) {
+	m.dropped = count
+}
+
 // SetLevelFilter sets the level filter.
 func (m *StatusBar) SetLevelFilter(level logentry.Level) {
 	m.levelFilter = level
 }
 
+// SetTimeRange sets the time window entries are limited to. Zero times are
+// open ends.
+func (m *StatusBar) SetTimeRange(since, until time.Time) {
+	m.since = since
+	m.until = until
+}
+
+// SetSources sets the selected sources. An empty list means all sources.
+func (m *StatusBar) SetSources(sources []string) {
+	m.sources = sources
+}
+
+// SetExcludes sets the exclusion rules with their suppressed counts, and
+// whether suppressed entries are currently revealed.
+func (m *StatusBar) SetExcludes(excludes []ExcludeCount, revealed bool) {
+	m.excludes = excludes
+	m.revealed = revealed
+}
+
+// SetContext sets how many entries the context view shows around each
+// match. Zero hides it.
+func (m *StatusBar) SetContext(lines int) {
+	m.context = lines
+}
+
+// SetSuspended sets whether the filter is suspended.
+func (m *StatusBar) SetSuspended(suspended bool) {
+	m.suspended = suspended
+}
+
+// Progress is how far a background job, such as a search, has got.
+type Progress struct {
+	Label   string
+	Scanned int
+	Total   int
+}
+
+// SetProgress shows the progress of a background job, replacing any earlier
+// progress with the same label. A total of zero removes it.
+func (m *StatusBar) SetProgress(label string, scanned, total int) {
+	var kept []Progress
+	for _, p := range m.progress {
+		if p.Label != label {
+			kept = append(kept, p)
+		}
+	}
+	m.progress = kept
+	if total > 0 {
+		m.progress = append(m.progress, Progress{Label: label, Scanned: scanned, Total: total})
+	}
+}
+
 // SetInfo sets the info message.
 func (m *StatusBar) SetInfo(info string) {
 	m.info = info
@@ -108,14 +192,19 @@ func (m StatusBar) View() string {
 func (m StatusBar) renderContent() string {
 	var parts []string
 
+	// Background jobs stay visible next to info and error messages.
+	if progressPart := m.renderProgressPart(); progressPart != "" {
+		parts = append(parts, progressPart)
+	}
+
 	if m.errorMsg != "" {
 		errorStyle := m.theme.ErrorStyle()
-		return errorStyle.Render(fmt.Sprintf("❌ %s", m.errorMsg))
+		return strings.Join(append(parts, errorStyle.Render(fmt.Sprintf("❌ %s", m.errorMsg))), " │ ")
 	}
 
 	if m.info != "" {
 		infoStyle := m.theme.InfoStyle()
-		return infoStyle.Render(fmt.Sprintf("ℹ️ %s", m.info))
+		return strings.Join(append(parts, infoStyle.Render(fmt.Sprintf("ℹ️ %s", m.info))), " │ ")
 	}
 
 	filePart := m.renderFilePart()
@@ -141,6 +230,16 @@ func (m StatusBar) renderContent() string {
 	return strings.Join(parts, " │ ")
 }
 
+// renderProgressPart renders the progress of background jobs, e.g.
+// "⏳ filter: scanned 12000 of 50000".
+func (m StatusBar) renderProgressPart() string {
+	parts := make([]string, 0, len(m.progress))
+	for _, p := range m.progress {
+		parts = append(parts, fmt.Sprintf("⏳ %s: scanned %d of %d", p.Label, p.Scanned, p.Total))
+	}
+	return strings.Join(parts, " ")
+}
+
 // renderFilePart renders the file information.
 func (m StatusBar) renderFilePart() string {
 	if m.filePath == "" {
@@ -176,16 +275,92 @@ func (m StatusBar) renderFilterPart() string {
 	}
 
 	if m.levelFilter != logentry.Unknown {
-		filterInfo = append(filterInfo, fmt.Sprintf("🏷️ %s", m.levelFilter.String()))
+		filterInfo = append(filterInfo, fmt.Sprintf("🏷️ ≥%s", m.levelFilter.String()))
+	}
+
+	if timePart := m.renderTimePart(); timePart != "" {
+		filterInfo = append(filterInfo, timePart)
+	}
+
+	if len(m.sources) > 0 {
+		names := make([]string, len(m.sources))
+		for i, source := range m.sources {
+			names[i] = filepath.Base(source)
+		}
+		filterInfo = append(filterInfo, fmt.Sprintf("📂 %s", strings.Join(names, ",")))
+	}
+
+	if m.suspended {
+		filterInfo = append(filterInfo, "⏸ filter off")
+	} else if m.context > 0 {
+		filterInfo = append(filterInfo, fmt.Sprintf("↕ ±%d", m.context))
+	}
+
+	if excludePart := m.renderExcludePart(); excludePart != "" {
+		filterInfo = append(filterInfo, excludePart)
 	}
 
 	if m.following {
 		filterInfo = append(filterInfo, "👁️ FOLLOW")
 	}
 
+	if m.newEntries > 0 {
+		filterInfo = append(filterInfo, fmt.Sprintf("▼ %d new entries", m.newEntries))
+	}
+
+	if m.dropped > 0 {
+		filterInfo = append(filterInfo, fmt.Sprintf("⚠ %d dropped", m.dropped))
+	}
+
 	return strings.Join(filterInfo, " ")
 }
 
+// renderTimePart renders the time window.
+func (m StatusBar) renderTimePart() string {
+	if m.since.IsZero() && m.until.IsZero() {
+		return ""
+	}
+	since, until := "…", "…"
+	if !m.since.IsZero() {
+		since = formatBound(m.since)
+	}
+	if !m.until.IsZero() {
+		until = formatBound(m.until)
+	}
+	return fmt.Sprintf("🕒 %s→%s", since, until)
+}
+
+// formatBound formats a time window bound, leaving out the date for today.
+func formatBound(t time.Time) string {
+	y, mo, d := t.Date()
+	if ny, nmo, nd := time.Now().Date(); y == ny && mo == nmo && d == nd {
+		return t.Format(time.TimeOnly)
+	}
+	return t.Format("01-02 15:04")
+}
+
+// renderExcludePart renders the suppressed count of each exclusion rule.
+func (m StatusBar) renderExcludePart() string {
+	if len(m.excludes) == 0 {
+		return ""
+	}
+
+	counts := make([]string, len(m.excludes))
+	for i, ex := range m.excludes {
+		rule := ex.Rule
+		if len(rule) > 12 {
+			rule = rule[:12] + "..."
+		}
+		counts[i] = fmt.Sprintf("%s:%d", rule, ex.Count)
+	}
+
+	icon := "🚫"
+	if m.revealed {
+		icon = "👻"
+	}
+	return fmt.Sprintf("%s %s", icon, strings.Join(counts, " "))
+}
+
 // renderModePart renders the mode information.
 func (m StatusBar) renderModePart() string {
 	if m.mode == "" {
@@ -0,0 +1,242 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/ersanisk/sieve/internal/theme"
+)
+
+// TraceSpanItem is a span shown in the trace panel.
+type TraceSpanItem struct {
+	Name  string
+	Depth int
+	// Start is when the span began, from the start of the trace.
+	Start    time.Duration
+	Duration time.Duration
+	Entries  int
+}
+
+// TracePanel shows the spans of a trace as an indented call tree.
+type TracePanel struct {
+	theme    theme.Theme
+	title    string
+	spans    []TraceSpanItem
+	selected int
+	offset   int
+	visible  bool
+	width    int
+	height   int
+}
+
+// NewTracePanel creates a new trace panel.
+func NewTracePanel(theme theme.Theme) TracePanel {
+	return TracePanel{
+		theme:  theme,
+		width:  80,
+		height: 24,
+	}
+}
+
+// SetSpans sets the trace to show, named by title, and selects its root.
+func (p *TracePanel) SetSpans(title string, spans []TraceSpanItem) {
+	p.title = title
+	p.spans = spans
+	p.selected = 0
+	p.offset = 0
+}
+
+// Show shows the panel.
+func (p *TracePanel) Show() {
+	p.visible = true
+}
+
+// Hide hides the panel.
+func (p *TracePanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible returns whether the panel is visible.
+func (p TracePanel) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the dimensions of the panel.
+func (p *TracePanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetTheme sets the theme.
+func (p *TracePanel) SetTheme(theme theme.Theme) {
+	p.theme = theme
+}
+
+// Update handles key input.
+func (p TracePanel) Update(msg tea.Msg) (TracePanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "W":
+		p.Hide()
+	case "up", "k":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j":
+		if p.selected < len(p.spans)-1 {
+			p.selected++
+		}
+	case "g":
+		p.selected = 0
+	case "G":
+		if len(p.spans) > 0 {
+			p.selected = len(p.spans) - 1
+		}
+	case "enter":
+		if len(p.spans) == 0 {
+			return p, nil
+		}
+		index := p.selected
+		p.Hide()
+		return p, func() tea.Msg {
+			return GoToSpanMsg{Index: index}
+		}
+	}
+	return p, nil
+}
+
+// View renders the panel.
+func (p *TracePanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	colors := p.theme.Colors()
+
+	containerWidth := p.width - 8
+	if containerWidth < 40 {
+		containerWidth = 40
+	}
+	if containerWidth > 90 {
+		containerWidth = 90
+	}
+	contentWidth := containerWidth - 6
+
+	var content strings.Builder
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Bold(true)
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(colors.Info).
+		Faint(true)
+	footerStyle := lipgloss.NewStyle().
+		Foreground(colors.Foreground).
+		Faint(true)
+
+	title := "Call tree"
+	if p.title != "" {
+		title += " — " + p.title
+	}
+	if len(p.spans) > 0 {
+		title += fmt.Sprintf(" (%d spans)", len(p.spans))
+	}
+	content.WriteString(headerStyle.Render(truncateText(title, contentWidth)))
+	content.WriteString("\n")
+	content.WriteString(separatorStyle.Render(strings.Repeat("─", contentWidth)))
+	content.WriteString("\n")
+	content.WriteString(p.renderList(contentWidth))
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("j/k navigate  enter jump to span  esc close"))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colors.Info).
+		Padding(1, 2).
+		Width(containerWidth)
+
+	return lipgloss.Place(
+		p.width,
+		p.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content.String()),
+	)
+}
+
+// renderList renders the visible slice of spans, each indented by its depth
+// with when it started and how long it took on the right.
+func (p *TracePanel) renderList(width int) string {
+	colors := p.theme.Colors()
+
+	if len(p.spans) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(colors.Warn).
+			Italic(true).
+			Padding(1, 0)
+		return emptyStyle.Render("No spans; entries need a span_id field") + "\n"
+	}
+
+	visibleHeight := p.height - 12
+	if visibleHeight < 3 {
+		visibleHeight = 3
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+visibleHeight {
+		p.offset = p.selected - visibleHeight + 1
+	}
+	end := p.offset + visibleHeight
+	if end > len(p.spans) {
+		end = len(p.spans)
+	}
+
+	var b strings.Builder
+	for i := p.offset; i < end; i++ {
+		span := p.spans[i]
+		timing := fmt.Sprintf("%10s %9s %4d", formatOffset(span.Start), formatSpanDuration(span.Duration), span.Entries)
+		nameWidth := width - 2 - len(timing) - 1
+		name := strings.Repeat("  ", span.Depth)
+		if span.Depth > 0 {
+			name += "└ "
+		}
+		name = truncateText(name+span.Name, nameWidth)
+		line := name + strings.Repeat(" ", max(nameWidth-lipgloss.Width(name), 0)+1) + timing
+
+		itemStyle := lipgloss.NewStyle().
+			Foreground(colors.Foreground).
+			Width(width).
+			Padding(0, 1)
+		if i == p.selected {
+			itemStyle = itemStyle.
+				Foreground(colors.Background).
+				Background(colors.Info).
+				Bold(true)
+		}
+		b.WriteString(itemStyle.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatSpanDuration formats how long a span took, to the millisecond from a
+// second up.
+func formatSpanDuration(d time.Duration) string {
+	if d >= time.Second {
+		d = d.Round(time.Millisecond)
+	}
+	return d.String()
+}
@@ -2,8 +2,10 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/ersanisk/sieve/internal/theme"
@@ -18,6 +20,7 @@ type TreeView struct {
 	expanded map[string]bool
 	theme    theme.Theme
 	selected int
+	focused  bool
 }
 
 // TreeNode represents a node in the tree.
@@ -53,10 +56,11 @@ func (m *TreeView) IsVisible() bool {
 	return m.visible
 }
 
-// SetData sets the data to display.
+// SetData sets the data to display and selects the first node.
 func (m *TreeView) SetData(data any) {
 	m.data = data
 	m.expanded = make(map[string]bool)
+	m.selected = 0
 }
 
 // GetData returns the current data.
@@ -97,10 +101,16 @@ func (m *TreeView) IsExpanded(path string) bool {
 // ExpandAll expands all nodes.
 func (m *TreeView) ExpandAll() {
 	m.expanded = make(map[string]bool)
-	nodes := m.buildTree(m.data, "", 0)
-	for _, node := range nodes {
-		if !node.IsLeaf {
-			m.expanded[node.Path] = true
+	for {
+		grew := false
+		for _, node := range m.visibleNodes() {
+			if !node.IsLeaf && !m.expanded[node.Path] {
+				m.expanded[node.Path] = true
+				grew = true
+			}
+		}
+		if !grew {
+			return
 		}
 	}
 }
@@ -110,6 +120,44 @@ func (m *TreeView) CollapseAll() {
 	m.expanded = make(map[string]bool)
 }
 
+// Focus gives the tree keyboard focus; the selected node is highlighted.
+func (m *TreeView) Focus() {
+	m.focused = true
+}
+
+// Blur removes keyboard focus from the tree.
+func (m *TreeView) Blur() {
+	m.focused = false
+}
+
+// IsFocused returns true if the tree has keyboard focus.
+func (m *TreeView) IsFocused() bool {
+	return m.focused
+}
+
+// Update handles key input while the tree is focused: j/k move, g/G jump to
+// the first or last node and Enter expands or collapses the selected one.
+func (m *TreeView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !m.focused {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		m.SetSelected(m.selected - 1)
+	case "down", "j":
+		m.SetSelected(m.selected + 1)
+	case "g", "home":
+		m.SetSelected(0)
+	case "G", "end":
+		m.SetSelected(len(m.visibleNodes()) - 1)
+	case "enter", " ":
+		m.ToggleSelected()
+	}
+	return nil
+}
+
 // View renders the tree view.
 func (m TreeView) View() string {
 	if !m.visible || m.data == nil {
@@ -126,28 +174,70 @@ func (m TreeView) View() string {
 	return containerStyle.Render(content)
 }
 
-// renderContent renders the tree content.
+// renderContent renders the nodes that fit in the tree's height, keeping
+// the selected one in view.
 func (m TreeView) renderContent() string {
-	nodes := m.buildTree(m.data, "", 0)
+	nodes := m.visibleNodes()
 
 	if len(nodes) == 0 {
 		style := lipgloss.NewStyle().
 			Foreground(m.theme.Colors().Foreground).
-			Italic(true).
-			Align(lipgloss.Center).
-			Height(m.height - 2)
-		return style.Render("No data to display")
+			Italic(true)
+		return style.Render("No fields")
 	}
 
+	start, end := m.window(len(nodes))
 	var builder strings.Builder
-	for _, node := range nodes {
-		builder.WriteString(m.renderNode(node))
+	for i := start; i < end; i++ {
+		builder.WriteString(m.renderNode(nodes[i], m.width, m.focused && i == m.selected))
+		if i < end-1 {
+			builder.WriteString("\n")
+		}
 	}
 
 	return builder.String()
 }
 
-// buildTree builds a tree from data.
+// window returns the range of the n visible nodes that fits in the tree's
+// height, keeping the selected one in view.
+func (m TreeView) window(n int) (start, end int) {
+	if m.height > 0 && n > m.height {
+		start = max(0, min(m.selected-m.height/2, n-m.height))
+		return start, start + m.height
+	}
+	return 0, n
+}
+
+// SelectAt selects the node shown on row y of the tree and returns it, or
+// returns nil when the row shows none.
+func (m *TreeView) SelectAt(y int) *TreeNode {
+	nodes := m.visibleNodes()
+	start, end := m.window(len(nodes))
+	if y < 0 || start+y >= end {
+		return nil
+	}
+	m.selected = start + y
+	return &nodes[m.selected]
+}
+
+// visibleNodes returns the nodes shown: the top level, and below every
+// expanded node its children.
+func (m TreeView) visibleNodes() []TreeNode {
+	var nodes []TreeNode
+	var walk func(data any, path string, level int)
+	walk = func(data any, path string, level int) {
+		for _, node := range m.buildTree(data, path, level) {
+			nodes = append(nodes, node)
+			if !node.IsLeaf && m.expanded[node.Path] {
+				walk(node.Value, node.Path, level+1)
+			}
+		}
+	}
+	walk(m.data, "", 0)
+	return nodes
+}
+
+// buildTree builds the nodes one level down from data.
 func (m TreeView) buildTree(data any, path string, level int) []TreeNode {
 	var nodes []TreeNode
 
@@ -182,27 +272,47 @@ func (m TreeView) buildTree(data any, path string, level int) []TreeNode {
 	return nodes
 }
 
-// renderNode renders a single tree node.
-func (m TreeView) renderNode(node TreeNode) string {
-	keyStyle := m.theme.KeyStyle()
-	valueStyle := m.theme.ValueStyle()
+// renderNode renders a single tree node, cut to width when it is set. The
+// selected node of a focused tree is highlighted.
+func (m TreeView) renderNode(node TreeNode, width int, selected bool) string {
+	key, value := m.nodeText(node, width)
+	if selected {
+		return lipgloss.NewStyle().
+			Foreground(m.theme.Colors().Background).
+			Background(m.theme.Colors().Highlight).
+			Render(key + value)
+	}
+	if value == "" {
+		return m.theme.KeyStyle().Render(key)
+	}
+	return m.theme.KeyStyle().Render(key) + m.theme.ValueStyle().Render(value)
+}
 
+// nodeText returns the key and value text of a node, cut to width when it
+// is set.
+func (m TreeView) nodeText(node TreeNode, width int) (string, string) {
 	indent := strings.Repeat("  ", node.Level)
 
+	var key, value string
 	if node.IsLeaf {
-		keyText := keyStyle.Render(fmt.Sprintf("%s├─ %s", indent, node.Key))
-		valueText := valueStyle.Render(fmt.Sprintf(": %v", node.Value))
-		return fmt.Sprintf("%s %s\n", keyText, valueText)
+		key = fmt.Sprintf("%s├─ %s", indent, node.Key)
+		value = fmt.Sprintf(": %v", node.Value)
+	} else {
+		prefix := "+"
+		if m.expanded[node.Path] {
+			prefix = "-"
+		}
+		key = fmt.Sprintf("%s%s %s", indent, prefix, node.Key)
 	}
 
-	isExpanded := m.expanded[node.Path]
-	prefix := "+"
-	if isExpanded {
-		prefix = "-"
+	if width > 0 {
+		keyLen := len([]rune(key))
+		if keyLen >= width {
+			return truncateText(key, width), ""
+		}
+		value = truncateText(value, width-keyLen)
 	}
-
-	keyText := keyStyle.Render(fmt.Sprintf("%s%s %s", indent, prefix, node.Key))
-	return fmt.Sprintf("%s\n", keyText)
+	return key, value
 }
 
 // isLeaf checks if a value is a leaf node.
@@ -220,26 +330,23 @@ func (m TreeView) getSortedKeys(mmap map[string]any) []string {
 	for key := range mmap {
 		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
 	return keys
 }
 
 // GetSelected returns the selected node.
 func (m *TreeView) GetSelected() *TreeNode {
-	nodes := m.buildTree(m.data, "", 0)
+	nodes := m.visibleNodes()
 	if m.selected >= 0 && m.selected < len(nodes) {
 		return &nodes[m.selected]
 	}
 	return nil
 }
 
-// SetSelected sets the selected node.
+// SetSelected sets the selected node, clamped to the visible nodes.
 func (m *TreeView) SetSelected(index int) {
-	if index < 0 {
-		m.selected = 0
-	} else {
-		m.selected = index
-	}
+	m.selected = max(0, min(index, len(m.visibleNodes())-1))
 }
 
 // GetSelectedPath returns the path of the selected node.
@@ -1,11 +1,16 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/ersanisk/sieve/internal/filter"
+	"github.com/ersanisk/sieve/internal/search"
 	"github.com/ersanisk/sieve/internal/theme"
 	"github.com/ersanisk/sieve/pkg/logentry"
 )
@@ -147,6 +152,417 @@ func TestLogView_GetSelected(t *testing.T) {
 	}
 }
 
+func TestLogView_Markers(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(80, 4)
+
+	entries := make([]logentry.Entry, 6)
+	for i := range entries {
+		entries[i] = logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("entry %d", i), Source: "app.log", Offset: int64(i * 10)}
+	}
+	view.SetEntries(entries)
+	view.SetMarkers([]Marker{{ID: logentry.ID{Source: "app.log", Offset: 30}, Text: "file rotated"}})
+
+	if _, ok := view.markerBefore(3); !ok {
+		t.Error("markerBefore(3) = false, want the marker above the first entry at its offset")
+	}
+	for _, i := range []int{0, 1, 2, 4, 5} {
+		if _, ok := view.markerBefore(i); ok {
+			t.Errorf("markerBefore(%d) = true, want false", i)
+		}
+	}
+
+	view.ScrollToBottom()
+	out := view.View()
+	if lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n"); len(lines) != 4 {
+		t.Errorf("View() rendered %d rows, want 4", len(lines))
+	}
+	if !strings.Contains(out, "file rotated") || !strings.Contains(out, "entry 5") {
+		t.Errorf("View() = %q, want the marker and the selected last entry", out)
+	}
+}
+
+func TestLogView_SourceLabels(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(100, 5)
+	view.SetEntries([]logentry.Entry{
+		{Level: logentry.Info, Message: "from api", Source: "/var/log/api.log"},
+		{Level: logentry.Info, Message: "from worker", Source: "/var/log/worker.log"},
+	})
+
+	if out := view.View(); strings.Contains(out, "api.log") {
+		t.Errorf("View() = %q, want no labels for a single file", out)
+	}
+
+	view.SetSourceLabels([]string{"/var/log/api.log", "/var/log/worker.log"})
+	out := view.View()
+	if !strings.Contains(out, "api.log    ") || !strings.Contains(out, "worker.log ") {
+		t.Errorf("View() = %q, want padded source labels", out)
+	}
+}
+
+func TestCutCells(t *testing.T) {
+	tests := []struct {
+		text        string
+		skip, width int
+		want        string
+	}{
+		{"hello world", 0, 20, "hello world"},
+		{"hello world", 0, 8, "hello..."},
+		{"hello world", 6, 20, "world"},
+		{"日本語", 0, 6, "日本語"},
+		{"日本語のログ", 0, 6, "日..."},
+		{"日本語のログ", 0, 7, "日本..."},
+		{"日本語のログです", 2, 7, "本語..."},
+	}
+	for _, tt := range tests {
+		if got := cutCells(tt.text, tt.skip, tt.width); got != tt.want {
+			t.Errorf("cutCells(%q, %d, %d) = %q, want %q", tt.text, tt.skip, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestWrapCells(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"connection refused by upstream", 12, []string{"connection", "refused by", "upstream"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+		{"日本語のログ", 5, []string{"日本", "語の", "ログ"}},
+		{"first\nsecond", 20, []string{"first", "second"}},
+	}
+	for _, tt := range tests {
+		got := wrapCells(tt.text, tt.width)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrapCells(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestLogView_EntryAt(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(40, 6)
+	entries := make([]logentry.Entry, 10)
+	for i := range entries {
+		entries[i] = logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("entry %d %s", i, strings.Repeat("word ", 10))}
+	}
+	view.SetEntries(entries)
+
+	if got := view.EntryAt(2); got != 2 {
+		t.Errorf("EntryAt(2) = %d, want 2", got)
+	}
+	if got := view.EntryAt(6); got != -1 {
+		t.Errorf("EntryAt(6) = %d below the view, want -1", got)
+	}
+
+	view.SetWrap(true)
+	rows := view.entryRows(0)
+	if rows < 2 {
+		t.Fatalf("entryRows(0) = %d, want the entry wrapped", rows)
+	}
+	if got := view.EntryAt(rows - 1); got != 0 {
+		t.Errorf("EntryAt(%d) = %d, want 0 on its last wrapped row", rows-1, got)
+	}
+	if got := view.EntryAt(rows); got != 1 {
+		t.Errorf("EntryAt(%d) = %d, want 1", rows, got)
+	}
+
+	view.SetWrap(false)
+	view.SetColumns([]string{"level", "msg"})
+	if got := view.EntryAt(0); got != -1 {
+		t.Errorf("EntryAt(0) = %d on the header, want -1", got)
+	}
+	if got := view.EntryAt(1); got != 0 {
+		t.Errorf("EntryAt(1) = %d, want 0 below the header", got)
+	}
+}
+
+func TestLogView_Context(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(60, 10)
+	entries := make([]logentry.Entry, 4)
+	for i := range entries {
+		entries[i] = logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("entry %d", i), Offset: int64(i)}
+	}
+	view.SetEntries(entries)
+	view.SetContext(map[logentry.ID]bool{entries[1].ID(): true}, map[logentry.ID]int{entries[2].ID(): 7})
+
+	lines := strings.Split(view.View(), "\n")
+	if !strings.Contains(lines[2], "7 hidden") {
+		t.Errorf("row 2 = %q, want the separator above entry 2", lines[2])
+	}
+	if got := view.EntryAt(2); got != -1 {
+		t.Errorf("EntryAt(2) = %d on the separator, want -1", got)
+	}
+	if got := view.EntryAt(3); got != 2 {
+		t.Errorf("EntryAt(3) = %d, want 2 below the separator", got)
+	}
+
+	view.SetContext(nil, nil)
+	if strings.Contains(view.View(), "hidden") {
+		t.Error("View() should drop the separators with the context view off")
+	}
+}
+
+func TestLogView_Wrap(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(40, 6)
+	long := strings.Repeat("word ", 20)
+	entries := make([]logentry.Entry, 10)
+	for i := range entries {
+		entries[i] = logentry.Entry{Level: logentry.Info, Message: fmt.Sprintf("entry %d %s", i, long)}
+	}
+	view.SetEntries(entries)
+
+	// Cut at the edge and scrolled horizontally.
+	rows := strings.Split(strings.TrimSuffix(view.View(), "\n"), "\n")
+	if len(rows) != 6 || !strings.HasSuffix(rows[0], "...") {
+		t.Fatalf("View() = %q, want 6 rows cut with ...", rows)
+	}
+	view.ScrollRight(10)
+	if got := view.XOffset(); got != 10 {
+		t.Errorf("XOffset() = %d after ScrollRight(10), want 10", got)
+	}
+	if out := view.View(); strings.Contains(out, "entry 0") {
+		t.Errorf("View() = %q, want the start of the messages scrolled out", out)
+	}
+	view.ScrollRight(1000)
+	if got, want := view.XOffset(), view.maxXOffset(); got != want {
+		t.Errorf("XOffset() = %d, want it to stop at %d", got, want)
+	}
+	view.ScrollLeft(1000)
+	if got := view.XOffset(); got != 0 {
+		t.Errorf("XOffset() = %d after scrolling back, want 0", got)
+	}
+
+	// Wrapped, every row fits and entries take several rows.
+	view.SetWrap(true)
+	rows = strings.Split(strings.TrimSuffix(view.View(), "\n"), "\n")
+	if len(rows) != 6 {
+		t.Errorf("View() rendered %d rows, want 6", len(rows))
+	}
+	for _, row := range rows {
+		if w := lipgloss.Width(row); w > 40 {
+			t.Errorf("row %q is %d cells wide, want at most 40", row, w)
+		}
+	}
+	if got := view.entryRows(0); got < 2 {
+		t.Errorf("entryRows(0) = %d, want the message wrapped over several rows", got)
+	}
+
+	// The selected entry stays in view with variable-height rows.
+	view.ScrollToBottom()
+	if out := view.View(); !strings.Contains(out, "entry 9") {
+		t.Errorf("View() = %q, want the selected last entry shown", out)
+	}
+}
+
+func TestLogView_Columns(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(60, 5)
+	view.ToggleLineNumbers()
+	view.SetEntries([]logentry.Entry{
+		{Level: logentry.Info, Message: "ok", Fields: map[string]any{"status": 200.0, "path": "/api/users"}},
+		{Level: logentry.Error, Message: "failed", Fields: map[string]any{"status": 503.0, "path": "/api/orders/" + strings.Repeat("x", 80)}},
+	})
+	view.SetColumns([]string{"level", "status", "path"})
+	view.SetSortColumn("status", true)
+
+	rows := strings.Split(strings.TrimSuffix(view.View(), "\n"), "\n")
+	if len(rows) != 3 {
+		t.Fatalf("View() rendered %d rows, want a header and 2 entries", len(rows))
+	}
+	for i, want := range []string{"level    status ▼  path", "INFO     200       /api/users", "ERROR    503       /api/orders/"} {
+		if !strings.HasPrefix(rows[i], want) {
+			t.Errorf("row %d = %q, want prefix %q", i, rows[i], want)
+		}
+	}
+	for _, row := range rows {
+		if w := lipgloss.Width(row); w > 60 {
+			t.Errorf("row %q is %d cells wide, want at most 60", row, w)
+		}
+	}
+	if !strings.HasSuffix(strings.TrimRight(rows[2], " "), "...") {
+		t.Errorf("row %q, want the long path cut with ...", rows[2])
+	}
+
+	// Scrolled right on a narrow screen, the first columns go out of view.
+	view.SetSize(40, 5)
+	view.ScrollRight(9)
+	if row := strings.Split(view.View(), "\n")[1]; !strings.HasPrefix(row, "200") {
+		t.Errorf("scrolled row = %q, want it to start at the status column", row)
+	}
+
+	view.SetColumns(nil)
+	if out := view.View(); strings.Contains(out, "status") {
+		t.Errorf("View() = %q, want no header without columns", out)
+	}
+}
+
+func TestLogView_Bookmarks(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(80, 5)
+	view.SetEntries([]logentry.Entry{
+		{Level: logentry.Info, Message: "first", Source: "app.log", Offset: 0},
+		{Level: logentry.Info, Message: "second", Source: "app.log", Offset: 10},
+		{Level: logentry.Info, Message: "third", Source: "app.log", Offset: 20},
+	})
+
+	if out := view.View(); strings.Contains(out, "●") {
+		t.Errorf("View() = %q, want no gutter without bookmarks", out)
+	}
+
+	view.SetBookmarks(map[logentry.ID]string{
+		{Source: "app.log", Offset: 0}:  "a",
+		{Source: "app.log", Offset: 20}: "",
+	})
+	lines := strings.Split(strings.TrimSuffix(view.View(), "\n"), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("View() rendered %d rows, want 3", len(lines))
+	}
+	for i, want := range []string{"a ", "  ", "● "} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("row %d = %q, want gutter %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestBookmarksPanel(t *testing.T) {
+	panel := NewBookmarksPanel(&MockTheme{})
+	panel.SetSize(100, 30)
+	panel.SetBookmarks([]BookmarkItem{
+		{Name: "a", Line: 3, Message: "connection refused"},
+		{Source: "api/app.log", Line: 12, Message: "retrying"},
+	})
+	panel.Show()
+
+	view := panel.View()
+	for _, want := range []string{"Bookmarks (1/2)", "connection refused", "app.log", "retrying"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q", want)
+		}
+	}
+
+	panel, _ = panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	_, cmd := panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if msg, ok := cmd().(DeleteBookmarkMsg); !ok || msg.Index != 1 {
+		t.Errorf("d sent %#v, want DeleteBookmarkMsg{1}", msg)
+	}
+
+	panel, cmd = panel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(GoToBookmarkMsg); !ok || msg.Index != 1 {
+		t.Errorf("enter sent %#v, want GoToBookmarkMsg{1}", msg)
+	}
+	if panel.IsVisible() {
+		t.Error("panel still visible after enter")
+	}
+}
+
+func TestTracePanel(t *testing.T) {
+	panel := NewTracePanel(&MockTheme{})
+	panel.SetSize(100, 30)
+	panel.SetSpans("trace_id=abc", []TraceSpanItem{
+		{Name: "GET /checkout", Duration: 120 * time.Millisecond, Entries: 2},
+		{Name: "query orders", Depth: 1, Start: 40 * time.Millisecond, Duration: 50 * time.Millisecond, Entries: 2},
+	})
+	panel.Show()
+
+	view := panel.View()
+	for _, want := range []string{"trace_id=abc (2 spans)", "GET /checkout", "  └ query orders", "+0.040s", "120ms"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q", want)
+		}
+	}
+
+	panel, _ = panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	panel, cmd := panel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(GoToSpanMsg); !ok || msg.Index != 1 {
+		t.Errorf("enter sent %#v, want GoToSpanMsg{1}", msg)
+	}
+	if panel.IsVisible() {
+		t.Error("panel still visible after enter")
+	}
+}
+
+func TestLogView_RelativeTime(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(80, 5)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	view.SetEntries([]logentry.Entry{
+		{Level: logentry.Info, Message: "first", Timestamp: base},
+		{Level: logentry.Info, Message: "second", Timestamp: base.Add(120 * time.Millisecond), Offset: 1},
+		{Level: logentry.Info, Message: "third", Timestamp: base.Add(2*time.Minute + 13*time.Second), Offset: 2},
+	})
+	if strings.Contains(view.View(), "+0.") {
+		t.Error("View() shows offsets before SetRelativeTo")
+	}
+
+	view.SetRelativeTo(base)
+	lines := strings.Split(view.View(), "\n")
+	for i, want := range []string{"+0.000s", "+0.120s", "+2m13s"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("row %d = %q, want offset %s", i, lines[i], want)
+		}
+	}
+
+	view.SetRelativeToPrevious()
+	lines = strings.Split(view.View(), "\n")
+	if strings.Contains(lines[0], "+0.") {
+		t.Errorf("row 0 = %q, want no offset without a previous entry", lines[0])
+	}
+	if !strings.Contains(lines[2], "+2m13s") {
+		t.Errorf("row 2 = %q, want the time since the previous entry", lines[2])
+	}
+}
+
+func TestLogView_TimeMarkers(t *testing.T) {
+	view := NewLogView(&MockTheme{})
+	view.SetSize(80, 20)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var entries []logentry.Entry
+	add := func(at time.Duration, msg string) {
+		entries = append(entries, logentry.Entry{Level: logentry.Info, Message: msg, Timestamp: base.Add(at), Offset: int64(len(entries))})
+	}
+	add(0, "start")
+	add(2*time.Minute+13*time.Second, "after the gap")
+	for i := 0; i < 4; i++ {
+		add(2*time.Minute+20*time.Second+time.Duration(i)*time.Millisecond, fmt.Sprintf("burst %d", i))
+	}
+	add(3*time.Minute, "after the burst")
+	view.SetEntries(entries)
+
+	if strings.Contains(view.View(), "2m13s gap") {
+		t.Error("View() shows markers before SetTimeMarkers")
+	}
+
+	view.SetTimeMarkers(30*time.Second, 4)
+	lines := strings.Split(view.View(), "\n")
+	if !strings.Contains(lines[1], "2m13s gap") {
+		t.Errorf("row 1 = %q, want the gap separator", lines[1])
+	}
+	if !strings.Contains(lines[3], "4 entries in 1s") {
+		t.Errorf("row 3 = %q, want the burst separator", lines[3])
+	}
+	if !strings.Contains(lines[8], "40s gap") || !strings.Contains(lines[9], "after the burst") {
+		t.Errorf("rows 8-9 = %q, want a gap before the last entry", lines[8:10])
+	}
+	if got := view.EntryAt(1); got != -1 {
+		t.Errorf("EntryAt(1) = %d on the separator, want -1", got)
+	}
+	if got := view.EntryAt(4); got != 2 {
+		t.Errorf("EntryAt(4) = %d, want 2 below the burst separator", got)
+	}
+
+	view.SetColumns([]string{"msg"})
+	view.SetSortColumn("msg", false)
+	if strings.Contains(view.View(), "2m13s gap") {
+		t.Error("View() shows time markers while sorted by a column")
+	}
+}
+
 func TestStatusBar_NewStatusBar(t *testing.T) {
 	theme := &MockTheme{}
 	bar := NewStatusBar(theme)
@@ -279,6 +695,116 @@ func TestTreeView_NewTreeView(t *testing.T) {
 	}
 }
 
+func TestTreeView_Navigation(t *testing.T) {
+	tree := NewTreeView(&MockTheme{})
+	tree.SetData(map[string]any{
+		"status": 200.0,
+		"user":   map[string]any{"name": "ada", "roles": []any{"admin"}},
+		"app":    "api",
+	})
+	tree.Focus()
+
+	if got := tree.GetSelectedPath(); got != ".app" {
+		t.Errorf("GetSelectedPath() = %q, want .app first", got)
+	}
+	tree.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if got := tree.GetSelectedPath(); got != ".user" {
+		t.Errorf("after G GetSelectedPath() = %q, want .user", got)
+	}
+	tree.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	tree.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	tree.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if got := tree.GetSelectedPath(); got != ".user.roles" {
+		t.Errorf("GetSelectedPath() = %q, want .user.roles inside the expanded node", got)
+	}
+
+	tree.ExpandAll()
+	if got := len(tree.visibleNodes()); got != 6 {
+		t.Errorf("visible nodes after ExpandAll() = %d, want 6", got)
+	}
+	tree.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	tree.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	tree.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := len(tree.visibleNodes()); got != 3 {
+		t.Errorf("visible nodes after collapsing .user = %d, want 3", got)
+	}
+}
+
+func TestSidebar_SelectAt(t *testing.T) {
+	sidebar := NewSidebar(&MockTheme{})
+	sidebar.SetSize(40, 20)
+	if sidebar.SelectAt(3) != nil {
+		t.Error("SelectAt() returned a field with no entry shown")
+	}
+	sidebar.SetEntry(logentry.Entry{
+		Raw:    `{"app":"api","status":200}`,
+		Fields: map[string]any{"app": "api", "status": 200.0},
+	})
+
+	// Rows 0-2 are the border, the header and the blank line below it.
+	if field := sidebar.SelectAt(2); field != nil {
+		t.Errorf("SelectAt(2) = %q, want nil above the fields", field.Path)
+	}
+	if field := sidebar.SelectAt(4); field == nil || field.Path != ".status" {
+		t.Errorf("SelectAt(4) = %v, want .status", field)
+	}
+	if got := sidebar.SelectedField(); got == nil || got.Path != ".status" {
+		t.Errorf("SelectedField() = %v, want the clicked .status", got)
+	}
+	if field := sidebar.SelectAt(5); field != nil {
+		t.Errorf("SelectAt(5) = %q, want nil below the fields", field.Path)
+	}
+	sidebar.Scroll(-5)
+	if got := sidebar.SelectedField(); got == nil || got.Path != ".app" {
+		t.Errorf("SelectedField() = %v after Scroll(-5), want .app", got)
+	}
+}
+
+func TestLayout_Split(t *testing.T) {
+	layout := NewLayout(0, 0)
+	layout.SetSize(200, 40)
+	if layout.Stacked() {
+		t.Error("Stacked() = true for a wide screen")
+	}
+	mainWidth, mainHeight, sideWidth, sideHeight := layout.Split()
+	if mainWidth+sideWidth != 200 || sideWidth != 70 || mainHeight != 40 || sideHeight != 40 {
+		t.Errorf("Split() = %d, %d, %d, %d; want 130x40 and 70x40", mainWidth, mainHeight, sideWidth, sideHeight)
+	}
+
+	layout.SetSize(80, 40)
+	if !layout.Stacked() {
+		t.Error("Stacked() = false for a narrow screen")
+	}
+	mainWidth, mainHeight, sideWidth, sideHeight = layout.Split()
+	if mainHeight+sideHeight != 40 || sideHeight != 14 || mainWidth != 80 || sideWidth != 80 {
+		t.Errorf("Split() = %d, %d, %d, %d; want 80x26 and 80x14", mainWidth, mainHeight, sideWidth, sideHeight)
+	}
+
+	for i := 0; i < 20; i++ {
+		layout.Grow(1)
+	}
+	if layout.Ratio() != maxPaneRatio {
+		t.Errorf("Ratio() = %v, want it capped at %v", layout.Ratio(), maxPaneRatio)
+	}
+	if layout.Grow(1) {
+		t.Error("Grow() reported a change past the limit")
+	}
+}
+
+func TestLayout_SetRatio(t *testing.T) {
+	layout := NewLayout(0, 0)
+	if !layout.SetRatio(0.504) || layout.Ratio() != 0.5 {
+		t.Errorf("Ratio() = %v after SetRatio(0.504), want 0.5", layout.Ratio())
+	}
+	if layout.SetRatio(0.5) {
+		t.Error("SetRatio() reported a change to the same ratio")
+	}
+	layout.SetRatio(1)
+	if layout.Ratio() != maxPaneRatio {
+		t.Errorf("Ratio() = %v after SetRatio(1), want it capped at %v", layout.Ratio(), maxPaneRatio)
+	}
+}
+
 func TestDashboard_NewDashboard(t *testing.T) {
 	theme := &MockTheme{}
 	dash := NewDashboard(theme)
@@ -425,3 +951,288 @@ func contains(s, substr string) bool {
 func indexOf(s, substr string) int {
 	return strings.Index(s, substr)
 }
+
+func TestSearchBar_HistoryNavigation(t *testing.T) {
+	bar := NewSearchBar(&MockTheme{})
+	bar.SetHistory([]string{"first", "second"})
+	bar.Show()
+	bar.SetValue("draft")
+
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := bar.GetValue(); got != "second" {
+		t.Errorf("after up GetValue() = %q, want %q", got, "second")
+	}
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyUp})
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := bar.GetValue(); got != "first" {
+		t.Errorf("after up x3 GetValue() = %q, want %q", got, "first")
+	}
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyDown})
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if got := bar.GetValue(); got != "draft" {
+		t.Errorf("after returning down GetValue() = %q, want draft restored", got)
+	}
+}
+
+func TestFilterBar_ReverseSearch(t *testing.T) {
+	bar := NewFilterBar(&MockTheme{})
+	bar.SetHistory([]string{`.service == "auth"`, ".level >= 40", `.service == "api"`})
+	bar.Show()
+
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !bar.IsSearchingHistory() {
+		t.Fatal("ctrl+r should start reverse search")
+	}
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("service")})
+	if got := bar.GetValue(); got != `.service == "api"` {
+		t.Errorf("GetValue() = %q, want most recent match", got)
+	}
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := bar.GetValue(); got != `.service == "auth"` {
+		t.Errorf("GetValue() = %q, want older match", got)
+	}
+
+	bar.Hide()
+	if bar.IsSearchingHistory() {
+		t.Error("Hide() should end reverse search")
+	}
+	if bar.textInput.Prompt != "F: " {
+		t.Errorf("Prompt = %q, want it restored", bar.textInput.Prompt)
+	}
+}
+
+func TestFilterBar_Completion(t *testing.T) {
+	bar := NewFilterBar(&MockTheme{})
+	bar.SetFieldStats(filter.NewFieldStats([]logentry.Entry{
+		{Fields: map[string]any{"service": "auth"}},
+		{Fields: map[string]any{"service": "api", "status": float64(200)}},
+	}))
+	bar.Show()
+
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".ser")})
+	if got := bar.Completions(); len(got) != 1 || got[0] != "service" {
+		t.Fatalf("Completions() = %v, want [service]", got)
+	}
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := bar.GetValue(); got != ".service" {
+		t.Errorf("after tab GetValue() = %q, want %q", got, ".service")
+	}
+
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(` == "`)})
+	if got := bar.Completions(); len(got) != 2 {
+		t.Fatalf("Completions() = %v, want both service values", got)
+	}
+	if bar.ParseError() == "" {
+		t.Error("ParseError() is empty for an unterminated string")
+	}
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := bar.GetValue(); got != `.service == "auth"` {
+		t.Errorf("after tab GetValue() = %q, want %q", got, `.service == "auth"`)
+	}
+	if got := bar.ParseError(); got != "" {
+		t.Errorf("ParseError() = %q, want none", got)
+	}
+	if !strings.Contains(bar.View(), `.service == "auth"`) {
+		t.Error("View() does not show the completed expression")
+	}
+}
+
+func TestStatusBar_SetExcludes(t *testing.T) {
+	bar := NewStatusBar(&MockTheme{})
+	bar.SetExcludes([]ExcludeCount{{Rule: "/healthz/", Count: 12}, {Rule: `.message == "cache warmed"`, Count: 3}}, false)
+
+	got := bar.renderFilterPart()
+	if !strings.Contains(got, "🚫 /healthz/:12") {
+		t.Errorf("renderFilterPart() = %q, want per-rule count for /healthz/", got)
+	}
+	if !strings.Contains(got, `.message == ...:3`) {
+		t.Errorf("renderFilterPart() = %q, want truncated rule with its count", got)
+	}
+
+	bar.SetExcludes([]ExcludeCount{{Rule: "/healthz/", Count: 12}}, true)
+	if got := bar.renderFilterPart(); !strings.Contains(got, "👻") {
+		t.Errorf("renderFilterPart() = %q, want revealed marker", got)
+	}
+}
+
+func TestStatusBar_SetProgress(t *testing.T) {
+	bar := NewStatusBar(&MockTheme{})
+	bar.SetProgress("filter", 100, 1000)
+	bar.SetProgress("search", 5, 50)
+	bar.SetProgress("filter", 200, 1000)
+	bar.SetInfo("Searching")
+
+	got := bar.renderContent()
+	for _, want := range []string{"filter: scanned 200 of 1000", "search: scanned 5 of 50", "Searching"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderContent() = %q, want %q", got, want)
+		}
+	}
+	if strings.Contains(got, "scanned 100") {
+		t.Errorf("renderContent() = %q, want earlier progress replaced", got)
+	}
+
+	bar.SetProgress("filter", 0, 0)
+	bar.SetProgress("search", 0, 0)
+	if got := bar.renderProgressPart(); got != "" {
+		t.Errorf("renderProgressPart() = %q, want empty after clearing", got)
+	}
+}
+
+func TestStatusBar_SetNewEntries(t *testing.T) {
+	bar := NewStatusBar(&MockTheme{})
+	bar.SetFollowing(true)
+	bar.SetNewEntries(142)
+	if got := bar.renderContent(); !strings.Contains(got, "▼ 142 new entries") {
+		t.Errorf("renderContent() = %q, want the new entries badge", got)
+	}
+
+	bar.SetNewEntries(0)
+	if got := bar.renderContent(); strings.Contains(got, "new entries") {
+		t.Errorf("renderContent() = %q, want no badge", got)
+	}
+}
+
+func TestStatusBar_SetDropped(t *testing.T) {
+	bar := NewStatusBar(&MockTheme{})
+	bar.SetDropped(12)
+	if got := bar.renderContent(); !strings.Contains(got, "⚠ 12 dropped") {
+		t.Errorf("renderContent() = %q, want the dropped count", got)
+	}
+
+	bar.SetDropped(0)
+	if got := bar.renderContent(); strings.Contains(got, "dropped") {
+		t.Errorf("renderContent() = %q, want no dropped count", got)
+	}
+}
+
+func TestSourcesPanel(t *testing.T) {
+	panel := NewSourcesPanel(&MockTheme{})
+	panel.SetSize(100, 30)
+	panel.SetSources([]SourceItem{
+		{Path: "/var/log/app.log", Status: "running", Lines: 42, Dropped: 3},
+		{Path: "/var/log/gone.log", Status: "error", Error: "no such file"},
+	}, 3)
+	if panel.View() != "" {
+		t.Error("View() of a hidden panel is not empty")
+	}
+
+	panel.Show()
+	view := panel.View()
+	for _, want := range []string{"Sources (2)", "3 lines dropped", "app.log", "42 lines, 3 dropped", "gone.log", "no such file"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q", want)
+		}
+	}
+
+	panel, _ = panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.IsVisible() {
+		t.Error("panel still visible after esc")
+	}
+}
+
+func TestSearchBar_CycleMode(t *testing.T) {
+	bar := NewSearchBar(&MockTheme{})
+	bar.Show()
+	bar.SetValue("timeout")
+
+	for _, want := range []string{"f:timeout", "re:timeout", "timeout"} {
+		bar, _ = bar.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+		if got := bar.GetValue(); got != want {
+			t.Errorf("after ctrl+t GetValue() = %q, want %q", got, want)
+		}
+	}
+
+	bar.SetValue("service:auth")
+	if got := bar.Query().Label(); got != "smart" {
+		t.Errorf("without entries Query().Label() = %q, want %q", got, "smart")
+	}
+
+	bar.SetFieldStats(filter.NewFieldStats([]logentry.Entry{{Fields: map[string]any{"service": "auth"}}}))
+	if got := bar.Query().Label(); got != "field:service" {
+		t.Errorf("Query().Label() = %q, want %q", got, "field:service")
+	}
+	if !strings.Contains(bar.View(), "field:service") {
+		t.Error("View() does not show the search mode")
+	}
+}
+
+func TestHighlightQuery(t *testing.T) {
+	hl := lipgloss.NewStyle().Bold(true)
+	text := "Connection timeout"
+
+	if got := highlightQuery(text, search.ParseQuery("nomatch", nil).Spanner(), hl); got != text {
+		t.Errorf("highlightQuery() = %q, want text unchanged", got)
+	}
+	want := "Connection " + hl.Render("time") + "out"
+	if got := highlightQuery(text, search.ParseQuery("re:t[aeiou]me", nil).Spanner(), hl); got != want {
+		t.Errorf("highlightQuery() = %q, want %q", got, want)
+	}
+}
+
+func TestGotoPrompt(t *testing.T) {
+	p := NewGotoPrompt(&MockTheme{})
+	p.Show()
+	p.SetValue("soon")
+	p.SetPreview(GotoPreview{Row: -1, Err: `invalid time "soon"`})
+
+	var cmd tea.Cmd
+	p, cmd = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !p.IsVisible() {
+		t.Fatal("Enter with an invalid target should keep the prompt open")
+	}
+	if view := p.View(); !strings.Contains(view, "invalid time") {
+		t.Errorf("View() should show the error, got %q", view)
+	}
+
+	p.SetValue("12")
+	p.SetPreview(GotoPreview{Row: 11, Total: 50, Entry: logentry.Entry{Message: "target entry", Line: 12}})
+	if view := p.View(); !strings.Contains(view, "row 12/50") || !strings.Contains(view, "target entry") {
+		t.Errorf("View() should preview the target, got %q", view)
+	}
+
+	p, cmd = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.IsVisible() || cmd == nil {
+		t.Fatal("Enter should close the prompt and submit")
+	}
+	if msg, ok := cmd().(GotoSubmitMsg); !ok || msg.Input != "12" {
+		t.Errorf("submit = %#v, want GotoSubmitMsg{12}", msg)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	p := NewPalette(&MockTheme{})
+	p.Show([]PaletteItem{
+		{ID: "action:toggle-wrap", Title: "Toggle line wrap", Key: "w"},
+		{ID: "action:dashboard", Title: "Toggle dashboard", Key: "d"},
+		{ID: "theme:nord", Title: "Switch theme: nord"},
+	})
+	if got := len(p.Matches()); got != 3 {
+		t.Fatalf("Matches() without a query = %d items, want 3", got)
+	}
+
+	p.SetValue("dash")
+	matches := p.Matches()
+	if len(matches) == 0 || matches[0].ID != "action:dashboard" {
+		t.Fatalf("Matches() for %q = %+v, want the dashboard first", "dash", matches)
+	}
+	if view := p.View(); !strings.Contains(view, "Toggle dashboard") {
+		t.Errorf("View() should list the matches, got %q", view)
+	}
+
+	p.SetValue("zzz")
+	if got := len(p.Matches()); got != 0 {
+		t.Errorf("Matches() for %q = %d items, want none", "zzz", got)
+	}
+
+	p.SetValue("wrap")
+	var cmd tea.Cmd
+	p, cmd = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.IsVisible() || cmd == nil {
+		t.Fatal("Enter should close the palette and run the selection")
+	}
+	if msg, ok := cmd().(PaletteRunMsg); !ok || msg.ID != "action:toggle-wrap" {
+		t.Errorf("run = %#v, want PaletteRunMsg{action:toggle-wrap}", msg)
+	}
+}
@@ -12,6 +12,22 @@ type Entry struct {
 	Raw       string
 	Line      int
 	IsJSON    bool
+	// Source is the file the entry was read from.
+	Source string
+	// Offset is the byte offset of the line in Source.
+	Offset int64
+}
+
+// ID identifies an entry by its source and line offset. Unlike its position
+// in a list, it doesn't change when entries are sorted, filtered or appended.
+type ID struct {
+	Source string
+	Offset int64
+}
+
+// ID returns the entry's identity.
+func (e Entry) ID() ID {
+	return ID{Source: e.Source, Offset: e.Offset}
 }
 
 // GetField returns the value of a field by key and a boolean indicating existence.